@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -9,23 +10,18 @@ import (
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to a YAML or TOML config file, overrides CONFIG_FILE")
+	flag.Parse()
 
-	host := os.Getenv("SERVER_ADDRESS")
-	connStr := os.Getenv("POSTGRES_CONN")
-	isTest := os.Getenv("IS_TEST_ENV")
-
-	isT := func() bool { return isTest == "true" }()
-
-	if isT {
-		fmt.Println("server address: ", host)
-		fmt.Println("postgress conn: ", connStr)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	cfg := config.New(
-		host,
-		connStr,
-		isT,
-	)
+	if cfg.IsTest {
+		fmt.Print(cfg.Dump())
+	}
 
 	core.Run(cfg)
 }
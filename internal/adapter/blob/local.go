@@ -0,0 +1,115 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local implements port.BlobStore on a directory of the local filesystem.
+// Blobs are written to a temporary file and renamed, so a reader never sees
+// a partially written blob.
+type Local struct {
+	root string
+}
+
+func NewLocal(root string) (*Local, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &Local{root: root}, nil
+}
+
+func (r *Local) Put(ctx context.Context, key string, body io.Reader) (err error) {
+	path, err := r.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if _, err := io.Copy(file, contextReader{ctx: ctx, reader: body}); err != nil {
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (r *Local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := r.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+// Delete ignores missing blobs, deleting twice is not an error.
+func (r *Local) Delete(_ context.Context, key string) error {
+	path, err := r.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// path maps key to a file under root and rejects keys escaping it.
+func (r *Local) path(key string) (string, error) {
+	path := filepath.Join(r.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, r.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("blob key %q: outside of the store", key)
+	}
+
+	return path, nil
+}
+
+// contextReader stops a long copy once the request is canceled.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.reader.Read(p)
+}
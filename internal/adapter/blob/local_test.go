@@ -0,0 +1,110 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{name: "nested key", key: "tenders/1/2", want: filepath.Join("tenders", "1", "2")},
+		{name: "cleaned key", key: "tenders/./1//2", want: filepath.Join("tenders", "1", "2")},
+		{name: "leading slash stays inside", key: "/tenders/1", want: filepath.Join("tenders", "1")},
+		{name: "dot dot inside", key: "tenders/1/../2", want: filepath.Join("tenders", "2")},
+		{name: "parent", key: "../outside", wantErr: true},
+		{name: "nested parent", key: "tenders/../../outside", wantErr: true},
+		{name: "sibling with root prefix", key: "../" + filepath.Base(store.root) + "-other/blob", wantErr: true},
+		{name: "root itself", key: "", wantErr: true},
+		{name: "dot", key: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := store.path(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("path(%q) = %q, want an error", tt.key, path)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("path(%q): unexpected error: %v", tt.key, err)
+			}
+
+			if want := filepath.Join(store.root, tt.want); path != want {
+				t.Errorf("path(%q) = %q, want %q", tt.key, path, want)
+			}
+		})
+	}
+}
+
+func TestLocalPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := store.Put(ctx, "tenders/1/2", strings.NewReader("content")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	body, err := store.Get(ctx, "tenders/1/2")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if string(data) != "content" {
+		t.Errorf("got %q, want %q", data, "content")
+	}
+
+	for range 2 {
+		if err := store.Delete(ctx, "tenders/1/2"); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	}
+
+	if _, err := store.Get(ctx, "tenders/1/2"); !os.IsNotExist(err) {
+		t.Errorf("get after delete: got %v, want not exist", err)
+	}
+}
+
+func TestLocalPutEscapingKey(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewLocal(filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := store.Put(context.Background(), "../escaped", strings.NewReader("content")); err == nil {
+		t.Fatal("put of an escaping key succeeded")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "escaped")); !os.IsNotExist(err) {
+		t.Errorf("escaping key was written outside of the store: %v", err)
+	}
+}
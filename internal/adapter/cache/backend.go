@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/logger"
+)
+
+// Backend stores encoded values under string keys. A zero ttl keeps the value
+// until it is evicted. Implementations must be safe for concurrent use, so a
+// shared store such as Redis can replace the in-process LRU.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// store encodes values as JSON, so every hit decodes into a fresh value that
+// callers are free to modify. Backend errors are logged and treated as a miss:
+// the cache must never fail a request the database could serve.
+type store struct {
+	backend Backend
+	logger  *zap.Logger
+}
+
+func (r *store) get(ctx context.Context, key string, dst any) bool {
+	data, ok, err := r.backend.Get(ctx, key)
+	if err != nil {
+		r.log(ctx).Warn("cache get failed", zap.String("key", key), zap.Error(err))
+		return false
+	}
+
+	if !ok {
+		return false
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
+		r.log(ctx).Warn("cache decode failed", zap.String("key", key), zap.Error(err))
+		return false
+	}
+
+	return true
+}
+
+func (r *store) set(ctx context.Context, key string, value any, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		r.log(ctx).Warn("cache encode failed", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	if err := r.backend.Set(ctx, key, data, ttl); err != nil {
+		r.log(ctx).Warn("cache set failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (r *store) delete(ctx context.Context, keys ...string) {
+	if err := r.backend.Delete(ctx, keys...); err != nil {
+		r.log(ctx).Warn("cache delete failed", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+func (r *store) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+// InvitationRepo passes port.InvitationRepo through uncached. Invitations
+// decide which tenders a viewer is listed, so every write starts a new tender
+// list generation.
+type InvitationRepo struct {
+	port.InvitationRepo
+	tenders *TenderRepo
+}
+
+func NewInvitationRepo(next port.InvitationRepo, tenders *TenderRepo) *InvitationRepo {
+	return &InvitationRepo{InvitationRepo: next, tenders: tenders}
+}
+
+func (r *InvitationRepo) Create(ctx context.Context, invitation *entity.Invitation) (bool, error) {
+	defer r.tenders.invalidateLists(ctx)
+
+	return r.InvitationRepo.Create(ctx, invitation)
+}
+
+func (r *InvitationRepo) UpdateStatus(
+	ctx context.Context,
+	invitationID entity.InvitationID,
+	status entity.InvitationStatus,
+	respondedAt time.Time,
+) error {
+	defer r.tenders.invalidateLists(ctx)
+
+	return r.InvitationRepo.UpdateStatus(ctx, invitationID, status, respondedAt)
+}
+
+func (r *InvitationRepo) Delete(ctx context.Context, invitationID entity.InvitationID) error {
+	defer r.tenders.invalidateLists(ctx)
+
+	return r.InvitationRepo.Delete(ctx, invitationID)
+}
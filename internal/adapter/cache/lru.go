@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// LRU is the in-process Backend. It holds at most size entries and evicts
+// the least recently used one when full.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+		now:     time.Now,
+	}
+}
+
+func (r *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && r.now().After(entry.expiresAt) {
+		r.remove(elem)
+		return nil, false, nil
+	}
+
+	r.order.MoveToFront(elem)
+
+	return entry.value, true, nil
+}
+
+func (r *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = r.now().Add(ttl)
+	}
+
+	if elem, ok := r.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		r.order.MoveToFront(elem)
+
+		return nil
+	}
+
+	r.entries[key] = r.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for r.order.Len() > r.size {
+		r.remove(r.order.Back())
+	}
+
+	return nil
+}
+
+func (r *LRU) Delete(_ context.Context, keys ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := r.entries[key]; ok {
+			r.remove(elem)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of entries, expired ones included until they are touched or evicted.
+func (r *LRU) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.order.Len()
+}
+
+func (r *LRU) remove(elem *list.Element) {
+	r.order.Remove(elem)
+	delete(r.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUEviction(t *testing.T) {
+	type op struct {
+		set string
+		get string
+	}
+
+	tests := []struct {
+		name    string
+		size    int
+		ops     []op
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "evicts the oldest when full",
+			size:    2,
+			ops:     []op{{set: "a"}, {set: "b"}, {set: "c"}},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+		},
+		{
+			name:    "a get keeps the entry",
+			size:    2,
+			ops:     []op{{set: "a"}, {set: "b"}, {get: "a"}, {set: "c"}},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name:    "setting a key again keeps the entry",
+			size:    2,
+			ops:     []op{{set: "a"}, {set: "b"}, {set: "a"}, {set: "c"}},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			lru := NewLRU(tt.size)
+
+			for _, op := range tt.ops {
+				if op.set != "" {
+					if err := lru.Set(ctx, op.set, []byte(op.set), 0); err != nil {
+						t.Fatalf("Set(%q) error = %v", op.set, err)
+					}
+				}
+
+				if op.get != "" {
+					_, _, _ = lru.Get(ctx, op.get)
+				}
+			}
+
+			if lru.Len() != tt.size {
+				t.Errorf("Len() = %d, want %d", lru.Len(), tt.size)
+			}
+
+			for _, key := range tt.present {
+				if value, ok, _ := lru.Get(ctx, key); !ok || string(value) != key {
+					t.Errorf("Get(%q) = %q, %v, want %q, true", key, value, ok, key)
+				}
+			}
+
+			for _, key := range tt.absent {
+				if _, ok, _ := lru.Get(ctx, key); ok {
+					t.Errorf("Get(%q) hit, want a miss", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	start := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		elapsed time.Duration
+		wantHit bool
+	}{
+		{name: "before the ttl", ttl: time.Minute, elapsed: 59 * time.Second, wantHit: true},
+		{name: "at the ttl", ttl: time.Minute, elapsed: time.Minute, wantHit: true},
+		{name: "after the ttl", ttl: time.Minute, elapsed: time.Minute + time.Nanosecond, wantHit: false},
+		{name: "zero ttl never expires", ttl: 0, elapsed: 365 * 24 * time.Hour, wantHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := start
+
+			lru := NewLRU(10)
+			lru.now = func() time.Time { return now }
+
+			if err := lru.Set(ctx, "key", []byte("value"), tt.ttl); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			now = now.Add(tt.elapsed)
+
+			if _, ok, _ := lru.Get(ctx, "key"); ok != tt.wantHit {
+				t.Errorf("Get() hit = %v, want %v", ok, tt.wantHit)
+			}
+
+			if !tt.wantHit && lru.Len() != 0 {
+				t.Errorf("Len() = %d after an expired get, want 0", lru.Len())
+			}
+		})
+	}
+}
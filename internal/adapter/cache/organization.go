@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+const (
+	keyOrganizationExists      = "organization:exists:"
+	keyOrganizationsByUser     = "organization:by_user:"
+	keyOrganizationResponsible = "organization:responsible:"
+)
+
+// OrganizationRepo caches port.OrganizationRepo lookups. The service never
+// writes organizations or their responsible users, so entries only expire.
+type OrganizationRepo struct {
+	next  port.OrganizationRepo
+	store *store
+	ttl   time.Duration
+}
+
+func NewOrganizationRepo(next port.OrganizationRepo, backend Backend, ttl time.Duration, logger *zap.Logger) *OrganizationRepo {
+	return &OrganizationRepo{
+		next:  next,
+		store: &store{backend: backend, logger: logger.Named("cache.organization")},
+		ttl:   ttl,
+	}
+}
+
+func (r *OrganizationRepo) Exists(ctx context.Context, orgID entity.OrganizationID) bool {
+	var exists bool
+	if r.store.get(ctx, keyOrganizationExists+string(orgID), &exists) {
+		return exists
+	}
+
+	exists = r.next.Exists(ctx, orgID)
+	if exists {
+		r.store.set(ctx, keyOrganizationExists+string(orgID), exists, r.ttl)
+	}
+
+	return exists
+}
+
+func (r *OrganizationRepo) ReadResponsibleUserOrganization(ctx context.Context, userID entity.UserID) ([]entity.OrganizationID, error) {
+	return r.organizationsByUser(ctx, userID, r.next.ReadResponsibleUserOrganization)
+}
+
+func (r *OrganizationRepo) FindOrganizationsByResponsibleUserID(ctx context.Context, userID entity.UserID) ([]entity.OrganizationID, error) {
+	return r.organizationsByUser(ctx, userID, r.next.FindOrganizationsByResponsibleUserID)
+}
+
+func (r *OrganizationRepo) FindResponsibleUsers(ctx context.Context, organizations []entity.OrganizationID) ([]entity.UserID, error) {
+	sorted := slices.Clone(organizations)
+	slices.Sort(sorted)
+
+	ids := make([]string, 0, len(sorted))
+	for _, id := range sorted {
+		ids = append(ids, string(id))
+	}
+
+	key := keyOrganizationResponsible + strings.Join(ids, ",")
+
+	var users []entity.UserID
+	if r.store.get(ctx, key, &users) {
+		return users, nil
+	}
+
+	users, err := r.next.FindResponsibleUsers(ctx, organizations)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.set(ctx, key, users, r.ttl)
+
+	return users, nil
+}
+
+// organizationsByUser backs both lookups by responsible user, they read the same rows.
+func (r *OrganizationRepo) organizationsByUser(
+	ctx context.Context,
+	userID entity.UserID,
+	read func(context.Context, entity.UserID) ([]entity.OrganizationID, error),
+) ([]entity.OrganizationID, error) {
+	key := keyOrganizationsByUser + string(userID)
+
+	var organizations []entity.OrganizationID
+	if r.store.get(ctx, key, &organizations) {
+		return organizations, nil
+	}
+
+	organizations, err := read(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.set(ctx, key, organizations, r.ttl)
+
+	return organizations, nil
+}
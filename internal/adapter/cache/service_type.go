@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+const keyServiceTypes = "service_type:list"
+
+// ServiceTypeRepo caches the service type catalog, which every tender write
+// and listing consults. Writes through the service drop it once committed,
+// writes on other replicas are picked up once it expires.
+type ServiceTypeRepo struct {
+	next       port.ServiceTypeRepo
+	transactor port.Transactor
+	store      *store
+	ttl        time.Duration
+}
+
+func NewServiceTypeRepo(
+	next port.ServiceTypeRepo,
+	transactor port.Transactor,
+	backend Backend,
+	ttl time.Duration,
+	logger *zap.Logger,
+) *ServiceTypeRepo {
+	return &ServiceTypeRepo{
+		next:       next,
+		transactor: transactor,
+		store:      &store{backend: backend, logger: logger.Named("cache.service_type")},
+		ttl:        ttl,
+	}
+}
+
+func (r *ServiceTypeRepo) Create(ctx context.Context, serviceType *entity.ServiceType) (bool, error) {
+	defer r.invalidate(ctx)
+
+	return r.next.Create(ctx, serviceType)
+}
+
+func (r *ServiceTypeRepo) List(ctx context.Context) ([]*entity.ServiceType, error) {
+	if r.transactor.InTransaction(ctx) {
+		return r.next.List(ctx)
+	}
+
+	var serviceTypes []*entity.ServiceType
+	if r.store.get(ctx, keyServiceTypes, &serviceTypes) {
+		return serviceTypes, nil
+	}
+
+	serviceTypes, err := r.next.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.set(ctx, keyServiceTypes, serviceTypes, r.ttl)
+
+	return serviceTypes, nil
+}
+
+func (r *ServiceTypeRepo) Update(ctx context.Context, serviceType *entity.ServiceType) error {
+	defer r.invalidate(ctx)
+
+	return r.next.Update(ctx, serviceType)
+}
+
+// invalidate drops the cached catalog once the transaction of ctx has committed.
+func (r *ServiceTypeRepo) invalidate(ctx context.Context) {
+	r.transactor.AfterCommit(ctx, func(ctx context.Context) {
+		r.store.delete(ctx, keyServiceTypes)
+	})
+}
@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+const (
+	keyTender               = "tender:"
+	keyTenderListGeneration = "tender:list:generation"
+	keyTenderList           = "tender:list:"
+	keyTenderListMy         = "tender:list_my:"
+)
+
+// TenderRepo caches port.TenderRepo reads. Writes drop the cached tender and
+// start a new list generation once their transaction has committed: list keys
+// embed the generation, so every cached page becomes unreachable at once and
+// simply expires. Reads within a transaction bypass the cache, they must see
+// its own writes and may see rows it later rolls back.
+type TenderRepo struct {
+	next       port.TenderRepo
+	transactor port.Transactor
+	store      *store
+	ttl        time.Duration
+	listTTL    time.Duration
+}
+
+func NewTenderRepo(
+	next port.TenderRepo,
+	transactor port.Transactor,
+	backend Backend,
+	ttl, listTTL time.Duration,
+	logger *zap.Logger,
+) *TenderRepo {
+	return &TenderRepo{
+		next:       next,
+		transactor: transactor,
+		store:      &store{backend: backend, logger: logger.Named("cache.tender")},
+		ttl:        ttl,
+		listTTL:    listTTL,
+	}
+}
+
+func (r *TenderRepo) Create(ctx context.Context, tender *entity.Tender) error {
+	if err := r.next.Create(ctx, tender); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, tender.ID)
+
+	return nil
+}
+
+func (r *TenderRepo) Read(ctx context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
+	if r.transactor.InTransaction(ctx) {
+		return r.next.Read(ctx, tenderID)
+	}
+
+	var tender *entity.Tender
+	if r.store.get(ctx, keyTender+string(tenderID), &tender) {
+		return tender, nil
+	}
+
+	tender, err := r.next.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender != nil {
+		r.store.set(ctx, keyTender+string(tenderID), tender, r.ttl)
+	}
+
+	return tender, nil
+}
+
+func (r *TenderRepo) List(
+	ctx context.Context,
+	tenderTypes []entity.TenderServiceType,
+	viewer *entity.Viewer,
+	options *entity.TenderListOptions,
+	limitOffset *entity.RequestLimitOffset,
+) ([]*entity.Tender, error) {
+	if r.transactor.InTransaction(ctx) {
+		return r.next.List(ctx, tenderTypes, viewer, options, limitOffset)
+	}
+
+	types := make([]string, 0, len(tenderTypes))
+	for _, tenderType := range tenderTypes {
+		types = append(types, string(tenderType))
+	}
+
+	key := keyTenderList + r.generation(ctx) + ":" + strings.Join(types, ",") + ":" + viewerKey(viewer) + ":" + optionsKey(options) + ":" + limitOffsetKey(limitOffset)
+
+	var tenders []*entity.Tender
+	if r.store.get(ctx, key, &tenders) {
+		return tenders, nil
+	}
+
+	tenders, err := r.next.List(ctx, tenderTypes, viewer, options, limitOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.set(ctx, key, tenders, r.listTTL)
+
+	return tenders, nil
+}
+
+func (r *TenderRepo) ListMy(
+	ctx context.Context,
+	organizations []entity.OrganizationID,
+	options *entity.TenderListOptions,
+	limitOffset *entity.RequestLimitOffset,
+) ([]*entity.Tender, error) {
+	if r.transactor.InTransaction(ctx) {
+		return r.next.ListMy(ctx, organizations, options, limitOffset)
+	}
+
+	ids := make([]string, 0, len(organizations))
+	for _, id := range organizations {
+		ids = append(ids, string(id))
+	}
+
+	key := keyTenderListMy + r.generation(ctx) + ":" + strings.Join(ids, ",") + ":" + optionsKey(options) + ":" + limitOffsetKey(limitOffset)
+
+	var tenders []*entity.Tender
+	if r.store.get(ctx, key, &tenders) {
+		return tenders, nil
+	}
+
+	tenders, err := r.next.ListMy(ctx, organizations, options, limitOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store.set(ctx, key, tenders, r.listTTL)
+
+	return tenders, nil
+}
+
+// StreamMy is not cached, an export reads every row once anyway.
+func (r *TenderRepo) StreamMy(ctx context.Context, organizations []entity.OrganizationID, fn func(*entity.Tender) error) error {
+	return r.next.StreamMy(ctx, organizations, fn)
+}
+
+func (r *TenderRepo) UpdateStatus(ctx context.Context, tenderID entity.TenderID, status entity.TenderStatus) error {
+	// Invalidate even on error, outside a transaction the statement may have been applied before it failed.
+	defer r.invalidate(ctx, tenderID)
+
+	return r.next.UpdateStatus(ctx, tenderID, status)
+}
+
+func (r *TenderRepo) Update(ctx context.Context, tenderID entity.TenderID, update *entity.TenderUpdate) error {
+	defer r.invalidate(ctx, tenderID)
+
+	return r.next.Update(ctx, tenderID, update)
+}
+
+func (r *TenderRepo) BumpVersion(ctx context.Context, tenderID entity.TenderID) (entity.TenderVersion, error) {
+	defer r.invalidate(ctx, tenderID)
+
+	return r.next.BumpVersion(ctx, tenderID)
+}
+
+// Lock is not cached, the locked row must be the current one.
+func (r *TenderRepo) Lock(ctx context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
+	return r.next.Lock(ctx, tenderID)
+}
+
+// ReadDeleted is not cached, deleted tenders are only read to restore them.
+func (r *TenderRepo) ReadDeleted(ctx context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
+	return r.next.ReadDeleted(ctx, tenderID)
+}
+
+func (r *TenderRepo) Delete(ctx context.Context, tenderID entity.TenderID, deletedAt time.Time) (bool, error) {
+	defer r.invalidate(ctx, tenderID)
+
+	return r.next.Delete(ctx, tenderID, deletedAt)
+}
+
+func (r *TenderRepo) Restore(ctx context.Context, tenderID entity.TenderID) (bool, error) {
+	defer r.invalidate(ctx, tenderID)
+
+	return r.next.Restore(ctx, tenderID)
+}
+
+// Purge only removes deleted tenders, which are neither read nor listed from the cache.
+func (r *TenderRepo) Purge(ctx context.Context, deletedBefore time.Time) (int64, []*entity.Attachment, error) {
+	return r.next.Purge(ctx, deletedBefore)
+}
+
+// Archive drops the cached archived tenders, which Read no longer returns.
+func (r *TenderRepo) Archive(ctx context.Context, createdBefore time.Time, limit int) ([]entity.TenderID, error) {
+	archived, err := r.next.Archive(ctx, createdBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	r.transactor.AfterCommit(ctx, func(ctx context.Context) {
+		for _, tenderID := range archived {
+			r.store.delete(ctx, keyTender+string(tenderID))
+		}
+
+		r.newGeneration(ctx)
+	})
+
+	return archived, nil
+}
+
+// invalidate drops the cached tender and its lists once the transaction of ctx has committed.
+func (r *TenderRepo) invalidate(ctx context.Context, tenderID entity.TenderID) {
+	r.transactor.AfterCommit(ctx, func(ctx context.Context) {
+		r.store.delete(ctx, keyTender+string(tenderID))
+		r.newGeneration(ctx)
+	})
+}
+
+// invalidateLists drops the cached lists once the transaction of ctx has committed.
+func (r *TenderRepo) invalidateLists(ctx context.Context) {
+	r.transactor.AfterCommit(ctx, func(ctx context.Context) {
+		r.newGeneration(ctx)
+	})
+}
+
+// generation returns the current list generation. A missing generation,
+// for example one evicted from the LRU, is replaced by a new one so lists
+// cached under an older generation are never served again.
+func (r *TenderRepo) generation(ctx context.Context) string {
+	var generation string
+	if r.store.get(ctx, keyTenderListGeneration, &generation) {
+		return generation
+	}
+
+	return r.newGeneration(ctx)
+}
+
+func (r *TenderRepo) newGeneration(ctx context.Context) string {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	r.store.set(ctx, keyTenderListGeneration, generation, 0)
+
+	return generation
+}
+
+// viewerKey tells apart the lists of viewers who may see different invitation-only tenders.
+func viewerKey(viewer *entity.Viewer) string {
+	if viewer == nil {
+		return "-"
+	}
+
+	ids := make([]string, 0, len(viewer.Organizations))
+	for _, id := range viewer.Organizations {
+		ids = append(ids, string(id))
+	}
+
+	return string(viewer.UserID) + "/" + strings.Join(ids, ",")
+}
+
+func optionsKey(options *entity.TenderListOptions) string {
+	if options == nil {
+		return "-"
+	}
+
+	bound := func(amount *int64) string {
+		if amount == nil {
+			return ""
+		}
+
+		return strconv.FormatInt(*amount, 10)
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s/%t", options.Currency, bound(options.BudgetMin), bound(options.BudgetMax), options.Sort, options.IncludeArchived)
+}
+
+func limitOffsetKey(limitOffset *entity.RequestLimitOffset) string {
+	if limitOffset == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d-%d", limitOffset.Limit, limitOffset.Offset)
+}
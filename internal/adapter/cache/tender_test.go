@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+type txKey struct{}
+
+// transactorStub runs hooks after a committed transaction the way the postgres
+// Transactor does, without a database.
+type transactorStub struct{}
+
+type txStub struct {
+	afterCommit []func(context.Context)
+}
+
+func (r transactorStub) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.InTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	tx := &txStub{}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	for _, hook := range tx.afterCommit {
+		hook(ctx)
+	}
+
+	return nil
+}
+
+func (r transactorStub) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if tx, ok := ctx.Value(txKey{}).(*txStub); ok {
+		tx.afterCommit = append(tx.afterCommit, fn)
+		return
+	}
+
+	fn(ctx)
+}
+
+func (r transactorStub) InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txStub)
+	return ok
+}
+
+// tenderRepoStub keeps tenders in a map and counts the reads that reach it.
+type tenderRepoStub struct {
+	port.TenderRepo
+	tenders map[entity.TenderID]entity.Tender
+	reads   int
+	lists   int
+}
+
+func (r *tenderRepoStub) Read(_ context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
+	r.reads++
+
+	tender, ok := r.tenders[tenderID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &tender, nil
+}
+
+func (r *tenderRepoStub) ListMy(
+	_ context.Context,
+	_ []entity.OrganizationID,
+	_ *entity.TenderListOptions,
+	_ *entity.RequestLimitOffset,
+) ([]*entity.Tender, error) {
+	r.lists++
+
+	tenders := make([]*entity.Tender, 0, len(r.tenders))
+	for _, tender := range r.tenders {
+		tenders = append(tenders, &tender)
+	}
+
+	return tenders, nil
+}
+
+func (r *tenderRepoStub) UpdateStatus(_ context.Context, tenderID entity.TenderID, status entity.TenderStatus) error {
+	tender := r.tenders[tenderID]
+	tender.Status = status
+	r.tenders[tenderID] = tender
+
+	return nil
+}
+
+func TestTenderRepoInvalidation(t *testing.T) {
+	const tenderID = entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a")
+
+	tests := []struct {
+		name string
+		// write closes the tender, possibly within a transaction.
+		write func(ctx context.Context, cache *TenderRepo, transactor port.Transactor) error
+	}{
+		{
+			name: "write outside a transaction",
+			write: func(ctx context.Context, cache *TenderRepo, _ port.Transactor) error {
+				return cache.UpdateStatus(ctx, tenderID, entity.TenderStatusClosed)
+			},
+		},
+		{
+			name: "committed transaction",
+			write: func(ctx context.Context, cache *TenderRepo, transactor port.Transactor) error {
+				return transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+					return cache.UpdateStatus(ctx, tenderID, entity.TenderStatusClosed)
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			next := &tenderRepoStub{tenders: map[entity.TenderID]entity.Tender{
+				tenderID: {ID: tenderID, Status: entity.TenderStatusPublished},
+			}}
+			transactor := transactorStub{}
+			cache := NewTenderRepo(next, transactor, NewLRU(100), time.Minute, time.Minute, zap.NewNop())
+
+			if _, err := cache.Read(ctx, tenderID); err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			if _, err := cache.ListMy(ctx, nil, nil, nil); err != nil {
+				t.Fatalf("ListMy() error = %v", err)
+			}
+
+			if err := tt.write(ctx, cache, transactor); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+
+			tender, err := cache.Read(ctx, tenderID)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			if tender.Status != entity.TenderStatusClosed {
+				t.Errorf("Status = %q, want %q", tender.Status, entity.TenderStatusClosed)
+			}
+
+			if next.reads != 2 {
+				t.Errorf("reads reaching the repo = %d, want 2, the read after the write must miss", next.reads)
+			}
+
+			if _, err := cache.ListMy(ctx, nil, nil, nil); err != nil {
+				t.Fatalf("ListMy() error = %v", err)
+			}
+
+			if next.lists != 2 {
+				t.Errorf("lists reaching the repo = %d, want 2, the list after the write must miss", next.lists)
+			}
+		})
+	}
+}
+
+func TestTenderRepoReadsWithinTransaction(t *testing.T) {
+	const tenderID = entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a")
+
+	ctx := context.Background()
+	next := &tenderRepoStub{tenders: map[entity.TenderID]entity.Tender{
+		tenderID: {ID: tenderID, Status: entity.TenderStatusPublished},
+	}}
+	transactor := transactorStub{}
+	backend := NewLRU(100)
+	cache := NewTenderRepo(next, transactor, backend, time.Minute, time.Minute, zap.NewNop())
+
+	if _, err := cache.Read(ctx, tenderID); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	cached := backend.Len()
+
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for range 2 {
+			if _, err := cache.Read(ctx, tenderID); err != nil {
+				return err
+			}
+		}
+
+		_, err := cache.ListMy(ctx, nil, nil, nil)
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithinTransaction() error = %v", err)
+	}
+
+	if next.reads != 3 {
+		t.Errorf("reads reaching the repo = %d, want 3, reads in a transaction must skip the cache", next.reads)
+	}
+
+	if backend.Len() != cached {
+		t.Errorf("cached entries = %d, want %d, reads in a transaction must not fill the cache", backend.Len(), cached)
+	}
+}
+
+func TestTenderRepoRollback(t *testing.T) {
+	const tenderID = entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a")
+
+	ctx := context.Background()
+	next := &tenderRepoStub{tenders: map[entity.TenderID]entity.Tender{
+		tenderID: {ID: tenderID, Status: entity.TenderStatusPublished},
+	}}
+	transactor := transactorStub{}
+	cache := NewTenderRepo(next, transactor, NewLRU(100), time.Minute, time.Minute, zap.NewNop())
+
+	if _, err := cache.Read(ctx, tenderID); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	errRollback := errors.New("rollback")
+
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := cache.UpdateStatus(ctx, tenderID, entity.TenderStatusClosed); err != nil {
+			return err
+		}
+
+		// A concurrent read before the commit must not drop or refill the entry.
+		if _, err := cache.Read(context.Background(), tenderID); err != nil {
+			return err
+		}
+
+		// The stub repo has no transaction, put the row back as a rollback would.
+		next.tenders[tenderID] = entity.Tender{ID: tenderID, Status: entity.TenderStatusPublished}
+
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTransaction() error = %v, want %v", err, errRollback)
+	}
+
+	if next.reads != 1 {
+		t.Errorf("reads reaching the repo = %d, want 1, the entry must stay cached until a commit", next.reads)
+	}
+
+	tender, err := cache.Read(ctx, tenderID)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if tender.Status != entity.TenderStatusPublished {
+		t.Errorf("Status = %q after a rollback, want %q", tender.Status, entity.TenderStatusPublished)
+	}
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+const (
+	keyUserByName = "user:name:"
+	keyUserExists = "user:exists:"
+)
+
+// UserRepo caches port.UserRepo lookups. Users are created outside of the
+// service, so only users that were found are cached and misses always reach
+// the database.
+type UserRepo struct {
+	next  port.UserRepo
+	store *store
+	ttl   time.Duration
+}
+
+func NewUserRepo(next port.UserRepo, backend Backend, ttl time.Duration, logger *zap.Logger) *UserRepo {
+	return &UserRepo{
+		next:  next,
+		store: &store{backend: backend, logger: logger.Named("cache.user")},
+		ttl:   ttl,
+	}
+}
+
+func (r *UserRepo) FindUserId(ctx context.Context, userName string) (entity.UserID, error) {
+	var userID entity.UserID
+	if r.store.get(ctx, keyUserByName+userName, &userID) {
+		return userID, nil
+	}
+
+	userID, err := r.next.FindUserId(ctx, userName)
+	if err != nil {
+		return "", err
+	}
+
+	if userID != "" {
+		r.store.set(ctx, keyUserByName+userName, userID, r.ttl)
+	}
+
+	return userID, nil
+}
+
+func (r *UserRepo) Exists(ctx context.Context, userID entity.UserID) bool {
+	var exists bool
+	if r.store.get(ctx, keyUserExists+string(userID), &exists) {
+		return exists
+	}
+
+	exists = r.next.Exists(ctx, userID)
+	if exists {
+		r.store.set(ctx, keyUserExists+string(userID), exists, r.ttl)
+	}
+
+	return exists
+}
@@ -0,0 +1,67 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/logger"
+)
+
+// Handler reacts to a published event.
+type Handler func(context.Context, *entity.Event) error
+
+// Bus implements port.EventPublisher within the process. Handlers run in the
+// order they subscribed, in the goroutine of the change, so they should hand
+// slow work off. A failing or panicking handler is logged and neither stops
+// the others nor reaches the publisher.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+	logger   *zap.Logger
+}
+
+func NewBus(logger *zap.Logger) *Bus {
+	return &Bus{logger: logger.Named("events")}
+}
+
+func (r *Bus) Subscribe(handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers = append(r.handlers, handler)
+}
+
+func (r *Bus) Publish(ctx context.Context, event *entity.Event) {
+	r.mu.RLock()
+	handlers := r.handlers
+	r.mu.RUnlock()
+
+	r.log(ctx).Debug("event published",
+		zap.String("type", string(event.Type)),
+		zap.String("tenderId", string(event.TenderID)),
+	)
+
+	for _, handler := range handlers {
+		if err := r.handle(ctx, handler, event); err != nil {
+			r.log(ctx).Error("event handler failed", zap.String("type", string(event.Type)), zap.Error(err))
+		}
+	}
+}
+
+func (r *Bus) handle(ctx context.Context, handler Handler, event *entity.Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return handler(ctx, event)
+}
+
+func (r *Bus) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
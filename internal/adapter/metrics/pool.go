@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads pgxpool statistics on every scrape, the pool keeps the
+// counters itself so there is nothing to update in between.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	maxConns         *prometheus.Desc
+	totalConns       *prometheus.Desc
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	canceledAcquire  *prometheus.Desc
+	saturation       *prometheus.Desc
+	lifetimeDestroys *prometheus.Desc
+	idleDestroys     *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool, name string) *poolCollector {
+	labels := prometheus.Labels{"db_name": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", metric), help, nil, labels)
+	}
+
+	return &poolCollector{
+		pool:             pool,
+		maxConns:         desc("max_conns", "Maximum size of the pool."),
+		totalConns:       desc("total_conns", "Connections currently open, including those being established."),
+		acquiredConns:    desc("acquired_conns", "Connections currently in use."),
+		idleConns:        desc("idle_conns", "Connections currently idle."),
+		acquireCount:     desc("acquire_total", "Successful connection acquires."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		emptyAcquire:     desc("empty_acquire_total", "Acquires that had to wait for a connection because the pool was empty."),
+		canceledAcquire:  desc("canceled_acquire_total", "Acquires canceled by their context."),
+		saturation:       desc("saturation_ratio", "Connections in use divided by the maximum size of the pool."),
+		lifetimeDestroys: desc("max_lifetime_destroy_total", "Connections closed for exceeding their maximum lifetime."),
+		idleDestroys:     desc("max_idle_destroy_total", "Connections closed for exceeding their maximum idle time."),
+	}
+}
+
+func (r *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(r, ch)
+}
+
+func (r *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := r.pool.Stat()
+
+	var saturation float64
+	if stat.MaxConns() > 0 {
+		saturation = float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+	}
+
+	ch <- prometheus.MustNewConstMetric(r.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(r.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(r.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(r.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(r.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(r.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(r.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(r.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(r.saturation, prometheus.GaugeValue, saturation)
+	ch <- prometheus.MustNewConstMetric(r.lifetimeDestroys, prometheus.CounterValue, float64(stat.MaxLifetimeDestroyCount()))
+	ch <- prometheus.MustNewConstMetric(r.idleDestroys, prometheus.CounterValue, float64(stat.MaxIdleDestroyCount()))
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	namespace = "avito"
+
+	labelOther = "other"
+)
+
+type Prometheus struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	tendersCreated    prometheus.Counter
+	tenderTransitions *prometheus.CounterVec
+	bidsCreated       prometheus.Counter
+	bidDecisions      *prometheus.CounterVec
+}
+
+func NewPrometheus() *Prometheus {
+	r := &Prometheus{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		tendersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tenders_created_total",
+			Help:      "Tenders created.",
+		}),
+		tenderTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tender_status_transitions_total",
+			Help:      "Tender status changes by target status.",
+		}, []string{"status"}),
+		bidsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bids_created_total",
+			Help:      "Bids created.",
+		}),
+		bidDecisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bid_decisions_total",
+			Help:      "Bid review decisions by outcome.",
+		}, []string{"decision"}),
+	}
+
+	r.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.httpRequests,
+		r.httpDuration,
+		r.tendersCreated,
+		r.tenderTransitions,
+		r.bidsCreated,
+		r.bidDecisions,
+	)
+
+	return r
+}
+
+// RegisterPool exposes the pgx pool statistics of pool, including the share
+// of connections in use, which is the value to alert on before the pool runs out.
+func (r *Prometheus) RegisterPool(pool *pgxpool.Pool, name string) {
+	r.registry.MustRegister(newPoolCollector(pool, name))
+}
+
+func (r *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{Registry: r.registry})
+}
+
+func (r *Prometheus) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+
+	r.httpRequests.WithLabelValues(method, route, code).Inc()
+	r.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (r *Prometheus) TenderCreated() {
+	r.tendersCreated.Inc()
+}
+
+func (r *Prometheus) TenderStatusChanged(status entity.TenderStatus) {
+	// Status comes straight from the request, keep the label set bounded.
+	switch status {
+	case entity.TenderStatusCreated, entity.TenderStatusPublished, entity.TenderStatusClosed:
+		r.tenderTransitions.WithLabelValues(string(status)).Inc()
+	default:
+		r.tenderTransitions.WithLabelValues(labelOther).Inc()
+	}
+}
+
+func (r *Prometheus) BidCreated() {
+	r.bidsCreated.Inc()
+}
+
+func (r *Prometheus) BidDecision(decision entity.BidReviewDecision) {
+	switch decision {
+	case entity.BidReviewApproved, entity.BidReviewRejected:
+		r.bidDecisions.WithLabelValues(string(decision)).Inc()
+	default:
+		r.bidDecisions.WithLabelValues(labelOther).Inc()
+	}
+}
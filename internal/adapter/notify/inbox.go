@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+// Inbox keeps notifications for users to read through the API.
+type Inbox struct {
+	repo port.NotificationRepo
+}
+
+func NewInbox(repo port.NotificationRepo) *Inbox {
+	return &Inbox{repo: repo}
+}
+
+func (r *Inbox) Channel() entity.NotificationChannel {
+	return entity.NotificationChannelInApp
+}
+
+func (r *Inbox) Notify(ctx context.Context, notification *entity.Notification, _ *entity.NotificationPreferences) error {
+	return r.repo.Create(ctx, notification)
+}
@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// SMTPConfig is the mail server notifications are sent through.
+type SMTPConfig struct {
+	// Addr is host:port of the server.
+	Addr     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// SMTP mails notifications to the address in the preferences of the user.
+// The connection is upgraded with STARTTLS when the server offers it, and
+// authenticates only when a username is configured.
+type SMTP struct {
+	cfg  SMTPConfig
+	host string
+}
+
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp addr: %w", err)
+	}
+
+	return &SMTP{cfg: cfg, host: host}, nil
+}
+
+func (r *SMTP) Channel() entity.NotificationChannel {
+	return entity.NotificationChannelEmail
+}
+
+func (r *SMTP) Notify(ctx context.Context, notification *entity.Notification, preferences *entity.NotificationPreferences) error {
+	if preferences == nil || preferences.Email == "" {
+		return nil
+	}
+
+	if r.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", r.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("dial smtp: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, r.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if err := r.send(client, preferences.Email, notification); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (r *SMTP) send(client *smtp.Client, to string, notification *entity.Notification) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: r.host}); err != nil {
+			return err
+		}
+	}
+
+	if r.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", r.cfg.Username, r.cfg.Password, r.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(r.cfg.From); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(message(r.cfg.From, to, notification)); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+func message(from, to string, notification *entity.Notification) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", notification.CreatedAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(crlf(notification.Text))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
+
+// crlf ends every line of text with CRLF, servers may reject the bare LF or CR
+// RFC 5322 forbids.
+func crlf(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// session is what the fake server received in one connection.
+type session struct {
+	auth string
+	from string
+	to   []string
+	data string
+	quit bool
+}
+
+// fakeSMTP accepts a single connection on a local port and records it. It
+// offers AUTH PLAIN but not STARTTLS, so the client stays in plain text.
+func fakeSMTP(t *testing.T) (string, <-chan session) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan session, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		sessions <- serve(conn)
+	}()
+
+	return listener.Addr().String(), sessions
+}
+
+func serve(conn net.Conn) session {
+	var s session
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return s
+		}
+
+		command := strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			s.auth = command
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = command
+			reply("250 2.1.0 OK")
+		case "RCPT":
+			s.to = append(s.to, command)
+			reply("250 2.1.5 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return s
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(line)
+			}
+
+			s.data = data.String()
+			reply("250 2.0.0 queued")
+		case "QUIT":
+			s.quit = true
+			reply("221 2.0.0 Bye")
+
+			return s
+		default:
+			reply("502 5.5.2 command not recognized")
+		}
+	}
+}
+
+func TestSMTPNotify(t *testing.T) {
+	addr, sessions := fakeSMTP(t)
+
+	notifier, err := NewSMTP(SMTPConfig{
+		Addr:     addr,
+		Username: "robot",
+		Password: "secret",
+		From:     "tenders@example.com",
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("new smtp: %v", err)
+	}
+
+	notification := &entity.Notification{
+		Subject:   "Новое предложение",
+		Text:      "First line\nsecond line\r\nthird line\rlast",
+		CreatedAt: time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	err = notifier.Notify(context.Background(), notification, &entity.NotificationPreferences{Email: "owner@example.com"})
+	if err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	s := <-sessions
+
+	if want := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00robot\x00secret")); s.auth != want {
+		t.Errorf("got %q, want %q", s.auth, want)
+	}
+
+	if want := "MAIL FROM:<tenders@example.com>"; !strings.HasPrefix(s.from, want) {
+		t.Errorf("got %q, want %q", s.from, want)
+	}
+
+	if len(s.to) != 1 || s.to[0] != "RCPT TO:<owner@example.com>" {
+		t.Errorf("got recipients %q, want only owner@example.com", s.to)
+	}
+
+	if !s.quit {
+		t.Error("the session was not ended with QUIT")
+	}
+
+	if strings.Contains(strings.ReplaceAll(s.data, "\r\n", ""), "\n") || strings.Contains(strings.ReplaceAll(s.data, "\r\n", ""), "\r") {
+		t.Errorf("message has bare line endings: %q", s.data)
+	}
+
+	header, body, ok := strings.Cut(s.data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("message has no header and body: %q", s.data)
+	}
+
+	for _, want := range []string{
+		"From: tenders@example.com",
+		"To: owner@example.com",
+		"Subject: =?utf-8?q?",
+		"Date: Sun, 01 Sep 2024 12:00:00 +0000",
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header lacks %q:\n%s", want, header)
+		}
+	}
+
+	if want := "First line\r\nsecond line\r\nthird line\r\nlast\r\n"; body != want {
+		t.Errorf("got body %q, want %q", body, want)
+	}
+}
+
+func TestSMTPNotifyWithoutEmail(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	notifier, err := NewSMTP(SMTPConfig{Addr: listener.Addr().String(), From: "tenders@example.com"})
+	if err != nil {
+		t.Fatalf("new smtp: %v", err)
+	}
+
+	for _, preferences := range []*entity.NotificationPreferences{nil, {}} {
+		if err := notifier.Notify(context.Background(), &entity.Notification{}, preferences); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+	}
+
+	listener.(*net.TCPListener).SetDeadline(time.Now().Add(50 * time.Millisecond))
+	if conn, err := listener.Accept(); err == nil {
+		conn.Close()
+		t.Error("a user without an email address was mailed")
+	}
+}
+
+func TestMessageLineEndings(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "one line", want: "one line"},
+		{text: "a\nb", want: "a\r\nb"},
+		{text: "a\r\nb", want: "a\r\nb"},
+		{text: "a\rb", want: "a\r\nb"},
+		{text: "a\n\nb\n", want: "a\r\n\r\nb\r\n"},
+	}
+
+	for _, tt := range tests {
+		if got := crlf(tt.text); got != tt.want {
+			t.Errorf("crlf(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
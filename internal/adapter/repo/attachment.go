@@ -0,0 +1,181 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	// bid_id is set for the attachments of a bid, tender_id is then the tender of the bid.
+	queryInitTenderAttachments = `CREATE TABLE IF NOT EXISTS tender_attachments (
+		id UUID PRIMARY KEY,
+		tender_id UUID NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		content_type VARCHAR(255) NOT NULL,
+		size BIGINT NOT NULL,
+		sha256 CHAR(64) NOT NULL,
+		added_version INTEGER NOT NULL,
+		removed_version INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS tender_attachments_tender_id_idx ON tender_attachments (tender_id);
+	ALTER TABLE tender_attachments ADD COLUMN IF NOT EXISTS bid_id UUID;
+	CREATE INDEX IF NOT EXISTS tender_attachments_bid_id_idx ON tender_attachments (bid_id) WHERE bid_id IS NOT NULL`
+
+	attachmentColumns = `id, tender_id, bid_id, name, content_type, size, sha256, added_version, removed_version, created_at`
+
+	queryCreateAttachment = `INSERT INTO tender_attachments (` + attachmentColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	queryReadAttachment   = `SELECT ` + attachmentColumns + ` FROM tender_attachments WHERE id = $1`
+	// queryListAttachments lists the attachments of the owner present at version $3, a NULL version means now.
+	queryListAttachments = `SELECT ` + attachmentColumns + ` FROM tender_attachments
+		WHERE tender_id = $1 AND bid_id IS NOT DISTINCT FROM $2
+			AND ($3::integer IS NULL OR added_version <= $3)
+			AND (removed_version IS NULL OR ($3::integer IS NOT NULL AND removed_version > $3))
+		ORDER BY created_at, name`
+	queryMarkAttachmentRemoved = `UPDATE tender_attachments SET removed_version = $2 WHERE id = $1 AND removed_version IS NULL`
+)
+
+var attachmentTables = map[string]string{
+	"tender_attachments": queryInitTenderAttachments,
+}
+
+type AttachmentRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanAttachment(row pgx.Row) (*entity.Attachment, error) {
+	var (
+		attachment entity.Attachment
+		bidID      *entity.BidId
+	)
+
+	if err := row.Scan(
+		&attachment.ID,
+		&attachment.TenderID,
+		&bidID,
+		&attachment.Name,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.SHA256,
+		&attachment.AddedInVersion,
+		&attachment.RemovedInVersion,
+		&attachment.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if bidID != nil {
+		attachment.BidID = *bidID
+	}
+
+	return &attachment, nil
+}
+
+func (r *AttachmentRepo) Create(ctx context.Context, attachment *entity.Attachment) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "attachment.create", queryCreateAttachment)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateAttachment,
+		uuidArg(attachment.ID),
+		uuidArg(attachment.TenderID),
+		uuidArg(attachment.BidID),
+		attachment.Name,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.SHA256,
+		attachment.AddedInVersion,
+		attachment.RemovedInVersion,
+		attachment.CreatedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *AttachmentRepo) Read(ctx context.Context, attachmentID entity.AttachmentID) (_ *entity.Attachment, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "attachment.read", queryReadAttachment)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	attachment, err := scanAttachment(conn(ctx, r.db).QueryRow(ctx, queryReadAttachment, uuidArg(attachmentID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	found = 1
+
+	return attachment, nil
+}
+
+func (r *AttachmentRepo) List(ctx context.Context, owner entity.AttachmentOwner, version int32) (_ []*entity.Attachment, err error) {
+	var attachments []*entity.Attachment
+
+	ctx, span := startStatement(ctx, r.timeout, "attachment.list", queryListAttachments)
+	defer func() { span.end(int64(len(attachments)), err) }()
+
+	var versionArg *int32
+	if version > 0 {
+		versionArg = &version
+	}
+
+	// An empty bid ID turns into NULL, which selects the attachments of the tender itself.
+	rows, err := conn(ctx, r.db).Query(ctx, queryListAttachments, uuidArg(owner.TenderID), uuidArg(owner.BidID), versionArg)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Attachment, error) {
+		return scanAttachment(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (r *AttachmentRepo) MarkRemoved(ctx context.Context, attachmentID entity.AttachmentID, version int32) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "attachment.mark_removed", queryMarkAttachmentRemoved)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryMarkAttachmentRemoved, uuidArg(attachmentID), version)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *PostgresRepo) NewAttachmentRepo(ctx context.Context) (*AttachmentRepo, error) {
+	r.requireRelations("tender_attachments")
+
+	ar := &AttachmentRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("attachment"),
+	}
+
+	if err := r.InitTables(ctx, attachmentTables); err != nil {
+		return nil, err
+	}
+
+	return ar, nil
+}
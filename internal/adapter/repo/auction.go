@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	// Both tables are created by one statement so auction_offers never exists without tender_auctions.
+	queryInitAuction = `CREATE TABLE IF NOT EXISTS tender_auctions (
+		tender_id UUID PRIMARY KEY,
+		currency CHAR(3) NOT NULL,
+		start_price BIGINT NOT NULL,
+		min_decrement BIGINT NOT NULL,
+		starts_at TIMESTAMPTZ NOT NULL,
+		ends_at TIMESTAMPTZ NOT NULL,
+		anti_sniping_seconds INTEGER NOT NULL,
+		best_price BIGINT,
+		best_bid_id UUID,
+		offers INTEGER NOT NULL DEFAULT 0,
+		winner_bid_id UUID,
+		closed_at TIMESTAMPTZ
+	);
+	CREATE INDEX IF NOT EXISTS tender_auctions_due_idx ON tender_auctions (ends_at) WHERE closed_at IS NULL;
+	CREATE TABLE IF NOT EXISTS auction_offers (
+		id UUID PRIMARY KEY,
+		tender_id UUID NOT NULL REFERENCES tender_auctions (tender_id),
+		bid_id UUID NOT NULL,
+		amount BIGINT NOT NULL,
+		currency CHAR(3) NOT NULL,
+		placed_at TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS auction_offers_tender_id_idx ON auction_offers (tender_id, bid_id, amount)`
+
+	auctionColumns = `tender_id, currency, start_price, min_decrement, starts_at, ends_at, anti_sniping_seconds,
+		best_price, best_bid_id, offers, winner_bid_id, closed_at`
+	auctionOfferColumns = `id, tender_id, bid_id, amount, currency, placed_at`
+
+	queryCreateAuction = `INSERT INTO tender_auctions (tender_id, currency, start_price, min_decrement, starts_at, ends_at, anti_sniping_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	queryReadAuction   = `SELECT ` + auctionColumns + ` FROM tender_auctions WHERE tender_id = $1`
+	queryLockAuction   = queryReadAuction + ` FOR UPDATE`
+	queryUpdateAuction = `UPDATE tender_auctions
+		SET best_price = $2, best_bid_id = $3, ends_at = $4, offers = $5, winner_bid_id = $6, closed_at = $7
+		WHERE tender_id = $1`
+	queryCreateAuctionOffer  = `INSERT INTO auction_offers (` + auctionOfferColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+	queryLowestAuctionOffers = `SELECT ` + auctionOfferColumns + ` FROM (
+			SELECT DISTINCT ON (bid_id) ` + auctionOfferColumns + ` FROM auction_offers
+			WHERE tender_id = $1
+			ORDER BY bid_id, amount, placed_at
+		) lowest
+		ORDER BY amount, placed_at`
+	queryListDueAuctions = `SELECT tender_id FROM tender_auctions WHERE closed_at IS NULL AND ends_at <= $1 ORDER BY ends_at`
+)
+
+var auctionTables = map[string]string{
+	"tender_auctions": queryInitAuction,
+}
+
+type AuctionRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanAuction(row pgx.Row) (*entity.Auction, error) {
+	var (
+		auction      entity.Auction
+		currency     entity.Currency
+		startPrice   int64
+		minDecrement int64
+		bestPrice    *int64
+		bestBidID    *entity.BidId
+		winnerBidID  *entity.BidId
+	)
+
+	if err := row.Scan(
+		&auction.TenderID,
+		&currency,
+		&startPrice,
+		&minDecrement,
+		&auction.StartsAt,
+		&auction.EndsAt,
+		&auction.AntiSnipingSeconds,
+		&bestPrice,
+		&bestBidID,
+		&auction.Offers,
+		&winnerBidID,
+		&auction.ClosedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	auction.StartPrice = entity.Money{Amount: startPrice, Currency: currency}
+	auction.MinDecrement = entity.Money{Amount: minDecrement, Currency: currency}
+
+	if bestPrice != nil {
+		auction.BestPrice = &entity.Money{Amount: *bestPrice, Currency: currency}
+	}
+
+	if bestBidID != nil {
+		auction.BestBidID = *bestBidID
+	}
+
+	if winnerBidID != nil {
+		auction.WinnerBidID = *winnerBidID
+	}
+
+	return &auction, nil
+}
+
+func (r *AuctionRepo) Create(ctx context.Context, auction *entity.Auction) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "auction.create", queryCreateAuction)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateAuction,
+		uuidArg(auction.TenderID),
+		auction.StartPrice.Currency,
+		auction.StartPrice.Amount,
+		auction.MinDecrement.Amount,
+		auction.StartsAt,
+		auction.EndsAt,
+		auction.AntiSnipingSeconds,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *AuctionRepo) Read(ctx context.Context, tenderID entity.TenderID) (*entity.Auction, error) {
+	return r.read(ctx, "auction.read", queryReadAuction, tenderID)
+}
+
+func (r *AuctionRepo) Lock(ctx context.Context, tenderID entity.TenderID) (*entity.Auction, error) {
+	return r.read(ctx, "auction.lock", queryLockAuction, tenderID)
+}
+
+func (r *AuctionRepo) read(ctx context.Context, name, query string, tenderID entity.TenderID) (_ *entity.Auction, err error) {
+	ctx, span := startStatement(ctx, r.timeout, name, query)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	auction, err := scanAuction(conn(ctx, r.db).QueryRow(ctx, query, uuidArg(tenderID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	found = 1
+
+	return auction, nil
+}
+
+func (r *AuctionRepo) Update(ctx context.Context, auction *entity.Auction) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "auction.update", queryUpdateAuction)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	var bestPrice *int64
+	if auction.BestPrice != nil {
+		bestPrice = &auction.BestPrice.Amount
+	}
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryUpdateAuction,
+		uuidArg(auction.TenderID),
+		bestPrice,
+		uuidArg(auction.BestBidID),
+		auction.EndsAt,
+		auction.Offers,
+		uuidArg(auction.WinnerBidID),
+		auction.ClosedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *AuctionRepo) CreateOffer(ctx context.Context, offer *entity.AuctionOffer) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "auction.create_offer", queryCreateAuctionOffer)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateAuctionOffer,
+		uuidArg(offer.ID),
+		uuidArg(offer.TenderID),
+		uuidArg(offer.BidID),
+		offer.Amount.Amount,
+		offer.Amount.Currency,
+		offer.PlacedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *AuctionRepo) LowestOffers(ctx context.Context, tenderID entity.TenderID) (_ []*entity.AuctionOffer, err error) {
+	var offers []*entity.AuctionOffer
+
+	ctx, span := startStatement(ctx, r.timeout, "auction.lowest_offers", queryLowestAuctionOffers)
+	defer func() { span.end(int64(len(offers)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, queryLowestAuctionOffers, uuidArg(tenderID))
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.AuctionOffer, error) {
+		var offer entity.AuctionOffer
+
+		err := row.Scan(
+			&offer.ID,
+			&offer.TenderID,
+			&offer.BidID,
+			&offer.Amount.Amount,
+			&offer.Amount.Currency,
+			&offer.PlacedAt,
+		)
+
+		return &offer, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+func (r *AuctionRepo) ListDue(ctx context.Context, now time.Time) (_ []entity.TenderID, err error) {
+	var tenderIDs []entity.TenderID
+
+	ctx, span := startStatement(ctx, r.timeout, "auction.list_due", queryListDueAuctions)
+	defer func() { span.end(int64(len(tenderIDs)), err) }()
+
+	tenderIDs, err = collectIDs[entity.TenderID](conn(ctx, r.db).Query(ctx, queryListDueAuctions, now))
+	if err != nil {
+		return nil, err
+	}
+
+	return tenderIDs, nil
+}
+
+func (r *PostgresRepo) NewAuctionRepo(ctx context.Context) (*AuctionRepo, error) {
+	r.requireRelations("tender_auctions", "auction_offers")
+
+	ar := &AuctionRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("auction"),
+	}
+
+	if err := r.InitTables(ctx, auctionTables); err != nil {
+		return nil, err
+	}
+
+	return ar, nil
+}
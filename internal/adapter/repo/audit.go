@@ -0,0 +1,210 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	// The trigger rejects every change to written entries, whoever connects.
+	queryInitAudit = `CREATE TABLE IF NOT EXISTS audit_log (
+		id UUID PRIMARY KEY,
+		entity_type VARCHAR(20) NOT NULL,
+		entity_id UUID NOT NULL,
+		action VARCHAR(20) NOT NULL,
+		actor_id UUID,
+		organization_id UUID,
+		changes JSONB NOT NULL,
+		request_id VARCHAR(128),
+		ip VARCHAR(45),
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS tender_id UUID;
+	CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity_type, entity_id);
+	CREATE INDEX IF NOT EXISTS audit_log_organization_id_created_at_idx ON audit_log (organization_id, created_at DESC);
+	CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at DESC);
+	CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+	END
+	$$ LANGUAGE plpgsql;
+	DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+	CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+		FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+	DROP TRIGGER IF EXISTS audit_log_no_truncate ON audit_log;
+	CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only()`
+
+	auditColumns = `id, entity_type, entity_id, action, actor_id, organization_id, tender_id, changes, request_id, ip, created_at`
+
+	queryAppendAudit = `INSERT INTO audit_log (` + auditColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	// Unset filters are passed as NULL and match everything.
+	queryListAudit = `SELECT ` + auditColumns + ` FROM audit_log
+		WHERE ($1::VARCHAR IS NULL OR entity_type = $1)
+			AND ($2::UUID IS NULL OR entity_id = $2)
+			AND ($3::VARCHAR IS NULL OR action = $3)
+			AND ($4::UUID IS NULL OR actor_id = $4)
+			AND ($5::UUID IS NULL OR organization_id = $5)
+			AND ($6::TIMESTAMP IS NULL OR created_at >= $6)
+			AND ($7::TIMESTAMP IS NULL OR created_at < $7)
+			AND (NOT $8::BOOLEAN OR entity_type <> 'bid' OR tender_id IN (` + queryOpenBidTenders + `))
+		ORDER BY created_at DESC, id `
+	// The tenders whose bids are open at $9, as in entity.Tender.BidsSealed.
+	// Archived tenders are closed, so their bids are open too.
+	queryOpenBidTenders = `SELECT id FROM tenders
+			WHERE NOT sealed OR status = 'Closed' OR bid_deadline <= $9
+		UNION ALL SELECT id FROM tenders_archive`
+)
+
+var auditTables = map[string]string{
+	"audit_log": queryInitAudit,
+}
+
+type AuditRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanAuditEntry(row pgx.Row) (*entity.AuditEntry, error) {
+	var (
+		entry          entity.AuditEntry
+		actorID        *entity.UserID
+		organizationID *entity.OrganizationID
+		tenderID       *entity.TenderID
+		requestID      *string
+		ip             *string
+	)
+
+	if err := row.Scan(
+		&entry.ID,
+		&entry.EntityType,
+		&entry.EntityID,
+		&entry.Action,
+		&actorID,
+		&organizationID,
+		&tenderID,
+		&entry.Changes,
+		&requestID,
+		&ip,
+		&entry.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if actorID != nil {
+		entry.ActorID = *actorID
+	}
+
+	if organizationID != nil {
+		entry.OrganizationID = *organizationID
+	}
+
+	if tenderID != nil {
+		entry.TenderID = *tenderID
+	}
+
+	if requestID != nil {
+		entry.RequestID = *requestID
+	}
+
+	if ip != nil {
+		entry.IP = *ip
+	}
+
+	return &entry, nil
+}
+
+func (r *AuditRepo) Append(ctx context.Context, entry *entity.AuditEntry) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "audit.append", queryAppendAudit)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryAppendAudit,
+		uuidArg(entry.ID),
+		entry.EntityType,
+		uuidArg(entry.EntityID),
+		entry.Action,
+		uuidArg(entry.ActorID),
+		uuidArg(entry.OrganizationID),
+		uuidArg(entry.TenderID),
+		entry.Changes,
+		nullString(entry.RequestID),
+		nullString(entry.IP),
+		entry.CreatedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *AuditRepo) List(
+	ctx context.Context,
+	filter *entity.AuditFilter,
+	limitOffset *entity.RequestLimitOffset,
+) (_ []*entity.AuditEntry, err error) {
+	var entries []*entity.AuditEntry
+
+	query, args := buildLimitOffset(queryListAudit, []any{
+		nullString(string(filter.EntityType)),
+		uuidArg(filter.EntityID),
+		nullString(string(filter.Action)),
+		uuidArg(filter.ActorID),
+		uuidArg(filter.OrganizationID),
+		filter.From,
+		filter.To,
+		filter.HideSealedBids,
+		time.Now(),
+	}, limitOffset)
+
+	ctx, span := startStatement(ctx, r.timeout, "audit.list", query)
+	defer func() { span.end(int64(len(entries)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.AuditEntry, error) {
+		return scanAuditEntry(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// nullString binds an empty string as NULL.
+func nullString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+func (r *PostgresRepo) NewAuditRepo(ctx context.Context) (*AuditRepo, error) {
+	r.requireRelations("audit_log")
+
+	ar := &AuditRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("audit"),
+	}
+
+	if err := r.InitTables(ctx, auditTables); err != nil {
+		return nil, err
+	}
+
+	return ar, nil
+}
@@ -2,17 +2,21 @@ package repo
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"time"
 
-	"avito2024/internal/app/core/entity"
-
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/logger"
 )
 
 type BidRepo struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
 }
 
 const (
@@ -20,161 +24,267 @@ const (
 	id UUID PRIMARY KEY,
 	name VARCHAR(100),
 	description VARCHAR(500),
-	status TEXT,
-	tender_id VARCHAR(100),
+	status TEXT NOT NULL,
+	tender_id UUID NOT NULL,
 	author_type TEXT,
-	author_id VARCHAR(100),
+	author_id UUID NOT NULL,
 	version integer DEFAULT 1,
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-)`
-	queryCreateBid  = `INSERT INTO bid VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	queryReadMyBids = `SELECT * FROM bid WHERE author_id = $1 ORDER BY name`
-
-	queryReadTenderBids = `SELECT * FROM bid WHERE tender_id = $1`
-	queryFindBidAuthor  = `SELECT author_id FROM bid WHERE id = $1`
-	queryReadBidByID    = `SELECT * FROM bid WHERE id =$1`
-
-	queryChangeTenderStatus = `UPDATE status FROM tenders WHERE id = $1 SET status = $2`
+);
+ALTER TABLE bid
+	ADD COLUMN IF NOT EXISTS price_amount BIGINT,
+	ADD COLUMN IF NOT EXISTS price_currency CHAR(3),
+	ADD COLUMN IF NOT EXISTS delivery_days INTEGER,
+	ADD COLUMN IF NOT EXISTS valid_until TIMESTAMP,
+	ADD COLUMN IF NOT EXISTS line_items JSONB,
+	ADD COLUMN IF NOT EXISTS lot_id UUID,
+	ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+-- Like tenders_archive, later columns go to both tables.
+CREATE TABLE IF NOT EXISTS bid_archive (LIKE bid INCLUDING DEFAULTS);
+CREATE UNIQUE INDEX IF NOT EXISTS bid_archive_id_idx ON bid_archive (id);
+CREATE INDEX IF NOT EXISTS bid_archive_author_id_idx ON bid_archive (author_id)`
+	queryCreateBid          = `INSERT INTO bid (` + bidColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+	queryReadMyBids         = `SELECT ` + bidColumns + ` FROM bid WHERE author_id = $1 AND deleted_at IS NULL ORDER BY name`
+	queryReadMyArchivedBids = `SELECT ` + bidColumns + ` FROM bid WHERE author_id = $1 AND deleted_at IS NULL
+		UNION ALL SELECT ` + bidColumns + ` FROM bid_archive WHERE author_id = $1 AND deleted_at IS NULL
+		ORDER BY name`
+
+	queryReadTenderBids = `SELECT ` + bidColumns + ` FROM bid WHERE tender_id = $1 AND deleted_at IS NULL`
+	queryFindBidAuthor  = `SELECT author_id FROM bid WHERE id = ANY($1) AND deleted_at IS NULL`
+	queryReadBidByID    = `SELECT ` + bidColumns + ` FROM bid WHERE id = $1 AND deleted_at IS NULL`
+	queryReadDeletedBid = `SELECT ` + bidColumns + ` FROM bid WHERE id = $1 AND deleted_at IS NOT NULL`
+	queryUpdateBid      = `UPDATE bid SET name = $2, description = $3,
+		price_amount = $4, price_currency = $5, delivery_days = $6, valid_until = $7, line_items = $8,
+		version = COALESCE(version, 0) + 1
+		WHERE id = $1 AND deleted_at IS NULL RETURNING version`
+	queryBumpBidVersion = `UPDATE bid SET version = COALESCE(version, 0) + 1 WHERE id = $1 RETURNING version`
+	queryDeleteBid      = `UPDATE bid SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	queryRestoreBid     = `UPDATE bid SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+	queryPurgeBids      = `WITH purged AS (
+			DELETE FROM bid WHERE deleted_at < $1 RETURNING id
+		), purged_scores AS (
+			DELETE FROM bid_scores WHERE bid_id IN (SELECT id FROM purged)
+		), purged_attachments AS (
+			DELETE FROM tender_attachments WHERE bid_id IN (SELECT id FROM purged)
+			RETURNING id, tender_id, bid_id
+		)
+		SELECT (SELECT count(*) FROM purged), ` + purgedAttachments + ` FROM purged_attachments`
+
+	queryChangeTenderStatus = `UPDATE tenders SET status = $2 WHERE id = $1`
 )
 
 var bidTables map[string]string = map[string]string{
 	"bid": queryInitBid,
 }
 
-func (r *BidRepo) Create(ctx context.Context, bid *entity.Bid) error {
-	_, err := r.db.ExecContext(
-		ctx,
-		queryCreateBid,
-		&bid.ID,
-		&bid.Name,
-		&bid.Description,
-		&bid.Status,
-		&bid.TenderID,
-		&bid.AuthorType,
-		&bid.AuthorID,
-		&bid.Version,
-		&bid.CreatedAt,
-	)
+func (r *BidRepo) Create(ctx context.Context, bid *entity.Bid) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.create", queryCreateBid)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	args := []any{
+		uuidArg(bid.ID),
+		bid.Name,
+		bid.Description,
+		bid.Status,
+		uuidArg(bid.TenderID),
+		bid.AuthorType,
+		uuidArg(bid.AuthorID),
+		bid.Version,
+		bid.CreatedAt,
+	}
 
-	return err
+	args = append(args, offerArgs(bid.Offer)...)
+	args = append(args, uuidArg(bid.LotID))
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryCreateBid, args...)
+	affected = tag.RowsAffected()
+
+	return constraintError(err)
+}
+
+// Update writes the name, description and offer of the bid and returns its new version.
+func (r *BidRepo) Update(ctx context.Context, bid *entity.Bid) (_ entity.BidVersion, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.update", queryUpdateBid)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	args := append([]any{uuidArg(bid.ID), bid.Name, bid.Description}, offerArgs(bid.Offer)...)
+
+	var version entity.BidVersion
+
+	if err := conn(ctx, r.db).QueryRow(ctx, queryUpdateBid, args...).Scan(&version); err != nil {
+		return 0, constraintError(err)
+	}
+
+	affected = 1
+
+	return version, nil
+}
+
+func (r *BidRepo) BumpVersion(ctx context.Context, bidID entity.BidId) (_ entity.BidVersion, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.bump_version", queryBumpBidVersion)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	var version entity.BidVersion
+
+	if err := conn(ctx, r.db).QueryRow(ctx, queryBumpBidVersion, uuidArg(bidID)).Scan(&version); err != nil {
+		return 0, err
+	}
+
+	affected = 1
+
+	return version, nil
 }
 
-func (r *BidRepo) ReadMyBids(ctx context.Context, userID entity.UserID) ([]*entity.Bid, error) {
-	rows, err := r.db.QueryContext(ctx, queryReadMyBids, userID)
+func (r *BidRepo) ReadMyBids(ctx context.Context, userID entity.UserID, includeArchived bool) (_ []*entity.Bid, err error) {
+	var bids []*entity.Bid
+
+	query := queryReadMyBids
+	if includeArchived {
+		query = queryReadMyArchivedBids
+	}
+
+	ctx, span := startStatement(ctx, r.timeout, "bid.read_my", query)
+	defer func() { span.end(int64(len(bids)), err) }()
+
+	bids, err = collectBids(conn(ctx, r.db).Query(ctx, query, uuidArg(userID)))
 	if err != nil {
 		return nil, err
 	}
 
-	var bids []*entity.Bid
+	return bids, nil
+}
 
-	for rows.Next() {
-		bid := new(entity.Bid)
-
-		if err := rows.Scan(
-			&bid.ID,
-			&bid.Name,
-			&bid.Description,
-			&bid.Status,
-			&bid.TenderID,
-			&bid.AuthorType,
-			&bid.AuthorID,
-			&bid.Version,
-			&bid.CreatedAt,
-		); err != nil {
-			return nil, err
+func (r *BidRepo) ReadBidByID(ctx context.Context, bidID entity.BidId) (_ *entity.Bid, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.read_by_id", queryReadBidByID)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	bid, err := scanBid(conn(ctx, r.db).QueryRow(ctx, queryReadBidByID, uuidArg(bidID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
 		}
 
-		bids = append(bids, bid)
+		return nil, err
 	}
-	return bids, nil
 
+	found = 1
+
+	return bid, nil
 }
 
-func (r *BidRepo) ReadBidByID(ctx context.Context, bidID entity.BidId) (*entity.Bid, error) {
-	var bid entity.Bid
-	if err := r.db.QueryRowContext(ctx, queryReadBidByID, bidID).Scan(
-		&bid.ID,
-		&bid.Name,
-		&bid.Description,
-		&bid.Status,
-		&bid.TenderID,
-		&bid.AuthorType,
-		&bid.AuthorID,
-		&bid.Version,
-		&bid.CreatedAt,
-	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+func (r *BidRepo) ReadDeleted(ctx context.Context, bidID entity.BidId) (_ *entity.Bid, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.read_deleted", queryReadDeletedBid)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	bid, err := scanBid(conn(ctx, r.db).QueryRow(ctx, queryReadDeletedBid, uuidArg(bidID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 
 		return nil, err
 	}
 
-	return &bid, nil
+	found = 1
+
+	return bid, nil
 }
 
-func (r *BidRepo) ChangeTenderStatus(ctx context.Context, tenderID entity.TenderID, status entity.TenderStatus) error {
-	_, err := r.db.ExecContext(ctx, queryChangeTenderStatus, tenderID, status)
+func (r *BidRepo) Delete(ctx context.Context, bidID entity.BidId, deletedAt time.Time) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.delete", queryDeleteBid)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryDeleteBid, uuidArg(bidID), deletedAt)
 	if err != nil {
-		return err
+		return false, err
 	}
-	return nil
+
+	affected = tag.RowsAffected()
+
+	return affected > 0, nil
 }
 
-func (r *BidRepo) ReadTenderBids(ctx context.Context, tenderId entity.TenderID) ([]*entity.Bid, error) {
-	rows, err := r.db.QueryContext(ctx, queryReadTenderBids, tenderId)
+func (r *BidRepo) Restore(ctx context.Context, bidID entity.BidId) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.restore", queryRestoreBid)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryRestoreBid, uuidArg(bidID))
 	if err != nil {
-		return nil, err
+		return false, err
+	}
+
+	affected = tag.RowsAffected()
+
+	return affected > 0, nil
+}
+
+func (r *BidRepo) Purge(ctx context.Context, deletedBefore time.Time) (_ int64, _ []*entity.Attachment, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.purge", queryPurgeBids)
+
+	var purged int64
+	defer func() { span.end(purged, err) }()
+
+	var attachments []*entity.Attachment
+	if err := conn(ctx, r.db).QueryRow(ctx, queryPurgeBids, deletedBefore).Scan(&purged, &attachments); err != nil {
+		return 0, nil, err
 	}
 
+	return purged, attachments, nil
+}
+
+func (r *BidRepo) ChangeTenderStatus(ctx context.Context, tenderID entity.TenderID, status entity.TenderStatus) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "bid.change_tender_status", queryChangeTenderStatus)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryChangeTenderStatus, uuidArg(tenderID), status)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *BidRepo) ReadTenderBids(ctx context.Context, tenderId entity.TenderID) (_ []*entity.Bid, err error) {
 	var bids []*entity.Bid
 
-	for rows.Next() {
-		bid := new(entity.Bid)
-
-		if err := rows.Scan(
-			&bid.ID,
-			&bid.Name,
-			&bid.Description,
-			&bid.Status,
-			&bid.TenderID,
-			&bid.AuthorType,
-			&bid.AuthorID,
-			&bid.Version,
-			&bid.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
+	ctx, span := startStatement(ctx, r.timeout, "bid.read_tender_bids", queryReadTenderBids)
+	defer func() { span.end(int64(len(bids)), err) }()
 
-		bids = append(bids, bid)
+	bids, err = collectBids(conn(ctx, r.db).Query(ctx, queryReadTenderBids, uuidArg(tenderId)))
+	if err != nil {
+		return nil, err
 	}
+
 	return bids, nil
 }
 
-func (r *BidRepo) ReadBidResponsibleUsers(ctx context.Context, bidIDs []entity.BidId) ([]entity.UserID, error) {
+func (r *BidRepo) ReadBidResponsibleUsers(ctx context.Context, bidIDs []entity.BidId) (_ []entity.UserID, err error) {
 	var userIDs []entity.UserID
 
-	for _, bidID := range bidIDs {
-		rows, err := r.db.QueryContext(ctx, queryFindBidAuthor, bidID)
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				r.logger.Error("author not found", zap.Any("bidIds", bidIDs))
-				continue
-			}
+	ctx, span := startStatement(ctx, r.timeout, "bid.read_responsible_users", queryFindBidAuthor)
+	defer func() { span.end(int64(len(userIDs)), err) }()
 
-			return nil, err
-		}
-
-		for rows.Next() {
-			var user entity.UserID
-			if err := rows.Scan(&user); err != nil {
-				return nil, err
-			}
-			userIDs = append(userIDs, user)
-		}
+	userIDs, err = collectIDs[entity.UserID](conn(ctx, r.db).Query(ctx, queryFindBidAuthor, uuidArgs(bidIDs)))
+	if err != nil {
+		return nil, err
 	}
 
 	if len(userIDs) == 0 {
+		r.log(ctx).Error("author not found", zap.Any("bidIds", bidIDs))
 		return nil, nil
 	}
 
@@ -182,9 +292,12 @@ func (r *BidRepo) ReadBidResponsibleUsers(ctx context.Context, bidIDs []entity.B
 }
 
 func (r *PostgresRepo) NewBidRepo(ctx context.Context) (*BidRepo, error) {
+	r.requireRelations("bid")
+
 	ur := &BidRepo{
-		db:     r.db,
-		logger: r.logger.Named("bid"),
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("bid"),
 	}
 
 	if err := r.InitTables(ctx, bidTables); err != nil {
@@ -193,3 +306,7 @@ func (r *PostgresRepo) NewBidRepo(ctx context.Context) (*BidRepo, error) {
 
 	return ur, nil
 }
+
+func (r *BidRepo) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
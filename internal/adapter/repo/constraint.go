@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"avito2024/internal/app/core/port"
+)
+
+// SQLSTATE codes of the violations constraintError maps.
+const (
+	codeNotNullViolation    = "23502"
+	codeForeignKeyViolation = "23503"
+	codeCheckViolation      = "23514"
+)
+
+// foreignKeyErrors are what a write referring to a missing row means to the
+// caller, by the name the schema migration gives the foreign key.
+var foreignKeyErrors = map[string]error{
+	"tenders_organization_id_fkey":    fmt.Errorf("%w: organization does not exist", port.ErrWrongInputFormat),
+	"tenders_service_type_fkey":       port.ErrServiceTypeNotFound,
+	"tender_lots_service_type_fkey":   port.ErrServiceTypeNotFound,
+	"bid_tender_id_fkey":              port.ErrTenderNotFound,
+	"bid_lot_id_fkey":                 port.ErrLotNotFound,
+	"bid_author_user_id_fkey":         port.ErrUserNotExists,
+	"bid_author_organization_id_fkey": port.ErrUserNotExists,
+}
+
+// constraintError turns a constraint violation of a write into the domain
+// error it stands for. Other errors, and violations the caller cannot cause,
+// are returned as they are.
+func constraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case codeForeignKeyViolation:
+		if domainErr, ok := foreignKeyErrors[pgErr.ConstraintName]; ok {
+			return domainErr
+		}
+	case codeCheckViolation:
+		return fmt.Errorf("%w: violates %s", port.ErrWrongInputFormat, pgErr.ConstraintName)
+	case codeNotNullViolation:
+		// Malformed ids are bound as NULL, see uuidArg.
+		return fmt.Errorf("%w: %s is missing or malformed", port.ErrWrongInputFormat, pgErr.ColumnName)
+	}
+
+	return err
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"avito2024/internal/app/core/port"
+)
+
+func TestConstraintError(t *testing.T) {
+	errOther := errors.New("connection reset")
+	uniqueViolation := &pgconn.PgError{Code: "23505", ConstraintName: "tenders_pkey"}
+	archiveViolation := &pgconn.PgError{Code: codeForeignKeyViolation, ConstraintName: "bid_archive_tender_id_fkey"}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "missing tender",
+			err:  fmt.Errorf("create bid: %w", &pgconn.PgError{Code: codeForeignKeyViolation, ConstraintName: "bid_tender_id_fkey"}),
+			want: port.ErrTenderNotFound,
+		},
+		{
+			name: "missing author",
+			err:  &pgconn.PgError{Code: codeForeignKeyViolation, ConstraintName: "bid_author_organization_id_fkey"},
+			want: port.ErrUserNotExists,
+		},
+		{
+			name: "missing organization",
+			err:  &pgconn.PgError{Code: codeForeignKeyViolation, ConstraintName: "tenders_organization_id_fkey"},
+			want: port.ErrWrongInputFormat,
+		},
+		{
+			name: "check violation",
+			err:  &pgconn.PgError{Code: codeCheckViolation, ConstraintName: "bid_status_check"},
+			want: port.ErrWrongInputFormat,
+		},
+		{
+			name: "malformed id",
+			err:  &pgconn.PgError{Code: codeNotNullViolation, ColumnName: "tender_id"},
+			want: port.ErrWrongInputFormat,
+		},
+		{name: "foreign key the caller cannot violate", err: archiveViolation, want: archiveViolation},
+		{name: "other violation", err: uniqueViolation, want: uniqueViolation},
+		{name: "not a postgres error", err: errOther, want: errOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := constraintError(tt.err); !errors.Is(got, tt.want) {
+				t.Errorf("constraintError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
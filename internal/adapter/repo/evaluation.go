@@ -0,0 +1,181 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	// Both tables are created by one statement, bid_scores references tender_criteria.
+	queryInitEvaluation = `CREATE TABLE IF NOT EXISTS tender_criteria (
+		id UUID PRIMARY KEY,
+		tender_id UUID NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		weight INTEGER NOT NULL,
+		position INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS tender_criteria_tender_id_idx ON tender_criteria (tender_id);
+	CREATE TABLE IF NOT EXISTS bid_scores (
+		bid_id UUID NOT NULL,
+		criterion_id UUID NOT NULL REFERENCES tender_criteria (id) ON DELETE CASCADE,
+		reviewer_id VARCHAR(100) NOT NULL,
+		score INTEGER NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (bid_id, criterion_id, reviewer_id)
+	);
+	CREATE INDEX IF NOT EXISTS bid_scores_criterion_id_idx ON bid_scores (criterion_id)`
+
+	criterionColumns = `id, tender_id, name, weight`
+	scoreColumns     = `bid_id, criterion_id, reviewer_id, score, updated_at`
+
+	queryDeleteCriteria  = `DELETE FROM tender_criteria WHERE tender_id = $1`
+	queryCreateCriterion = `INSERT INTO tender_criteria (` + criterionColumns + `, position) VALUES ($1, $2, $3, $4, $5)`
+	queryListCriteria    = `SELECT ` + criterionColumns + ` FROM tender_criteria WHERE tender_id = $1 ORDER BY position`
+	querySaveScore       = `INSERT INTO bid_scores (` + scoreColumns + `) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (bid_id, criterion_id, reviewer_id) DO UPDATE SET score = EXCLUDED.score, updated_at = EXCLUDED.updated_at`
+	queryListScores = `SELECT s.bid_id, s.criterion_id, s.reviewer_id, s.score, s.updated_at FROM bid_scores s
+		JOIN tender_criteria c ON c.id = s.criterion_id
+		WHERE c.tender_id = $1`
+)
+
+var evaluationTables = map[string]string{
+	"tender_criteria": queryInitEvaluation,
+}
+
+type EvaluationRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// ReplaceCriteria runs several statements, callers wrap it in a transaction.
+func (r *EvaluationRepo) ReplaceCriteria(ctx context.Context, tenderID entity.TenderID, criteria []*entity.EvaluationCriterion) (err error) {
+	deleteCtx, span := startStatement(ctx, r.timeout, "evaluation.delete_criteria", queryDeleteCriteria)
+
+	tag, err := conn(deleteCtx, r.db).Exec(deleteCtx, queryDeleteCriteria, uuidArg(tenderID))
+	span.end(tag.RowsAffected(), err)
+
+	if err != nil {
+		return err
+	}
+
+	for i, criterion := range criteria {
+		if err := r.createCriterion(ctx, criterion, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *EvaluationRepo) createCriterion(ctx context.Context, criterion *entity.EvaluationCriterion, position int) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "evaluation.create_criterion", queryCreateCriterion)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateCriterion,
+		uuidArg(criterion.ID),
+		uuidArg(criterion.TenderID),
+		criterion.Name,
+		criterion.Weight,
+		position,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *EvaluationRepo) ListCriteria(ctx context.Context, tenderID entity.TenderID) (_ []*entity.EvaluationCriterion, err error) {
+	var criteria []*entity.EvaluationCriterion
+
+	ctx, span := startStatement(ctx, r.timeout, "evaluation.list_criteria", queryListCriteria)
+	defer func() { span.end(int64(len(criteria)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, queryListCriteria, uuidArg(tenderID))
+	if err != nil {
+		return nil, err
+	}
+
+	criteria, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.EvaluationCriterion, error) {
+		var criterion entity.EvaluationCriterion
+
+		err := row.Scan(&criterion.ID, &criterion.TenderID, &criterion.Name, &criterion.Weight)
+
+		return &criterion, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return criteria, nil
+}
+
+func (r *EvaluationRepo) SaveScore(ctx context.Context, score *entity.BidScore) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "evaluation.save_score", querySaveScore)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		querySaveScore,
+		uuidArg(score.BidID),
+		uuidArg(score.CriterionID),
+		score.ReviewerID,
+		score.Score,
+		score.UpdatedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *EvaluationRepo) ListScores(ctx context.Context, tenderID entity.TenderID) (_ []*entity.BidScore, err error) {
+	var scores []*entity.BidScore
+
+	ctx, span := startStatement(ctx, r.timeout, "evaluation.list_scores", queryListScores)
+	defer func() { span.end(int64(len(scores)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, queryListScores, uuidArg(tenderID))
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.BidScore, error) {
+		var score entity.BidScore
+
+		err := row.Scan(&score.BidID, &score.CriterionID, &score.ReviewerID, &score.Score, &score.UpdatedAt)
+
+		return &score, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+func (r *PostgresRepo) NewEvaluationRepo(ctx context.Context) (*EvaluationRepo, error) {
+	r.requireRelations("tender_criteria", "bid_scores")
+
+	er := &EvaluationRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("evaluation"),
+	}
+
+	if err := r.InitTables(ctx, evaluationTables); err != nil {
+		return nil, err
+	}
+
+	return er, nil
+}
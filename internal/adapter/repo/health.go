@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"avito2024/internal/app/core/port"
+)
+
+const (
+	defaultCheckTimeout = 5 * time.Second
+
+	queryRelationExists = `SELECT to_regclass($1) IS NOT NULL`
+)
+
+type pingCheck struct {
+	db *pgxpool.Pool
+}
+
+func (r *pingCheck) Name() string {
+	return "postgres"
+}
+
+func (r *pingCheck) Check(ctx context.Context) error {
+	return r.db.Ping(ctx)
+}
+
+type migrationCheck struct {
+	db        *pgxpool.Pool
+	relations []string
+}
+
+func (r *migrationCheck) Name() string {
+	return "migrations"
+}
+
+func (r *migrationCheck) Check(ctx context.Context) error {
+	var missing []string
+
+	for _, relation := range r.relations {
+		var exists bool
+		if err := r.db.QueryRow(ctx, queryRelationExists, relation).Scan(&exists); err != nil {
+			return err
+		}
+
+		if !exists {
+			missing = append(missing, relation)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// HealthCheckers returns the readiness checks of the database. Call it after
+// every repo has been created so the migration check knows all of their tables.
+func (r *PostgresRepo) HealthCheckers() []port.HealthChecker {
+	return []port.HealthChecker{
+		&pingCheck{db: r.db},
+		&migrationCheck{db: r.db, relations: r.relations},
+	}
+}
@@ -0,0 +1,205 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	queryInitIdempotencyKeys = `CREATE TABLE IF NOT EXISTS idempotency_keys (
+		scope VARCHAR(100) NOT NULL,
+		key VARCHAR(255) NOT NULL,
+		request_hash CHAR(64) NOT NULL,
+		status_code INTEGER,
+		content_type TEXT,
+		body BYTEA,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		expires_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (scope, key)
+	);
+	CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at)`
+
+	// queryAcquireIdempotencyKey takes over an expired record, including a pending
+	// one whose request died before completing, and returns no row otherwise.
+	queryAcquireIdempotencyKey = `INSERT INTO idempotency_keys (scope, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, now() + $4::interval)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = NULL,
+			body = NULL,
+			created_at = now(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < now()
+		RETURNING key`
+	queryReadIdempotencyKey = `SELECT request_hash, status_code, content_type, body, expires_at
+		FROM idempotency_keys WHERE scope = $1 AND key = $2`
+	queryCompleteIdempotencyKey = `UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, body = $5, expires_at = now() + $6::interval
+		WHERE scope = $1 AND key = $2`
+	queryDeleteIdempotencyKey        = `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`
+	queryDeleteExpiredIdempotencyKey = `DELETE FROM idempotency_keys WHERE expires_at < now()`
+)
+
+var idempotencyTables = map[string]string{
+	"idempotency_keys": queryInitIdempotencyKeys,
+}
+
+type IdempotencyRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func (r *IdempotencyRepo) Acquire(
+	ctx context.Context,
+	record *entity.IdempotencyRecord,
+	lockTimeout time.Duration,
+) (*entity.IdempotencyRecord, error) {
+	// The existing record may expire and be deleted between the statements, retry once then.
+	for range 2 {
+		acquired, err := r.acquire(ctx, record, lockTimeout)
+		if err != nil || acquired {
+			return nil, err
+		}
+
+		existing, err := r.read(ctx, record.Scope, record.Key)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return existing, err
+		}
+	}
+
+	return nil, fmt.Errorf("idempotency key %s: %w", record.Key, pgx.ErrNoRows)
+}
+
+func (r *IdempotencyRepo) acquire(ctx context.Context, record *entity.IdempotencyRecord, lockTimeout time.Duration) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "idempotency.acquire", queryAcquireIdempotencyKey)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	var key string
+
+	err = conn(ctx, r.db).QueryRow(ctx, queryAcquireIdempotencyKey, record.Scope, record.Key, record.RequestHash, lockTimeout).Scan(&key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	affected = 1
+
+	return true, nil
+}
+
+func (r *IdempotencyRepo) read(ctx context.Context, scope, key string) (_ *entity.IdempotencyRecord, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "idempotency.read", queryReadIdempotencyKey)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	var (
+		record      = &entity.IdempotencyRecord{Scope: scope, Key: key}
+		statusCode  *int
+		contentType *string
+		body        []byte
+	)
+
+	err = conn(ctx, r.db).QueryRow(ctx, queryReadIdempotencyKey, scope, key).Scan(
+		&record.RequestHash,
+		&statusCode,
+		&contentType,
+		&body,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	found = 1
+
+	if statusCode != nil {
+		record.Response = &entity.IdempotentResponse{StatusCode: *statusCode, Body: body}
+		if contentType != nil {
+			record.Response.ContentType = *contentType
+		}
+	}
+
+	return record, nil
+}
+
+func (r *IdempotencyRepo) Complete(
+	ctx context.Context,
+	scope, key string,
+	response *entity.IdempotentResponse,
+	window time.Duration,
+) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "idempotency.complete", queryCompleteIdempotencyKey)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCompleteIdempotencyKey,
+		scope,
+		key,
+		response.StatusCode,
+		response.ContentType,
+		response.Body,
+		window,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *IdempotencyRepo) Delete(ctx context.Context, scope, key string) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "idempotency.delete", queryDeleteIdempotencyKey)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryDeleteIdempotencyKey, scope, key)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context) (_ int64, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "idempotency.delete_expired", queryDeleteExpiredIdempotencyKey)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryDeleteExpiredIdempotencyKey)
+	affected = tag.RowsAffected()
+
+	return affected, err
+}
+
+func (r *PostgresRepo) NewIdempotencyRepo(ctx context.Context) (*IdempotencyRepo, error) {
+	r.requireRelations("idempotency_keys")
+
+	ir := &IdempotencyRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("idempotency"),
+	}
+
+	if err := r.InitTables(ctx, idempotencyTables); err != nil {
+		return nil, err
+	}
+
+	return ir, nil
+}
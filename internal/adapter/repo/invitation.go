@@ -0,0 +1,225 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	queryInitInvitations = `CREATE TABLE IF NOT EXISTS tender_invitations (
+		id UUID PRIMARY KEY,
+		tender_id UUID NOT NULL,
+		invitee_type TEXT NOT NULL,
+		invitee_id UUID NOT NULL,
+		status TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		responded_at TIMESTAMP,
+		UNIQUE (tender_id, invitee_type, invitee_id)
+	);
+	CREATE INDEX IF NOT EXISTS tender_invitations_invitee_id_idx ON tender_invitations (invitee_id)`
+
+	invitationColumns = `id, tender_id, invitee_type, invitee_id, status, created_at, responded_at`
+
+	// queryInvitedTenders selects the tenders the user $2 or one of the organizations $3 is invited to.
+	queryInvitedTenders = `SELECT tender_id FROM tender_invitations
+		WHERE status <> 'Declined'
+			AND ((invitee_type = 'User' AND invitee_id = $2) OR (invitee_type = 'Organization' AND invitee_id = ANY($3)))`
+
+	queryCreateInvitation = `INSERT INTO tender_invitations (` + invitationColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tender_id, invitee_type, invitee_id) DO NOTHING`
+	queryReadInvitation        = `SELECT ` + invitationColumns + ` FROM tender_invitations WHERE id = $1`
+	queryListTenderInvitations = `SELECT ` + invitationColumns + ` FROM tender_invitations WHERE tender_id = $1 ORDER BY created_at`
+	queryListInvitationsFor    = `SELECT ` + invitationColumns + ` FROM tender_invitations
+		WHERE (invitee_type = 'User' AND invitee_id = $1) OR (invitee_type = 'Organization' AND invitee_id = ANY($2))
+		ORDER BY created_at DESC`
+	queryInvited                = `SELECT $1 IN (` + queryInvitedTenders + `)`
+	queryUpdateInvitationStatus = `UPDATE tender_invitations SET status = $2, responded_at = $3 WHERE id = $1`
+	queryDeleteInvitation       = `DELETE FROM tender_invitations WHERE id = $1`
+)
+
+var invitationTables = map[string]string{
+	"tender_invitations": queryInitInvitations,
+}
+
+type InvitationRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanInvitation(row pgx.Row) (*entity.Invitation, error) {
+	var invitation entity.Invitation
+
+	if err := row.Scan(
+		&invitation.ID,
+		&invitation.TenderID,
+		&invitation.InviteeType,
+		&invitation.InviteeID,
+		&invitation.Status,
+		&invitation.CreatedAt,
+		&invitation.RespondedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *InvitationRepo) Create(ctx context.Context, invitation *entity.Invitation) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "invitation.create", queryCreateInvitation)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateInvitation,
+		uuidArg(invitation.ID),
+		uuidArg(invitation.TenderID),
+		invitation.InviteeType,
+		uuidArg(invitation.InviteeID),
+		invitation.Status,
+		invitation.CreatedAt,
+		invitation.RespondedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return affected == 1, constraintError(err)
+}
+
+func (r *InvitationRepo) Read(ctx context.Context, invitationID entity.InvitationID) (_ *entity.Invitation, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "invitation.read", queryReadInvitation)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	invitation, err := scanInvitation(conn(ctx, r.db).QueryRow(ctx, queryReadInvitation, uuidArg(invitationID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	found = 1
+
+	return invitation, nil
+}
+
+func (r *InvitationRepo) ListByTender(ctx context.Context, tenderID entity.TenderID) ([]*entity.Invitation, error) {
+	return r.list(ctx, "invitation.list_by_tender", queryListTenderInvitations, uuidArg(tenderID))
+}
+
+func (r *InvitationRepo) ListFor(ctx context.Context, viewer *entity.Viewer) ([]*entity.Invitation, error) {
+	userID, organizations := viewerArgs(viewer)
+
+	return r.list(ctx, "invitation.list_for", queryListInvitationsFor, userID, organizations)
+}
+
+func (r *InvitationRepo) list(ctx context.Context, name, query string, args ...any) (_ []*entity.Invitation, err error) {
+	var invitations []*entity.Invitation
+
+	ctx, span := startStatement(ctx, r.timeout, name, query)
+	defer func() { span.end(int64(len(invitations)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Invitation, error) {
+		return scanInvitation(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (r *InvitationRepo) Invited(ctx context.Context, tenderID entity.TenderID, viewer *entity.Viewer) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "invitation.invited", queryInvited)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	userID, organizations := viewerArgs(viewer)
+
+	var invited *bool
+	if err := conn(ctx, r.db).QueryRow(ctx, queryInvited, uuidArg(tenderID), userID, organizations).Scan(&invited); err != nil {
+		return false, err
+	}
+
+	// IN yields NULL rather than false for a NULL tender id.
+	if invited == nil || !*invited {
+		return false, nil
+	}
+
+	found = 1
+
+	return true, nil
+}
+
+func (r *InvitationRepo) UpdateStatus(
+	ctx context.Context,
+	invitationID entity.InvitationID,
+	status entity.InvitationStatus,
+	respondedAt time.Time,
+) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "invitation.update_status", queryUpdateInvitationStatus)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryUpdateInvitationStatus, uuidArg(invitationID), status, respondedAt)
+	affected = tag.RowsAffected()
+
+	return constraintError(err)
+}
+
+func (r *InvitationRepo) Delete(ctx context.Context, invitationID entity.InvitationID) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "invitation.delete", queryDeleteInvitation)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryDeleteInvitation, uuidArg(invitationID))
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *PostgresRepo) NewInvitationRepo(ctx context.Context) (*InvitationRepo, error) {
+	r.requireRelations("tender_invitations")
+
+	ir := &InvitationRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("invitation"),
+	}
+
+	if err := r.InitTables(ctx, invitationTables); err != nil {
+		return nil, err
+	}
+
+	return ir, nil
+}
+
+// viewerArgs are the user and organization arguments matching invitations,
+// both NULL for an anonymous viewer so that no invitation matches.
+func viewerArgs(viewer *entity.Viewer) (pgtype.UUID, []pgtype.UUID) {
+	if viewer == nil {
+		return pgtype.UUID{}, nil
+	}
+
+	return uuidArg(viewer.UserID), uuidArgs(viewer.Organizations)
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	queryInitLots = `CREATE TABLE IF NOT EXISTS tender_lots (
+		id UUID PRIMARY KEY,
+		tender_id UUID NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		description VARCHAR(500),
+		service_type VARCHAR(50) NOT NULL,
+		quantity BIGINT NOT NULL,
+		unit VARCHAR(50),
+		budget_amount BIGINT NOT NULL,
+		budget_currency CHAR(3) NOT NULL,
+		status TEXT NOT NULL,
+		awarded_bid_id UUID,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS tender_lots_tender_id_idx ON tender_lots (tender_id)`
+
+	lotColumns = `id, tender_id, name, description, service_type, quantity, unit,
+		budget_amount, budget_currency, status, awarded_bid_id, created_at`
+
+	queryCreateLot       = `INSERT INTO tender_lots (` + lotColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	queryReadLot         = `SELECT ` + lotColumns + ` FROM tender_lots WHERE id = $1`
+	queryListLots        = `SELECT ` + lotColumns + ` FROM tender_lots WHERE tender_id = $1 ORDER BY created_at, name`
+	queryLockLots        = queryListLots + ` FOR UPDATE`
+	queryUpdateLotStatus = `UPDATE tender_lots SET status = $2, awarded_bid_id = $3 WHERE id = $1`
+)
+
+var lotTables = map[string]string{
+	"tender_lots": queryInitLots,
+}
+
+type LotRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanLot(row pgx.Row) (*entity.Lot, error) {
+	var (
+		lot          entity.Lot
+		description  *string
+		unit         *string
+		awardedBidID *entity.BidId
+	)
+
+	if err := row.Scan(
+		&lot.ID,
+		&lot.TenderID,
+		&lot.Name,
+		&description,
+		&lot.ServiceType,
+		&lot.Quantity,
+		&unit,
+		&lot.Budget.Amount,
+		&lot.Budget.Currency,
+		&lot.Status,
+		&awardedBidID,
+		&lot.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if description != nil {
+		lot.Description = *description
+	}
+
+	if unit != nil {
+		lot.Unit = *unit
+	}
+
+	if awardedBidID != nil {
+		lot.AwardedBidID = *awardedBidID
+	}
+
+	return &lot, nil
+}
+
+func (r *LotRepo) Create(ctx context.Context, lot *entity.Lot) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "lot.create", queryCreateLot)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateLot,
+		uuidArg(lot.ID),
+		uuidArg(lot.TenderID),
+		lot.Name,
+		lot.Description,
+		lot.ServiceType,
+		lot.Quantity,
+		lot.Unit,
+		lot.Budget.Amount,
+		lot.Budget.Currency,
+		lot.Status,
+		uuidArg(lot.AwardedBidID),
+		lot.CreatedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return constraintError(err)
+}
+
+func (r *LotRepo) Read(ctx context.Context, lotID entity.LotID) (_ *entity.Lot, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "lot.read", queryReadLot)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	lot, err := scanLot(conn(ctx, r.db).QueryRow(ctx, queryReadLot, uuidArg(lotID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	found = 1
+
+	return lot, nil
+}
+
+func (r *LotRepo) List(ctx context.Context, tenderID entity.TenderID) ([]*entity.Lot, error) {
+	return r.list(ctx, "lot.list", queryListLots, tenderID)
+}
+
+func (r *LotRepo) Lock(ctx context.Context, tenderID entity.TenderID) ([]*entity.Lot, error) {
+	return r.list(ctx, "lot.lock", queryLockLots, tenderID)
+}
+
+func (r *LotRepo) list(ctx context.Context, name, query string, tenderID entity.TenderID) (_ []*entity.Lot, err error) {
+	var lots []*entity.Lot
+
+	ctx, span := startStatement(ctx, r.timeout, name, query)
+	defer func() { span.end(int64(len(lots)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, uuidArg(tenderID))
+	if err != nil {
+		return nil, err
+	}
+
+	lots, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Lot, error) {
+		return scanLot(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+func (r *LotRepo) UpdateStatus(ctx context.Context, lotID entity.LotID, status entity.LotStatus, awardedBidID entity.BidId) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "lot.update_status", queryUpdateLotStatus)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryUpdateLotStatus, uuidArg(lotID), status, uuidArg(awardedBidID))
+	affected = tag.RowsAffected()
+
+	return constraintError(err)
+}
+
+func (r *PostgresRepo) NewLotRepo(ctx context.Context) (*LotRepo, error) {
+	r.requireRelations("tender_lots")
+
+	lr := &LotRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("lot"),
+	}
+
+	if err := r.InitTables(ctx, lotTables); err != nil {
+		return nil, err
+	}
+
+	return lr, nil
+}
@@ -0,0 +1,230 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const (
+	queryInitSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`
+	// Replicas starting at once wait here for the one applying the migration.
+	queryLockSchemaMigrations = `SELECT pg_advisory_xact_lock(hashtext('schema_migrations'))`
+	queryMigrationApplied     = `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`
+	queryRecordMigration      = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+
+	// queryMigrateIntegrity gives the tender and bid tables the types and
+	// constraints their columns always meant. Existing rows are converted, not
+	// changed: a value that does not fit fails the migration, which then leaves
+	// the schema as it was. References to employee and organization, owned by
+	// another system that may have deleted rows since, are only checked for new rows.
+	queryMigrateIntegrity = `
+	ALTER TABLE tenders DROP CONSTRAINT IF EXISTS tenders_organization_id_key;
+	ALTER TABLE tenders
+		ALTER COLUMN organization_id TYPE UUID USING organization_id::uuid,
+		ALTER COLUMN service_type TYPE VARCHAR(50),
+		ALTER COLUMN service_type SET NOT NULL,
+		ALTER COLUMN status SET NOT NULL;
+	ALTER TABLE tenders
+		ADD CONSTRAINT tenders_status_check CHECK (status IN ('Created', 'Published', 'Closed')),
+		ADD CONSTRAINT tenders_service_type_fkey FOREIGN KEY (service_type) REFERENCES service_types (name),
+		ADD CONSTRAINT tenders_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organization (id) NOT VALID;
+	ALTER TABLE tenders_archive
+		ALTER COLUMN organization_id TYPE UUID USING organization_id::uuid,
+		ALTER COLUMN service_type TYPE VARCHAR(50);
+
+	ALTER TABLE bid
+		ALTER COLUMN tender_id TYPE UUID USING tender_id::uuid,
+		ALTER COLUMN tender_id SET NOT NULL,
+		ALTER COLUMN author_id TYPE UUID USING author_id::uuid,
+		ALTER COLUMN author_id SET NOT NULL,
+		ALTER COLUMN status SET NOT NULL;
+	-- author_id is an employee or an organization depending on author_type, each gets a column to reference
+	-- it. They are derived, so bid_archive, which references nothing but archived tenders, goes without.
+	ALTER TABLE bid
+		ADD COLUMN author_user_id UUID GENERATED ALWAYS AS (CASE WHEN author_type = 'User' THEN author_id END) STORED,
+		ADD COLUMN author_organization_id UUID GENERATED ALWAYS AS (CASE WHEN author_type = 'Organization' THEN author_id END) STORED,
+		ADD CONSTRAINT bid_status_check CHECK (status IN ('Created', 'Published', 'Canceled')),
+		ADD CONSTRAINT bid_author_type_check CHECK (author_type IN ('User', 'Organization')),
+		ADD CONSTRAINT bid_tender_id_fkey FOREIGN KEY (tender_id) REFERENCES tenders (id),
+		ADD CONSTRAINT bid_lot_id_fkey FOREIGN KEY (lot_id) REFERENCES tender_lots (id);
+	ALTER TABLE bid
+		ADD CONSTRAINT bid_author_user_id_fkey FOREIGN KEY (author_user_id) REFERENCES employee (id) NOT VALID,
+		ADD CONSTRAINT bid_author_organization_id_fkey FOREIGN KEY (author_organization_id) REFERENCES organization (id) NOT VALID;
+	ALTER TABLE bid_archive
+		ALTER COLUMN tender_id TYPE UUID USING tender_id::uuid,
+		ALTER COLUMN author_id TYPE UUID USING author_id::uuid,
+		ADD CONSTRAINT bid_archive_tender_id_fkey FOREIGN KEY (tender_id) REFERENCES tenders_archive (id);
+
+	-- Lots, invitations and the other details of a tender stay where they are
+	-- when the tender is archived, so they do not reference tenders.
+	ALTER TABLE tender_lots
+		ALTER COLUMN service_type TYPE VARCHAR(50),
+		ADD CONSTRAINT tender_lots_status_check CHECK (status IN ('Open', 'Awarded', 'Canceled')),
+		ADD CONSTRAINT tender_lots_service_type_fkey FOREIGN KEY (service_type) REFERENCES service_types (name);
+	ALTER TABLE tender_invitations
+		ALTER COLUMN invitee_id TYPE UUID USING invitee_id::uuid,
+		ADD CONSTRAINT tender_invitations_invitee_type_check CHECK (invitee_type IN ('User', 'Organization')),
+		ADD CONSTRAINT tender_invitations_status_check CHECK (status IN ('Pending', 'Accepted', 'Declined'));
+
+	-- The unique index on organization_id used to serve the listings of an organization.
+	CREATE INDEX tenders_organization_id_name_idx ON tenders (organization_id, name) WHERE deleted_at IS NULL;
+	CREATE INDEX tenders_published_service_type_name_idx ON tenders (service_type, name)
+		WHERE status = 'Published' AND deleted_at IS NULL;
+	CREATE INDEX tenders_closed_created_at_idx ON tenders (created_at) WHERE status = 'Closed' AND deleted_at IS NULL;
+	CREATE INDEX bid_tender_id_idx ON bid (tender_id);
+	CREATE INDEX bid_author_id_name_idx ON bid (author_id, name) WHERE deleted_at IS NULL;
+	CREATE INDEX bid_lot_id_idx ON bid (lot_id) WHERE lot_id IS NOT NULL;
+	CREATE INDEX bid_deleted_at_idx ON bid (deleted_at) WHERE deleted_at IS NOT NULL;
+	CREATE INDEX bid_archive_tender_id_idx ON bid_archive (tender_id)`
+
+	// queryMigrateCurrencyExponents moves amounts stored with two decimals in
+	// every currency to the minor units of their currency, see entity.Currency.Exponent:
+	// a JPY amount is divided by 100 and rounded, a KWD amount is multiplied by 10.
+	// Line items keep decimal strings in major units, only the decimals a currency
+	// does not have are rounded off.
+	queryMigrateCurrencyExponents = `
+	CREATE FUNCTION pg_temp.exponent(currency TEXT) RETURNS INTEGER LANGUAGE SQL IMMUTABLE AS $$
+		SELECT CASE
+			WHEN currency IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 0
+			WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 3
+			ELSE 2
+		END
+	$$;
+
+	UPDATE tenders SET budget_amount = round(budget_amount * 10::numeric ^ (pg_temp.exponent(budget_currency) - 2))
+		WHERE pg_temp.exponent(budget_currency) <> 2;
+	UPDATE tenders_archive SET budget_amount = round(budget_amount * 10::numeric ^ (pg_temp.exponent(budget_currency) - 2))
+		WHERE pg_temp.exponent(budget_currency) <> 2;
+	UPDATE tender_lots SET budget_amount = round(budget_amount * 10::numeric ^ (pg_temp.exponent(budget_currency) - 2))
+		WHERE pg_temp.exponent(budget_currency) <> 2;
+
+	UPDATE bid SET price_amount = round(price_amount * 10::numeric ^ (pg_temp.exponent(price_currency) - 2))
+		WHERE pg_temp.exponent(price_currency) <> 2;
+	UPDATE bid_archive SET price_amount = round(price_amount * 10::numeric ^ (pg_temp.exponent(price_currency) - 2))
+		WHERE pg_temp.exponent(price_currency) <> 2;
+	UPDATE bid SET line_items = (
+		SELECT COALESCE(jsonb_agg(
+			jsonb_set(item, '{unitPrice,amount}', to_jsonb(round((item->'unitPrice'->>'amount')::numeric)::text))
+			ORDER BY position), '[]')
+		FROM jsonb_array_elements(line_items) WITH ORDINALITY AS items (item, position))
+		WHERE pg_temp.exponent(price_currency) = 0 AND jsonb_typeof(line_items) = 'array';
+	UPDATE bid_archive SET line_items = (
+		SELECT COALESCE(jsonb_agg(
+			jsonb_set(item, '{unitPrice,amount}', to_jsonb(round((item->'unitPrice'->>'amount')::numeric)::text))
+			ORDER BY position), '[]')
+		FROM jsonb_array_elements(line_items) WITH ORDINALITY AS items (item, position))
+		WHERE pg_temp.exponent(price_currency) = 0 AND jsonb_typeof(line_items) = 'array';
+
+	-- A decrement rounded to nothing would let an offer repeat the best price.
+	UPDATE tender_auctions SET
+		start_price = round(start_price * 10::numeric ^ (pg_temp.exponent(currency) - 2)),
+		min_decrement = GREATEST(round(min_decrement * 10::numeric ^ (pg_temp.exponent(currency) - 2)), 1),
+		best_price = round(best_price * 10::numeric ^ (pg_temp.exponent(currency) - 2))
+		WHERE pg_temp.exponent(currency) <> 2;
+	UPDATE auction_offers SET amount = round(amount * 10::numeric ^ (pg_temp.exponent(currency) - 2))
+		WHERE pg_temp.exponent(currency) <> 2;
+
+	-- Temporary objects live as long as the pooled connection, not the transaction.
+	DROP FUNCTION pg_temp.exponent(TEXT)`
+)
+
+type migration struct {
+	version int
+	name    string
+	query   string
+}
+
+// migrations change the tables the repos have created. Each runs once, in
+// its own transaction, in the order listed here. Append new ones, never edit
+// or reorder those that may have been applied.
+var migrations = []migration{
+	{version: 1, name: "referential integrity", query: queryMigrateIntegrity},
+	{version: 2, name: "currency exponents", query: queryMigrateCurrencyExponents},
+}
+
+// Migrate applies the migrations not applied yet. Call it after every repo
+// has been created, migrations may change any of their tables.
+func (r *PostgresRepo) Migrate(ctx context.Context) error {
+	r.requireRelations("schema_migrations")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	return applyMigrations(ctx, r.db, migrations, r.logger)
+}
+
+// migrationDB is the part of the pool the migrations run on.
+type migrationDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+func applyMigrations(ctx context.Context, db migrationDB, migrations []migration, logger *zap.Logger) error {
+	if _, err := db.Exec(ctx, queryInitSchemaMigrations); err != nil {
+		return fmt.Errorf("table schema_migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if err := applyMigration(ctx, db, m, logger); err != nil {
+			logger.Error("migration failed", zap.Int("version", m.version), zap.String("name", m.name), zap.Error(err))
+			return fmt.Errorf("migration %d %s: %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration applies the migration and records it in one transaction,
+// unless it was recorded before.
+func applyMigration(ctx context.Context, db migrationDB, m migration, logger *zap.Logger) (err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if rollbackErr := tx.Rollback(context.WithoutCancel(ctx)); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = errors.Join(err, rollbackErr)
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, queryLockSchemaMigrations); err != nil {
+		return err
+	}
+
+	var applied bool
+	if err := tx.QueryRow(ctx, queryMigrationApplied, m.version).Scan(&applied); err != nil {
+		return err
+	}
+
+	if applied {
+		return nil
+	}
+
+	logger.Info("migration started", zap.Int("version", m.version), zap.String("name", m.name))
+
+	if _, err := tx.Exec(ctx, m.query); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, queryRecordMigration, m.version, m.name); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	logger.Info("migration finished", zap.Int("version", m.version), zap.String("name", m.name))
+
+	return nil
+}
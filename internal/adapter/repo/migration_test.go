@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+)
+
+// migrationDBStub keeps schema_migrations in memory and records the statements
+// of committed transactions. A statement equal to failQuery fails.
+type migrationDBStub struct {
+	applied   map[int]bool
+	executed  []string
+	failQuery string
+}
+
+type migrationTxStub struct {
+	pgx.Tx
+	db       *migrationDBStub
+	executed []string
+	recorded []int
+	done     bool
+}
+
+func (r *migrationDBStub) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+
+func (r *migrationDBStub) Begin(context.Context) (pgx.Tx, error) {
+	return &migrationTxStub{db: r}, nil
+}
+
+func (r *migrationTxStub) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if sql == r.db.failQuery {
+		return pgconn.CommandTag{}, errors.New("syntax error")
+	}
+
+	switch sql {
+	case queryLockSchemaMigrations:
+	case queryRecordMigration:
+		r.recorded = append(r.recorded, args[0].(int))
+	default:
+		r.executed = append(r.executed, sql)
+	}
+
+	return pgconn.CommandTag{}, nil
+}
+
+func (r *migrationTxStub) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	return rowStub{r.db.applied[args[0].(int)]}
+}
+
+func (r *migrationTxStub) Commit(context.Context) error {
+	r.done = true
+	r.db.executed = append(r.db.executed, r.executed...)
+
+	for _, version := range r.recorded {
+		r.db.applied[version] = true
+	}
+
+	return nil
+}
+
+func (r *migrationTxStub) Rollback(context.Context) error {
+	if r.done {
+		return pgx.ErrTxClosed
+	}
+
+	r.done = true
+
+	return nil
+}
+
+func TestApplyMigrationsTwice(t *testing.T) {
+	ctx := context.Background()
+	db := &migrationDBStub{applied: make(map[int]bool)}
+
+	if err := applyMigrations(ctx, db, migrations, zap.NewNop()); err != nil {
+		t.Fatalf("first applyMigrations() error = %v", err)
+	}
+
+	if len(db.executed) != len(migrations) || len(db.applied) != len(migrations) {
+		t.Fatalf("first run executed %d and recorded %d migrations, want %d", len(db.executed), len(db.applied), len(migrations))
+	}
+
+	db.executed = nil
+
+	if err := applyMigrations(ctx, db, migrations, zap.NewNop()); err != nil {
+		t.Fatalf("second applyMigrations() error = %v", err)
+	}
+
+	if len(db.executed) != 0 || len(db.applied) != len(migrations) {
+		t.Errorf("second run executed %d statements and left %d migrations recorded, want no changes", len(db.executed), len(db.applied))
+	}
+}
+
+func TestApplyMigrationsFailure(t *testing.T) {
+	ctx := context.Background()
+	db := &migrationDBStub{applied: make(map[int]bool), failQuery: "broken"}
+	steps := []migration{
+		{version: 1, name: "first", query: "first"},
+		{version: 2, name: "broken", query: "broken"},
+		{version: 3, name: "third", query: "third"},
+	}
+
+	if err := applyMigrations(ctx, db, steps, zap.NewNop()); err == nil {
+		t.Fatal("applyMigrations() succeeded with a failing migration")
+	}
+
+	if !slices.Equal(db.executed, []string{"first"}) || db.applied[2] || db.applied[3] {
+		t.Fatalf("executed %v, recorded %v, want only the migration before the failing one", db.executed, db.applied)
+	}
+
+	db.failQuery = ""
+	steps[1].query = "fixed"
+
+	if err := applyMigrations(ctx, db, steps, zap.NewNop()); err != nil {
+		t.Fatalf("applyMigrations() error = %v after the fix", err)
+	}
+
+	if !slices.Equal(db.executed, []string{"first", "fixed", "third"}) || len(db.applied) != 3 {
+		t.Errorf("executed %v, recorded %v, want the rest applied once", db.executed, db.applied)
+	}
+}
@@ -0,0 +1,248 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	queryInitNotifications = `CREATE TABLE IF NOT EXISTS notifications (
+		id UUID PRIMARY KEY,
+		user_id UUID NOT NULL,
+		event VARCHAR(50) NOT NULL,
+		tender_id UUID NOT NULL,
+		bid_id UUID,
+		question_id UUID,
+		subject VARCHAR(200) NOT NULL,
+		text TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		read_at TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS notifications_user_id_created_at_idx ON notifications (user_id, created_at DESC)`
+
+	queryInitNotificationPreferences = `CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id UUID PRIMARY KEY,
+		email VARCHAR(254),
+		disabled JSONB NOT NULL DEFAULT '{}',
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	notificationColumns = `id, user_id, event, tender_id, bid_id, question_id, subject, text, created_at, read_at`
+
+	queryCreateNotification = `INSERT INTO notifications (` + notificationColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	queryListNotifications  = `SELECT ` + notificationColumns + ` FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC `
+	queryMarkNotificationRead = `UPDATE notifications SET read_at = COALESCE(read_at, $3) WHERE id = $1 AND user_id = $2`
+
+	queryReadNotificationPreferences = `SELECT user_id, email, disabled FROM notification_preferences WHERE user_id = ANY($1)`
+	querySaveNotificationPreferences = `INSERT INTO notification_preferences (user_id, email, disabled, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET email = EXCLUDED.email, disabled = EXCLUDED.disabled, updated_at = EXCLUDED.updated_at`
+)
+
+var notificationTables = map[string]string{
+	"notifications":            queryInitNotifications,
+	"notification_preferences": queryInitNotificationPreferences,
+}
+
+type NotificationRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanNotification(row pgx.Row) (*entity.Notification, error) {
+	var (
+		notification entity.Notification
+		bidID        *entity.BidId
+		questionID   *entity.QuestionID
+	)
+
+	if err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Event,
+		&notification.TenderID,
+		&bidID,
+		&questionID,
+		&notification.Subject,
+		&notification.Text,
+		&notification.CreatedAt,
+		&notification.ReadAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if bidID != nil {
+		notification.BidID = *bidID
+	}
+
+	if questionID != nil {
+		notification.QuestionID = *questionID
+	}
+
+	return &notification, nil
+}
+
+func (r *NotificationRepo) Create(ctx context.Context, notification *entity.Notification) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "notification.create", queryCreateNotification)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateNotification,
+		uuidArg(notification.ID),
+		uuidArg(notification.UserID),
+		notification.Event,
+		uuidArg(notification.TenderID),
+		uuidArg(notification.BidID),
+		uuidArg(notification.QuestionID),
+		notification.Subject,
+		notification.Text,
+		notification.CreatedAt,
+		notification.ReadAt,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *NotificationRepo) List(
+	ctx context.Context,
+	userID entity.UserID,
+	unreadOnly bool,
+	limitOffset *entity.RequestLimitOffset,
+) (_ []*entity.Notification, err error) {
+	var notifications []*entity.Notification
+
+	query, args := buildLimitOffset(queryListNotifications, []any{uuidArg(userID), unreadOnly}, limitOffset)
+
+	ctx, span := startStatement(ctx, r.timeout, "notification.list", query)
+	defer func() { span.end(int64(len(notifications)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Notification, error) {
+		return scanNotification(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepo) MarkRead(
+	ctx context.Context,
+	userID entity.UserID,
+	notificationID entity.NotificationID,
+	readAt time.Time,
+) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "notification.mark_read", queryMarkNotificationRead)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryMarkNotificationRead, uuidArg(notificationID), uuidArg(userID), readAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected = tag.RowsAffected()
+
+	return affected > 0, nil
+}
+
+func (r *NotificationRepo) ReadPreferences(
+	ctx context.Context,
+	userIDs []entity.UserID,
+) (_ map[entity.UserID]*entity.NotificationPreferences, err error) {
+	preferences := make(map[entity.UserID]*entity.NotificationPreferences, len(userIDs))
+
+	ctx, span := startStatement(ctx, r.timeout, "notification.read_preferences", queryReadNotificationPreferences)
+	defer func() { span.end(int64(len(preferences)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, queryReadNotificationPreferences, uuidArgs(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID entity.UserID
+			email  *string
+			prefs  entity.NotificationPreferences
+		)
+
+		if err := rows.Scan(&userID, &email, &prefs.Disabled); err != nil {
+			return nil, err
+		}
+
+		if email != nil {
+			prefs.Email = *email
+		}
+
+		preferences[userID] = &prefs
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (r *NotificationRepo) SavePreferences(
+	ctx context.Context,
+	userID entity.UserID,
+	preferences *entity.NotificationPreferences,
+) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "notification.save_preferences", querySaveNotificationPreferences)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	var email *string
+	if preferences.Email != "" {
+		email = &preferences.Email
+	}
+
+	disabled := preferences.Disabled
+	if disabled == nil {
+		disabled = map[entity.NotificationChannel][]entity.EventType{}
+	}
+
+	tag, err := conn(ctx, r.db).Exec(ctx, querySaveNotificationPreferences, uuidArg(userID), email, disabled)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *PostgresRepo) NewNotificationRepo(ctx context.Context) (*NotificationRepo, error) {
+	r.requireRelations("notifications", "notification_preferences")
+
+	nr := &NotificationRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("notification"),
+	}
+
+	if err := r.InitTables(ctx, notificationTables); err != nil {
+		return nil, err
+	}
+
+	return nr, nil
+}
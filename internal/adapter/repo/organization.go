@@ -2,11 +2,13 @@ package repo
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"time"
 
 	"avito2024/internal/app/core/entity"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
@@ -33,7 +35,7 @@ const (
 	)`
 
 	queryFindOrganizationsByResponsible = `SELECT organization_id FROM organization_responsible WHERE user_id = $1`
-	queryFindResponsibleUsers           = `SELECT user_id FROM organization_responsible WHERE organization_id = $1`
+	queryFindResponsibleUsers           = `SELECT user_id FROM organization_responsible WHERE organization_id = ANY($1)`
 
 	queryFindOrganizationByID = `SELECT id FROM organization WHERE id = $1`
 )
@@ -45,89 +47,80 @@ var organizationTables = map[string]string{
 }
 
 type OrganizationRepo struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
 }
 
-func (r *OrganizationRepo) FindOrganizationsByResponsibleUserID(ctx context.Context, userID entity.UserID) ([]entity.OrganizationID, error) {
-	rows, err := r.db.QueryContext(ctx, queryFindOrganizationsByResponsible, userID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-
-		return nil, err
-	}
-
+func (r *OrganizationRepo) FindOrganizationsByResponsibleUserID(ctx context.Context, userID entity.UserID) (_ []entity.OrganizationID, err error) {
 	var ids []entity.OrganizationID
 
-	for rows.Next() {
-		var id entity.OrganizationID
-		if err := rows.Scan(&id); err != nil {
-			return nil, err
-		}
+	ctx, span := startStatement(ctx, r.timeout, "organization.find_by_responsible", queryFindOrganizationsByResponsible)
+	defer func() { span.end(int64(len(ids)), err) }()
 
-		ids = append(ids, id)
+	ids, err = collectIDs[entity.OrganizationID](conn(ctx, r.db).Query(ctx, queryFindOrganizationsByResponsible, uuidArg(userID)))
+	if err != nil {
+		return nil, err
 	}
 
 	return ids, nil
 }
 
-func (r *OrganizationRepo) ReadResponsibleUserOrganization(ctx context.Context, userID entity.UserID) ([]entity.OrganizationID, error) {
+func (r *OrganizationRepo) ReadResponsibleUserOrganization(ctx context.Context, userID entity.UserID) (_ []entity.OrganizationID, err error) {
 	var organizationIDs []entity.OrganizationID
 
-	rows, err := r.db.QueryContext(ctx, queryFindOrganizationsByResponsible, userID)
+	ctx, span := startStatement(ctx, r.timeout, "organization.read_responsible_user_organization", queryFindOrganizationsByResponsible)
+	defer func() { span.end(int64(len(organizationIDs)), err) }()
+
+	organizationIDs, err = collectIDs[entity.OrganizationID](conn(ctx, r.db).Query(ctx, queryFindOrganizationsByResponsible, uuidArg(userID)))
 	if err != nil {
 		return nil, err
 	}
-	for rows.Next() {
-		var organization entity.OrganizationID
-		if err := rows.Scan(&organization); err != nil {
-			return nil, err
-		}
-		organizationIDs = append(organizationIDs, organization)
-	}
 
 	return organizationIDs, nil
 }
 
-func (r *OrganizationRepo) FindResponsibleUsers(ctx context.Context, organizations []entity.OrganizationID) ([]entity.UserID, error) {
+func (r *OrganizationRepo) FindResponsibleUsers(ctx context.Context, organizations []entity.OrganizationID) (_ []entity.UserID, err error) {
 	var users []entity.UserID
 
-	for i := range organizations {
-		rows, err := r.db.QueryContext(ctx, queryFindResponsibleUsers, organizations[i])
-		if err != nil {
-			return nil, err
-		}
-
-		for rows.Next() {
-			var userID entity.UserID
+	ctx, span := startStatement(ctx, r.timeout, "organization.find_responsible_users", queryFindResponsibleUsers)
+	defer func() { span.end(int64(len(users)), err) }()
 
-			if err := rows.Scan(&userID); err != nil {
-				return nil, err
-			}
-			users = append(users, userID)
-		}
+	users, err = collectIDs[entity.UserID](conn(ctx, r.db).Query(ctx, queryFindResponsibleUsers, uuidArgs(organizations)))
+	if err != nil {
+		return nil, err
 	}
 
 	return users, nil
 }
 
 func (r *OrganizationRepo) Exists(ctx context.Context, orgID entity.OrganizationID) bool {
+	ctx, span := startStatement(ctx, r.timeout, "organization.exists", queryFindOrganizationByID)
+
 	var id string
 
-	err := r.db.QueryRowContext(ctx, queryFindOrganizationByID, orgID).Scan(&id)
+	err := conn(ctx, r.db).QueryRow(ctx, queryFindOrganizationByID, uuidArg(orgID)).Scan(&id)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+
+		span.end(0, err)
 		return false
 	}
 
-	return id == string(orgID)
+	span.end(1, nil)
+
+	return true
 }
 
 func (r *PostgresRepo) NewOrganizationRepo(ctx context.Context, isTest bool) (*OrganizationRepo, error) {
+	r.requireRelations("organization", "organization_responsible")
+
 	or := &OrganizationRepo{
-		db:     r.db,
-		logger: r.logger.Named("organization"),
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("organization"),
 	}
 
 	if isTest {
@@ -2,16 +2,27 @@ package repo
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+
+	"avito2024/internal/config"
 )
 
-const defaultTimeout = time.Minute
+const (
+	defaultTimeout = time.Minute
+
+	connectBackoffMax = 15 * time.Second
+
+	// The pool counts as nearly saturated at 80% of connections in use.
+	poolBusyNumerator   = 4
+	poolBusyDenominator = 5
+)
 
 var (
 	errFilterIsEmpty = errors.New("filter is empty")
@@ -22,8 +33,17 @@ var defaultTables = map[string]string{
 }
 
 type PostgresRepo struct {
-	db     *sql.DB
+	db     *pgxpool.Pool
+	cfg    *config.Postgres
 	logger *zap.Logger
+
+	// relations are the tables the repos depend on, checked by the migration health check.
+	relations []string
+}
+
+// Pool exposes the connection pool for instrumentation.
+func (r *PostgresRepo) Pool() *pgxpool.Pool {
+	return r.db
 }
 
 func (r *PostgresRepo) InitTables(ctx context.Context, tables map[string]string) error {
@@ -32,7 +52,7 @@ func (r *PostgresRepo) InitTables(ctx context.Context, tables map[string]string)
 
 	for name, query := range tables {
 		r.logger.Info("init table started", zap.String("name", name))
-		if _, err := r.db.ExecContext(ctx, query); err != nil {
+		if _, err := r.db.Exec(ctx, query); err != nil {
 			r.logger.Error("init table failed", zap.String("name", name), zap.Error(err))
 			return fmt.Errorf("table %s: %w", name, err)
 		}
@@ -42,20 +62,162 @@ func (r *PostgresRepo) InitTables(ctx context.Context, tables map[string]string)
 	return nil
 }
 
-func NewPostgresRepo(ctx context.Context, connString string, logger *zap.Logger) (*PostgresRepo, error) {
-	db, err := sql.Open("postgres", connString)
+func NewPostgresRepo(ctx context.Context, cfg *config.Postgres, logger *zap.Logger) (*PostgresRepo, error) {
+	poolConfig, err := newPoolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	pr := &PostgresRepo{
 		db:     db,
+		cfg:    cfg,
 		logger: logger.Named("pgRepo"),
 	}
 
+	if err := pr.connect(ctx); err != nil {
+		return nil, err
+	}
+
 	if err := pr.InitTables(ctx, defaultTables); err != nil {
 		return nil, err
 	}
 
 	return pr, nil
 }
+
+// newPoolConfig applies the pool limits and the statement cache of cfg on top of the connection string.
+func newPoolConfig(cfg *config.Postgres) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.ConnectionString.Value())
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = int32(cfg.MaxConns)
+	poolConfig.MinConns = int32(cfg.MinConns)
+	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime.Std()
+	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime.Std()
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	poolConfig.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+
+	return poolConfig, nil
+}
+
+// Close waits for acquired connections to be released and closes the pool.
+func (r *PostgresRepo) Close() {
+	r.db.Close()
+}
+
+// connect pings the database until it answers, backing off exponentially between attempts.
+// The pool dials lazily, so without it an unreachable database only shows up on the first request.
+func (r *PostgresRepo) connect(ctx context.Context) error {
+	backoff := r.cfg.ConnectBackoff.Std()
+
+	var err error
+
+	for attempt := 1; attempt <= r.cfg.ConnectAttempts; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, defaultCheckTimeout)
+		err = r.db.Ping(pingCtx)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		r.logger.Warn("database is not reachable",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		if attempt == r.cfg.ConnectAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, connectBackoffMax)
+	}
+
+	return fmt.Errorf("connect to database after %d attempts: %w", r.cfg.ConnectAttempts, err)
+}
+
+// MonitorPool logs a warning whenever requests had to wait for a free connection
+// or the pool is close to its limit. It blocks until ctx is done.
+func (r *PostgresRepo) MonitorPool(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PoolStatsInterval.Std())
+	defer ticker.Stop()
+
+	prev := r.db.Stat()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats := r.db.Stat()
+
+		fields := []zap.Field{
+			zap.Int32("acquired", stats.AcquiredConns()),
+			zap.Int32("idle", stats.IdleConns()),
+			zap.Int32("max", stats.MaxConns()),
+			zap.Int64("waits", stats.EmptyAcquireCount()-prev.EmptyAcquireCount()),
+			zap.Duration("acquireDuration", stats.AcquireDuration()-prev.AcquireDuration()),
+		}
+
+		switch {
+		case stats.EmptyAcquireCount() > prev.EmptyAcquireCount():
+			r.logger.Warn("connection pool saturated", fields...)
+		case stats.AcquiredConns()*poolBusyDenominator >= stats.MaxConns()*poolBusyNumerator:
+			r.logger.Warn("connection pool nearly saturated", fields...)
+		default:
+			r.logger.Debug("connection pool stats", fields...)
+		}
+
+		prev = stats
+	}
+}
+
+func (r *PostgresRepo) requireRelations(relations ...string) {
+	r.relations = append(r.relations, relations...)
+}
+
+// uuidArg binds id as a native uuid parameter. A malformed id becomes NULL,
+// which matches no row instead of failing the statement.
+func uuidArg[T ~string](id T) pgtype.UUID {
+	var arg pgtype.UUID
+	if err := arg.Scan(string(id)); err != nil {
+		return pgtype.UUID{}
+	}
+
+	return arg
+}
+
+// uuidArgs is uuidArg for a uuid[] parameter.
+func uuidArgs[T ~string](ids []T) []pgtype.UUID {
+	args := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, uuidArg(id))
+	}
+
+	return args
+}
+
+// collectIDs reads single column rows returned by Query and closes them.
+func collectIDs[T ~string](rows pgx.Rows, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[T])
+}
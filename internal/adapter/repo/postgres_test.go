@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"avito2024/internal/config"
+)
+
+func TestNewPoolConfig(t *testing.T) {
+	cfg := config.Default().Postgres
+	cfg.ConnectionString = "postgres://app:secret@db:5432/app?pool_max_conns=100"
+	cfg.MaxConns = 12
+	cfg.MinConns = 3
+	cfg.ConnMaxLifetime = config.Duration(30 * time.Minute)
+	cfg.ConnMaxIdleTime = config.Duration(5 * time.Minute)
+	cfg.StatementCacheCapacity = 64
+
+	poolConfig, err := newPoolConfig(&cfg)
+	if err != nil {
+		t.Fatalf("newPoolConfig() error = %v", err)
+	}
+
+	if poolConfig.MaxConns != 12 || poolConfig.MinConns != 3 {
+		t.Errorf("conns = %d..%d, want the configured 3..12", poolConfig.MinConns, poolConfig.MaxConns)
+	}
+
+	if poolConfig.MaxConnLifetime != 30*time.Minute || poolConfig.MaxConnIdleTime != 5*time.Minute {
+		t.Errorf("lifetime = %s, idle time = %s, want 30m and 5m", poolConfig.MaxConnLifetime, poolConfig.MaxConnIdleTime)
+	}
+
+	if poolConfig.ConnConfig.DefaultQueryExecMode != pgx.QueryExecModeCacheStatement {
+		t.Errorf("exec mode = %v, want the statement cache", poolConfig.ConnConfig.DefaultQueryExecMode)
+	}
+
+	if poolConfig.ConnConfig.StatementCacheCapacity != 64 {
+		t.Errorf("statement cache capacity = %d, want 64", poolConfig.ConnConfig.StatementCacheCapacity)
+	}
+
+	cfg.ConnectionString = "postgres://db:port/app"
+	if _, err := newPoolConfig(&cfg); err == nil {
+		t.Error("newPoolConfig() accepted a malformed connection string")
+	}
+}
+
+func TestStartStatementTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		parent  time.Duration
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{name: "statement timeout is shorter", parent: time.Hour, timeout: time.Second, want: time.Second},
+		{name: "request deadline is shorter", parent: time.Second, timeout: time.Hour, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, cancel := context.WithTimeout(context.Background(), tt.parent)
+			defer cancel()
+
+			ctx, span := startStatement(parent, tt.timeout, "tender.read", queryReadTender)
+
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("the statement has no deadline")
+			}
+
+			if left := time.Until(deadline); left > tt.want || left < tt.want-time.Second/2 {
+				t.Errorf("deadline in %s, want about %s", left, tt.want)
+			}
+
+			span.end(0, nil)
+
+			if ctx.Err() == nil {
+				t.Error("the statement context is still alive after end")
+			}
+
+			if parent.Err() != nil {
+				t.Error("end canceled the request context")
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	queryInitQuestions = `CREATE TABLE IF NOT EXISTS tender_questions (
+		id UUID PRIMARY KEY,
+		tender_id UUID NOT NULL,
+		author_id UUID NOT NULL,
+		text VARCHAR(2000) NOT NULL,
+		answer VARCHAR(2000),
+		public BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		answered_at TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS tender_questions_tender_id_idx ON tender_questions (tender_id)`
+
+	questionColumns = `id, tender_id, author_id, text, answer, public, created_at, answered_at`
+
+	queryCreateQuestion   = `INSERT INTO tender_questions (` + questionColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	queryReadQuestion     = `SELECT ` + questionColumns + ` FROM tender_questions WHERE id = $1`
+	queryListQuestions    = `SELECT ` + questionColumns + ` FROM tender_questions WHERE tender_id = $1 ORDER BY created_at`
+	queryListQuestionsFor = `SELECT ` + questionColumns + ` FROM tender_questions
+		WHERE tender_id = $1 AND (author_id = $2 OR (public AND answer IS NOT NULL))
+		ORDER BY created_at`
+	queryAnswerQuestion = `UPDATE tender_questions SET answer = $2, public = $3, answered_at = $4 WHERE id = $1`
+)
+
+var questionTables = map[string]string{
+	"tender_questions": queryInitQuestions,
+}
+
+type QuestionRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanQuestion(row pgx.Row) (*entity.Question, error) {
+	var (
+		question entity.Question
+		answer   *string
+	)
+
+	if err := row.Scan(
+		&question.ID,
+		&question.TenderID,
+		&question.AuthorID,
+		&question.Text,
+		&answer,
+		&question.Public,
+		&question.CreatedAt,
+		&question.AnsweredAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if answer != nil {
+		question.Answer = *answer
+	}
+
+	return &question, nil
+}
+
+func (r *QuestionRepo) Create(ctx context.Context, question *entity.Question) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "question.create", queryCreateQuestion)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateQuestion,
+		uuidArg(question.ID),
+		uuidArg(question.TenderID),
+		uuidArg(question.AuthorID),
+		question.Text,
+		nil,
+		question.Public,
+		question.CreatedAt,
+		question.AnsweredAt,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *QuestionRepo) Read(ctx context.Context, questionID entity.QuestionID) (_ *entity.Question, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "question.read", queryReadQuestion)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	question, err := scanQuestion(conn(ctx, r.db).QueryRow(ctx, queryReadQuestion, uuidArg(questionID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	found = 1
+
+	return question, nil
+}
+
+func (r *QuestionRepo) List(ctx context.Context, tenderID entity.TenderID) ([]*entity.Question, error) {
+	return r.list(ctx, "question.list", queryListQuestions, uuidArg(tenderID))
+}
+
+func (r *QuestionRepo) ListFor(ctx context.Context, tenderID entity.TenderID, userID entity.UserID) ([]*entity.Question, error) {
+	return r.list(ctx, "question.list_for", queryListQuestionsFor, uuidArg(tenderID), uuidArg(userID))
+}
+
+func (r *QuestionRepo) list(ctx context.Context, name, query string, args ...any) (_ []*entity.Question, err error) {
+	var questions []*entity.Question
+
+	ctx, span := startStatement(ctx, r.timeout, name, query)
+	defer func() { span.end(int64(len(questions)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Question, error) {
+		return scanQuestion(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+func (r *QuestionRepo) Answer(
+	ctx context.Context,
+	questionID entity.QuestionID,
+	answer string,
+	public bool,
+	answeredAt time.Time,
+) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "question.answer", queryAnswerQuestion)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryAnswerQuestion, uuidArg(questionID), answer, public, answeredAt)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *PostgresRepo) NewQuestionRepo(ctx context.Context) (*QuestionRepo, error) {
+	r.requireRelations("tender_questions")
+
+	qr := &QuestionRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("question"),
+	}
+
+	if err := r.InitTables(ctx, questionTables); err != nil {
+		return nil, err
+	}
+
+	return qr, nil
+}
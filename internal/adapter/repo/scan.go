@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// Column lists are spelled out so that adding a column never shifts what Scan reads.
+const (
+	tenderColumns = `id, name, description, service_type, status, organization_id, version, created_at, sealed, bid_deadline, invite_only,
+		budget_amount, budget_currency, budget_hidden`
+	bidColumns = `id, name, description, status, tender_id, author_type, author_id, version, created_at,
+		price_amount, price_currency, delivery_days, valid_until, line_items, lot_id`
+)
+
+func scanTender(row pgx.Row) (*entity.Tender, error) {
+	var (
+		tender         entity.Tender
+		budgetAmount   *int64
+		budgetCurrency *entity.Currency
+	)
+
+	if err := row.Scan(
+		&tender.ID,
+		&tender.Name,
+		&tender.Description,
+		&tender.ServiceType,
+		&tender.Status,
+		&tender.OrganizationID,
+		&tender.Version,
+		&tender.CreatedAt,
+		&tender.Sealed,
+		&tender.BidDeadline,
+		&tender.InviteOnly,
+		&budgetAmount,
+		&budgetCurrency,
+		&tender.BudgetHidden,
+	); err != nil {
+		return nil, err
+	}
+
+	if budgetAmount != nil && budgetCurrency != nil {
+		tender.Budget = &entity.Money{Amount: *budgetAmount, Currency: *budgetCurrency}
+	}
+
+	return &tender, nil
+}
+
+func scanBid(row pgx.Row) (*entity.Bid, error) {
+	var (
+		bid           entity.Bid
+		priceAmount   *int64
+		priceCurrency *entity.Currency
+		deliveryDays  *int32
+		validUntil    *time.Time
+		items         []entity.BidLineItem
+		lotID         *entity.LotID
+	)
+
+	if err := row.Scan(
+		&bid.ID,
+		&bid.Name,
+		&bid.Description,
+		&bid.Status,
+		&bid.TenderID,
+		&bid.AuthorType,
+		&bid.AuthorID,
+		&bid.Version,
+		&bid.CreatedAt,
+		&priceAmount,
+		&priceCurrency,
+		&deliveryDays,
+		&validUntil,
+		&items,
+		&lotID,
+	); err != nil {
+		return nil, err
+	}
+
+	if lotID != nil {
+		bid.LotID = *lotID
+	}
+
+	// Bids created before offers were introduced have none.
+	if priceAmount != nil && priceCurrency != nil {
+		bid.Offer = &entity.BidOffer{
+			Price: entity.Money{Amount: *priceAmount, Currency: *priceCurrency},
+			Items: items,
+		}
+
+		if deliveryDays != nil {
+			bid.Offer.DeliveryDays = *deliveryDays
+		}
+
+		if validUntil != nil {
+			bid.Offer.ValidUntil = *validUntil
+		}
+	}
+
+	return &bid, nil
+}
+
+// offerArgs are the arguments for the offer columns, all NULL without an offer.
+func offerArgs(offer *entity.BidOffer) []any {
+	if offer == nil {
+		return []any{nil, nil, nil, nil, nil}
+	}
+
+	return []any{offer.Price.Amount, offer.Price.Currency, offer.DeliveryDays, offer.ValidUntil, offer.Items}
+}
+
+// collectTenders reads every row returned by Query and closes them.
+func collectTenders(rows pgx.Rows, err error) ([]*entity.Tender, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Tender, error) {
+		return scanTender(row)
+	})
+}
+
+// collectBids reads every row returned by Query and closes them.
+func collectBids(rows pgx.Rows, err error) ([]*entity.Bid, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.Bid, error) {
+		return scanBid(row)
+	})
+}
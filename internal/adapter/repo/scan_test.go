@@ -0,0 +1,200 @@
+package repo
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// rowStub scans its values the way pgx does for columns of the destination
+// types: a nil value leaves a nullable destination nil.
+type rowStub []any
+
+func (r rowStub) Scan(dest ...any) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("scan of %d columns into %d destinations", len(r), len(dest))
+	}
+
+	for i, value := range r {
+		if value != nil {
+			reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+		}
+	}
+
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestScanTender(t *testing.T) {
+	createdAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	tenderRow := func(budgetAmount *int64, budgetCurrency *entity.Currency) rowStub {
+		return rowStub{
+			entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a"),
+			entity.TenderName("roads"),
+			entity.TenderDescription("repair"),
+			entity.TenderServiceType("Construction"),
+			entity.TenderStatusPublished,
+			entity.OrganizationID("9b8a7c6d-5e4f-4321-9fed-cba987654321"),
+			entity.TenderVersion(2),
+			createdAt,
+			true,
+			nil,
+			false,
+			budgetAmount,
+			budgetCurrency,
+			true,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		row        rowStub
+		wantBudget *entity.Money
+	}{
+		{
+			name:       "with a budget",
+			row:        tenderRow(ptr[int64](150000), ptr[entity.Currency]("RUB")),
+			wantBudget: &entity.Money{Amount: 150000, Currency: "RUB"},
+		},
+		{
+			name: "without a budget",
+			row:  tenderRow(nil, nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tender, err := scanTender(tt.row)
+			if err != nil {
+				t.Fatalf("scanTender() error = %v", err)
+			}
+
+			if tender.Name != "roads" || tender.Version != 2 || !tender.CreatedAt.Equal(createdAt) || !tender.Sealed || !tender.BudgetHidden {
+				t.Errorf("tender = %+v, columns are read out of order", tender)
+			}
+
+			if tender.BidDeadline != nil {
+				t.Errorf("BidDeadline = %v, want nil", tender.BidDeadline)
+			}
+
+			if !reflect.DeepEqual(tender.Budget, tt.wantBudget) {
+				t.Errorf("Budget = %+v, want %+v", tender.Budget, tt.wantBudget)
+			}
+		})
+	}
+}
+
+func TestScanBid(t *testing.T) {
+	validUntil := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	items := []entity.BidLineItem{{Name: "asphalt", Quantity: 10, Unit: "t", UnitPrice: entity.Money{Amount: 5000, Currency: "RUB"}}}
+
+	bidRow := func(priceAmount *int64, priceCurrency *entity.Currency, deliveryDays *int32, lotID *entity.LotID) rowStub {
+		row := rowStub{
+			entity.BidId("7d6c5b4a-3f2e-4d1c-8b0a-9f8e7d6c5b4a"),
+			entity.BidName("offer"),
+			entity.BidDescription("fast"),
+			entity.BidStatus("Published"),
+			entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a"),
+			entity.BidAuthorUser,
+			entity.BidAuthorId("0e9d8c7b-6a5f-4e3d-8c2b-1a0f9e8d7c6b"),
+			entity.BidVersion(1),
+			validUntil.AddDate(0, -1, 0),
+			priceAmount,
+			priceCurrency,
+			deliveryDays,
+			nil,
+			nil,
+			lotID,
+		}
+
+		if priceAmount != nil {
+			row[12] = &validUntil
+			row[13] = items
+		}
+
+		return row
+	}
+
+	tests := []struct {
+		name      string
+		row       rowStub
+		wantOffer *entity.BidOffer
+		wantLotID entity.LotID
+	}{
+		{
+			name: "with an offer for a lot",
+			row:  bidRow(ptr[int64](50000), ptr[entity.Currency]("RUB"), ptr[int32](14), ptr[entity.LotID]("lot-1")),
+			wantOffer: &entity.BidOffer{
+				Price:        entity.Money{Amount: 50000, Currency: "RUB"},
+				DeliveryDays: 14,
+				ValidUntil:   validUntil,
+				Items:        items,
+			},
+			wantLotID: "lot-1",
+		},
+		{
+			name:      "with an offer without a delivery time",
+			row:       bidRow(ptr[int64](50000), ptr[entity.Currency]("RUB"), nil, nil),
+			wantOffer: &entity.BidOffer{Price: entity.Money{Amount: 50000, Currency: "RUB"}, ValidUntil: validUntil, Items: items},
+		},
+		{
+			name: "created before offers",
+			row:  bidRow(nil, nil, nil, nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bid, err := scanBid(tt.row)
+			if err != nil {
+				t.Fatalf("scanBid() error = %v", err)
+			}
+
+			if bid.Name != "offer" || bid.AuthorType != entity.BidAuthorUser || bid.Version != 1 {
+				t.Errorf("bid = %+v, columns are read out of order", bid)
+			}
+
+			if bid.LotID != tt.wantLotID {
+				t.Errorf("LotID = %q, want %q", bid.LotID, tt.wantLotID)
+			}
+
+			if !reflect.DeepEqual(bid.Offer, tt.wantOffer) {
+				t.Errorf("Offer = %+v, want %+v", bid.Offer, tt.wantOffer)
+			}
+		})
+	}
+}
+
+func TestUUIDArg(t *testing.T) {
+	tests := []struct {
+		id        entity.TenderID
+		wantValid bool
+	}{
+		{id: "3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a", wantValid: true},
+		{id: "3c2b1a09", wantValid: false},
+		{id: "", wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.id), func(t *testing.T) {
+			arg := uuidArg(tt.id)
+			if arg.Valid != tt.wantValid {
+				t.Errorf("Valid = %v, want %v, malformed ids must be bound as NULL", arg.Valid, tt.wantValid)
+			}
+
+			if value, _ := arg.Value(); tt.wantValid && value != string(tt.id) {
+				t.Errorf("uuid = %v, want %s", value, tt.id)
+			}
+		})
+	}
+
+	if args := uuidArgs([]entity.TenderID{"3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a", "bad"}); len(args) != 2 || args[1].Valid {
+		t.Errorf("uuidArgs() = %v, want both ids with the malformed one NULL", args)
+	}
+}
@@ -0,0 +1,154 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	// The catalog starts with the types that used to be built in.
+	queryInitServiceTypes = `CREATE TABLE IF NOT EXISTS service_types (
+		name VARCHAR(50) PRIMARY KEY,
+		parent VARCHAR(50) REFERENCES service_types (name),
+		description VARCHAR(500),
+		archived BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO service_types (name) VALUES ('Construction'), ('Delivery'), ('Manufacture') ON CONFLICT DO NOTHING`
+
+	serviceTypeColumns = `name, parent, description, archived, created_at`
+
+	queryCreateServiceType = `INSERT INTO service_types (` + serviceTypeColumns + `) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO NOTHING`
+	queryListServiceTypes  = `SELECT ` + serviceTypeColumns + ` FROM service_types ORDER BY name`
+	queryUpdateServiceType = `UPDATE service_types SET parent = $2, description = $3, archived = $4 WHERE name = $1`
+)
+
+var serviceTypeTables = map[string]string{
+	"service_types": queryInitServiceTypes,
+}
+
+type ServiceTypeRepo struct {
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+func scanServiceType(row pgx.Row) (*entity.ServiceType, error) {
+	var (
+		serviceType entity.ServiceType
+		parent      *entity.TenderServiceType
+		description *string
+	)
+
+	if err := row.Scan(
+		&serviceType.Name,
+		&parent,
+		&description,
+		&serviceType.Archived,
+		&serviceType.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if parent != nil {
+		serviceType.Parent = *parent
+	}
+
+	if description != nil {
+		serviceType.Description = *description
+	}
+
+	return &serviceType, nil
+}
+
+func (r *ServiceTypeRepo) Create(ctx context.Context, serviceType *entity.ServiceType) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "service_type.create", queryCreateServiceType)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryCreateServiceType,
+		serviceType.Name,
+		parentArg(serviceType.Parent),
+		serviceType.Description,
+		serviceType.Archived,
+		serviceType.CreatedAt,
+	)
+	affected = tag.RowsAffected()
+
+	return affected == 1, err
+}
+
+func (r *ServiceTypeRepo) List(ctx context.Context) (_ []*entity.ServiceType, err error) {
+	var serviceTypes []*entity.ServiceType
+
+	ctx, span := startStatement(ctx, r.timeout, "service_type.list", queryListServiceTypes)
+	defer func() { span.end(int64(len(serviceTypes)), err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, queryListServiceTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceTypes, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (*entity.ServiceType, error) {
+		return scanServiceType(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceTypes, nil
+}
+
+func (r *ServiceTypeRepo) Update(ctx context.Context, serviceType *entity.ServiceType) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "service_type.update", queryUpdateServiceType)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
+		ctx,
+		queryUpdateServiceType,
+		serviceType.Name,
+		parentArg(serviceType.Parent),
+		serviceType.Description,
+		serviceType.Archived,
+	)
+	affected = tag.RowsAffected()
+
+	return err
+}
+
+func (r *PostgresRepo) NewServiceTypeRepo(ctx context.Context) (*ServiceTypeRepo, error) {
+	r.requireRelations("service_types")
+
+	sr := &ServiceTypeRepo{
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("service_type"),
+	}
+
+	if err := r.InitTables(ctx, serviceTypeTables); err != nil {
+		return nil, err
+	}
+
+	return sr, nil
+}
+
+// parentArg stores a root type with a NULL parent, which the foreign key allows.
+func parentArg(parent entity.TenderServiceType) *entity.TenderServiceType {
+	if parent == "" {
+		return nil
+	}
+
+	return &parent
+}
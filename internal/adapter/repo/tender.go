@@ -2,20 +2,26 @@ package repo
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/huandu/go-sqlbuilder"
-	"github.com/lib/pq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 
 	"avito2024/internal/app/core/entity"
+	"avito2024/internal/logger"
 )
 
 type TenderRepo struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db            *pgxpool.Pool
+	timeout       time.Duration
+	streamTimeout time.Duration
+	logger        *zap.Logger
 }
 
 const (
@@ -23,157 +29,314 @@ const (
 	    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
 		name VARCHAR(100) NOT NULL,
 		description VARCHAR(500),
-		service_type TEXT,
-		status TEXT,
-		organization_id VARCHAR(50) UNIQUE NOT NULL,
+		service_type VARCHAR(50) NOT NULL,
+		status TEXT NOT NULL,
+		organization_id UUID NOT NULL,
 		version INTEGER,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`
-
-	queryCreateTender  = `INSERT INTO tenders VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-	queryListTenders   = `SELECT * FROM tenders WHERE service_type = ANY($1) AND status = 'Published' ORDER BY name `
-	queryListMyTenders = `SELECT * FROM tenders WHERE organization_id = ANY($1) ORDER BY name `
-
-	orderByName             = ` ORDER BY name`
-	queryReadTender         = `SELECT * FROM tenders WHERE id = $1`
+	);
+	ALTER TABLE tenders
+		ADD COLUMN IF NOT EXISTS sealed BOOLEAN NOT NULL DEFAULT FALSE,
+		ADD COLUMN IF NOT EXISTS bid_deadline TIMESTAMP,
+		ADD COLUMN IF NOT EXISTS invite_only BOOLEAN NOT NULL DEFAULT FALSE,
+		ADD COLUMN IF NOT EXISTS budget_amount BIGINT,
+		ADD COLUMN IF NOT EXISTS budget_currency CHAR(3),
+		ADD COLUMN IF NOT EXISTS budget_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+		ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+	CREATE INDEX IF NOT EXISTS tenders_deleted_at_idx ON tenders (deleted_at) WHERE deleted_at IS NOT NULL;
+	-- The archive takes the columns tenders have when it is first created, later columns go to both.
+	CREATE TABLE IF NOT EXISTS tenders_archive (LIKE tenders INCLUDING DEFAULTS);
+	CREATE UNIQUE INDEX IF NOT EXISTS tenders_archive_id_idx ON tenders_archive (id);
+	CREATE INDEX IF NOT EXISTS tenders_archive_organization_id_idx ON tenders_archive (organization_id)`
+
+	queryCreateTender = `INSERT INTO tenders (` + tenderColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	// Invitation-only tenders are listed for the responsible users of their organization and for
+	// invitees. The listings read from tenderSource.
+	queryListTenders = `SELECT ` + tenderColumns + ` FROM %s
+		WHERE deleted_at IS NULL AND service_type = ANY($1) AND status = 'Published'
+			AND (NOT invite_only OR organization_id = ANY($3) OR id IN (` + queryInvitedTenders + `))`
+	queryListMyTenders = `SELECT ` + tenderColumns + ` FROM %s WHERE deleted_at IS NULL AND organization_id = ANY($1)`
+
+	// budgetVisible holds for the tenders whose budget the viewer in $3 may see.
+	budgetVisible = `(NOT budget_hidden OR organization_id = ANY($3))`
+
+	orderByName             = ` ORDER BY name `
+	queryReadTender         = `SELECT ` + tenderColumns + ` FROM tenders WHERE id = $1 AND deleted_at IS NULL`
+	queryLockTender         = queryReadTender + ` FOR UPDATE`
+	queryReadDeletedTender  = `SELECT ` + tenderColumns + ` FROM tenders WHERE id = $1 AND deleted_at IS NOT NULL`
+	queryDeleteTender       = `UPDATE tenders SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	queryRestoreTender      = `UPDATE tenders SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
 	queryUpdateTenderStatus = `UPDATE tenders SET status = $1 WHERE id = $2`
+	queryBumpTenderVersion  = `UPDATE tenders SET version = COALESCE(version, 0) + 1 WHERE id = $1 RETURNING version`
+
+	// Bids and the rest of what refers to tenders by tender_id go in the same
+	// statement as their tender, scores with the criteria they reference.
+	queryPurgeTenders = `WITH purged AS (
+			DELETE FROM tenders WHERE deleted_at < $1 RETURNING id
+		), purged_bids AS (
+			DELETE FROM bid WHERE tender_id IN (SELECT id FROM purged)
+		), purged_lots AS (
+			DELETE FROM tender_lots WHERE tender_id IN (SELECT id FROM purged)
+		), purged_invitations AS (
+			DELETE FROM tender_invitations WHERE tender_id IN (SELECT id FROM purged)
+		), purged_questions AS (
+			DELETE FROM tender_questions WHERE tender_id IN (SELECT id FROM purged)
+		), purged_criteria AS (
+			DELETE FROM tender_criteria WHERE tender_id IN (SELECT id FROM purged)
+		), purged_offers AS (
+			DELETE FROM auction_offers WHERE tender_id IN (SELECT id FROM purged)
+		), purged_auctions AS (
+			DELETE FROM tender_auctions WHERE tender_id IN (SELECT id FROM purged)
+		), purged_attachments AS (
+			DELETE FROM tender_attachments WHERE tender_id IN (SELECT id FROM purged)
+			RETURNING id, tender_id, bid_id
+		)
+		SELECT (SELECT count(*) FROM purged), ` + purgedAttachments + ` FROM purged_attachments`
+	// purgedAttachments returns what BlobKey needs of the rows of purged_attachments as a JSON array.
+	purgedAttachments = `COALESCE(json_agg(json_build_object('id', id, 'tenderId', tender_id, 'bidId', bid_id)), '[]')`
+
+	queryArchiveTenders = `WITH archived AS (
+			DELETE FROM tenders WHERE id IN (
+				SELECT id FROM tenders
+				WHERE status = 'Closed' AND deleted_at IS NULL AND created_at < $1
+				ORDER BY created_at
+				LIMIT $2
+			)
+			RETURNING ` + tenderColumns + `, deleted_at
+		), archived_tenders AS (
+			INSERT INTO tenders_archive (` + tenderColumns + `, deleted_at)
+			SELECT ` + tenderColumns + `, deleted_at FROM archived
+		), archived_bids AS (
+			DELETE FROM bid WHERE tender_id IN (SELECT id FROM archived)
+			RETURNING ` + bidColumns + `, deleted_at
+		), moved_bids AS (
+			INSERT INTO bid_archive (` + bidColumns + `, deleted_at)
+			SELECT ` + bidColumns + `, deleted_at FROM archived_bids
+		)
+		SELECT id FROM archived`
 )
 
+var queryStreamMyTenders = fmt.Sprintf(queryListMyTenders, "tenders") + orderByName
+
 var tenderTables map[string]string = map[string]string{
 	"tender": queryInitTender,
 }
 
-func (r *TenderRepo) Create(ctx context.Context, tender *entity.Tender) error {
-	_, err := r.db.ExecContext(
+func (r *TenderRepo) Create(ctx context.Context, tender *entity.Tender) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.create", queryCreateTender)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(
 		ctx,
 		queryCreateTender,
-		&tender.ID,
-		&tender.Name,
-		&tender.Description,
-		&tender.ServiceType,
-		&tender.Status,
-		&tender.OrganizationID,
-		&tender.Version,
-		&tender.CreatedAt,
+		uuidArg(tender.ID),
+		tender.Name,
+		tender.Description,
+		tender.ServiceType,
+		tender.Status,
+		uuidArg(tender.OrganizationID),
+		tender.Version,
+		tender.CreatedAt,
+		tender.Sealed,
+		tender.BidDeadline,
+		tender.InviteOnly,
+		budgetAmountArg(tender.Budget),
+		budgetCurrencyArg(tender.Budget),
+		tender.BudgetHidden,
 	)
+	affected = tag.RowsAffected()
 
-	return err
+	return constraintError(err)
 }
 
-func (r *TenderRepo) Read(ctx context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
-	var tender entity.Tender
+func (r *TenderRepo) Read(ctx context.Context, tenderID entity.TenderID) (_ *entity.Tender, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.read", queryReadTender)
 
-	err := r.db.QueryRowContext(ctx, queryReadTender, tenderID).Scan(
-		&tender.ID,
-		&tender.Name,
-		&tender.Description,
-		&tender.ServiceType,
-		&tender.Status,
-		&tender.OrganizationID,
-		&tender.Version,
-		&tender.CreatedAt,
-	)
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	tender, err := scanTender(conn(ctx, r.db).QueryRow(ctx, queryReadTender, uuidArg(tenderID)))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			r.logger.Error("no tender found", zap.String("id", string(tenderID)))
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.log(ctx).Error("no tender found", zap.String("id", string(tenderID)))
 			return nil, nil
 		}
 
 		return nil, err
 	}
 
-	return &tender, nil
+	found = 1
+
+	return tender, nil
 }
 
-func (r *TenderRepo) List(ctx context.Context, tenderTypes []entity.TenderServiceType, limitOffset *entity.RequestLimitOffset) ([]*entity.Tender, error) {
-	var tenders []*entity.Tender
+func (r *TenderRepo) Lock(ctx context.Context, tenderID entity.TenderID) (_ *entity.Tender, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.lock", queryLockTender)
 
-	query, args := buildLimitOffset(
-		queryListTenders,
-		[]any{
-			pq.Array(tenderTypes),
-		},
-		limitOffset,
-	)
+	var found int64
+	defer func() { span.end(found, err) }()
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	tender, err := scanTender(conn(ctx, r.db).QueryRow(ctx, queryLockTender, uuidArg(tenderID)))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 
 		return nil, err
 	}
 
-	for rows.Next() {
-		var tender entity.Tender
-
-		if err := rows.Scan(
-			&tender.ID,
-			&tender.Name,
-			&tender.Description,
-			&tender.ServiceType,
-			&tender.Status,
-			&tender.OrganizationID,
-			&tender.Version,
-			&tender.CreatedAt,
-		); err != nil {
-			return nil, err
+	found = 1
+
+	return tender, nil
+}
+
+func (r *TenderRepo) ReadDeleted(ctx context.Context, tenderID entity.TenderID) (_ *entity.Tender, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.read_deleted", queryReadDeletedTender)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
+	tender, err := scanTender(conn(ctx, r.db).QueryRow(ctx, queryReadDeletedTender, uuidArg(tenderID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
 		}
-		tenders = append(tenders, &tender)
+
+		return nil, err
 	}
 
-	return tenders, nil
+	found = 1
+
+	return tender, nil
 }
 
-func (r *TenderRepo) ListMy(ctx context.Context, organizations []entity.OrganizationID, limitOffset *entity.RequestLimitOffset) ([]*entity.Tender, error) {
+func (r *TenderRepo) List(
+	ctx context.Context,
+	tenderTypes []entity.TenderServiceType,
+	viewer *entity.Viewer,
+	options *entity.TenderListOptions,
+	limitOffset *entity.RequestLimitOffset,
+) (_ []*entity.Tender, err error) {
+	var tenders []*entity.Tender
+
+	userID, organizations := viewerArgs(viewer)
+
 	query, args := buildLimitOffset(
-		queryListMyTenders,
-		[]any{
-			pq.Array(organizations),
-		},
+		fmt.Sprintf(queryListTenders, tenderSource(options))+budgetFilter(budgetVisible, 4)+tenderOrder(options, budgetVisible),
+		append(
+			[]any{
+				tenderTypes,
+				userID,
+				organizations,
+			},
+			budgetFilterArgs(options)...,
+		),
 		limitOffset,
 	)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
+	ctx, span := startStatement(ctx, r.timeout, "tender.list", query)
+	defer func() { span.end(int64(len(tenders)), err) }()
 
+	tenders, err = collectTenders(conn(ctx, r.db).Query(ctx, query, args...))
+	if err != nil {
 		return nil, err
 	}
 
+	return tenders, nil
+}
+
+func (r *TenderRepo) ListMy(
+	ctx context.Context,
+	organizations []entity.OrganizationID,
+	options *entity.TenderListOptions,
+	limitOffset *entity.RequestLimitOffset,
+) (_ []*entity.Tender, err error) {
 	var tenders []*entity.Tender
 
+	// Organizations see their own budgets.
+	query, args := buildLimitOffset(
+		fmt.Sprintf(queryListMyTenders, tenderSource(options))+budgetFilter("TRUE", 2)+tenderOrder(options, "TRUE"),
+		append(
+			[]any{
+				uuidArgs(organizations),
+			},
+			budgetFilterArgs(options)...,
+		),
+		limitOffset,
+	)
+
+	ctx, span := startStatement(ctx, r.timeout, "tender.list_my", query)
+	defer func() { span.end(int64(len(tenders)), err) }()
+
+	tenders, err = collectTenders(conn(ctx, r.db).Query(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	return tenders, nil
+}
+
+func (r *TenderRepo) StreamMy(ctx context.Context, organizations []entity.OrganizationID, fn func(*entity.Tender) error) (err error) {
+	// Rows are handed out while the client is reading them, so the statement gets the longer stream timeout.
+	ctx, span := startStatement(ctx, r.streamTimeout, "tender.stream_my", queryStreamMyTenders)
+
+	var streamed int64
+	defer func() { span.end(streamed, err) }()
+
+	rows, err := conn(ctx, r.db).Query(ctx, queryStreamMyTenders, uuidArgs(organizations))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
 	for rows.Next() {
-		var tender entity.Tender
-
-		if err := rows.Scan(
-			&tender.ID,
-			&tender.Name,
-			&tender.Description,
-			&tender.ServiceType,
-			&tender.Status,
-			&tender.OrganizationID,
-			&tender.Version,
-			&tender.CreatedAt,
-		); err != nil {
-			return nil, err
+		tender, err := scanTender(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(tender); err != nil {
+			return err
 		}
-		tenders = append(tenders, &tender)
+
+		streamed++
 	}
 
-	return tenders, nil
+	return rows.Err()
 }
 
-func (r *TenderRepo) UpdateStatus(ctx context.Context, tenderID entity.TenderID, tenderStatus entity.TenderStatus) error {
-	_, err := r.db.ExecContext(ctx, queryUpdateTenderStatus, tenderStatus, tenderID)
+func (r *TenderRepo) UpdateStatus(ctx context.Context, tenderID entity.TenderID, tenderStatus entity.TenderStatus) (err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.update_status", queryUpdateTenderStatus)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryUpdateTenderStatus, tenderStatus, uuidArg(tenderID))
+	affected = tag.RowsAffected()
+
+	return constraintError(err)
+}
+
+func (r *TenderRepo) BumpVersion(ctx context.Context, tenderID entity.TenderID) (_ entity.TenderVersion, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.bump_version", queryBumpTenderVersion)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	var version entity.TenderVersion
 
-	return err
+	if err := conn(ctx, r.db).QueryRow(ctx, queryBumpTenderVersion, uuidArg(tenderID)).Scan(&version); err != nil {
+		return 0, err
+	}
+
+	affected = 1
+
+	return version, nil
 }
 
-func (r *TenderRepo) Update(ctx context.Context, tenderID entity.TenderID, update *entity.TenderUpdate) error {
+func (r *TenderRepo) Update(ctx context.Context, tenderID entity.TenderID, update *entity.TenderUpdate) (err error) {
 	query := sqlbuilder.Update("tenders")
 
 	if update == nil {
@@ -194,25 +357,119 @@ func (r *TenderRepo) Update(ctx context.Context, tenderID entity.TenderID, updat
 		assign = append(assign, query.Assign("service_type", update.ServiceType))
 	}
 
-	query.Set(assign...).Where(query.Equal("id", tenderID))
+	if update.Sealed != nil {
+		assign = append(assign, query.Assign("sealed", *update.Sealed))
+	}
+
+	if update.BidDeadline != nil {
+		assign = append(assign, query.Assign("bid_deadline", *update.BidDeadline))
+	}
+
+	if update.InviteOnly != nil {
+		assign = append(assign, query.Assign("invite_only", *update.InviteOnly))
+	}
+
+	if update.Budget != nil {
+		assign = append(assign,
+			query.Assign("budget_amount", update.Budget.Amount),
+			query.Assign("budget_currency", update.Budget.Currency),
+		)
+	}
+
+	if update.BudgetHidden != nil {
+		assign = append(assign, query.Assign("budget_hidden", *update.BudgetHidden))
+	}
+
+	if len(assign) == 0 {
+		return nil
+	}
+
+	query.Set(assign...).Where(query.Equal("id", uuidArg(tenderID)))
 
 	query.SetFlavor(sqlbuilder.PostgreSQL)
 
 	queryString, args := query.Build()
-	stmt, err := r.db.PrepareContext(ctx, queryString)
+
+	ctx, span := startStatement(ctx, r.timeout, "tender.update", queryString)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	// The statement cache of the connection prepares the statement once per distinct set of columns.
+	tag, err := conn(ctx, r.db).Exec(ctx, queryString, args...)
+	affected = tag.RowsAffected()
+
+	return constraintError(err)
+}
+
+func (r *TenderRepo) Delete(ctx context.Context, tenderID entity.TenderID, deletedAt time.Time) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.delete", queryDeleteTender)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryDeleteTender, uuidArg(tenderID), deletedAt)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	affected = tag.RowsAffected()
+
+	return affected > 0, nil
+}
+
+func (r *TenderRepo) Restore(ctx context.Context, tenderID entity.TenderID) (_ bool, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.restore", queryRestoreTender)
+
+	var affected int64
+	defer func() { span.end(affected, err) }()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, queryRestoreTender, uuidArg(tenderID))
+	if err != nil {
+		return false, err
+	}
+
+	affected = tag.RowsAffected()
+
+	return affected > 0, nil
+}
+
+func (r *TenderRepo) Purge(ctx context.Context, deletedBefore time.Time) (_ int64, _ []*entity.Attachment, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.purge", queryPurgeTenders)
+
+	var purged int64
+	defer func() { span.end(purged, err) }()
+
+	var attachments []*entity.Attachment
+	if err := conn(ctx, r.db).QueryRow(ctx, queryPurgeTenders, deletedBefore).Scan(&purged, &attachments); err != nil {
+		return 0, nil, err
 	}
 
-	_, err = stmt.ExecContext(ctx, args...)
+	return purged, attachments, nil
+}
+
+func (r *TenderRepo) Archive(ctx context.Context, createdBefore time.Time, limit int) (_ []entity.TenderID, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "tender.archive", queryArchiveTenders)
+
+	var archived []entity.TenderID
+	defer func() { span.end(int64(len(archived)), err) }()
+
+	archived, err = collectIDs[entity.TenderID](conn(ctx, r.db).Query(ctx, queryArchiveTenders, createdBefore, limit))
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	return archived, nil
 }
 
 func (r *PostgresRepo) NewTenderRepo(ctx context.Context) (*TenderRepo, error) {
+	r.requireRelations("tenders")
+
 	tr := &TenderRepo{
-		db:     r.db,
-		logger: r.logger.Named("tender"),
+		db:            r.db,
+		timeout:       r.cfg.QueryTimeout.Std(),
+		streamTimeout: r.cfg.StreamTimeout.Std(),
+		logger:        r.logger.Named("tender"),
 	}
 
 	if err := r.InitTables(ctx, tenderTables); err != nil {
@@ -222,28 +479,99 @@ func (r *PostgresRepo) NewTenderRepo(ctx context.Context) (*TenderRepo, error) {
 	return tr, nil
 }
 
+// tenderSource is the relation tender listings read from: the live tenders,
+// joined by the archived ones when the options ask for them.
+func tenderSource(options *entity.TenderListOptions) string {
+	if options == nil || !options.IncludeArchived {
+		return "tenders"
+	}
+
+	return `(SELECT ` + tenderColumns + `, deleted_at FROM tenders
+		UNION ALL SELECT ` + tenderColumns + `, deleted_at FROM tenders_archive) AS tenders`
+}
+
+// budgetFilter narrows a listing by the budget currency and bounds passed as
+// $first to $first+2, a NULL argument leaves its condition out. Budgets for
+// which visible does not hold match no bound.
+func budgetFilter(visible string, first int) string {
+	return fmt.Sprintf(`
+			AND ($%[1]d::text IS NULL OR (budget_currency = $%[1]d AND %[4]s))
+			AND ($%[2]d::bigint IS NULL OR (budget_amount >= $%[2]d AND %[4]s))
+			AND ($%[3]d::bigint IS NULL OR (budget_amount <= $%[3]d AND %[4]s))`,
+		first, first+1, first+2, visible)
+}
+
+func budgetFilterArgs(options *entity.TenderListOptions) []any {
+	if options == nil {
+		return []any{nil, nil, nil}
+	}
+
+	var currency *entity.Currency
+	if options.Currency != "" {
+		currency = &options.Currency
+	}
+
+	return []any{currency, options.BudgetMin, options.BudgetMax}
+}
+
+// tenderOrder sorts by name, or by the visible budget within its currency.
+func tenderOrder(options *entity.TenderListOptions, visible string) string {
+	if options == nil {
+		return orderByName
+	}
+
+	var direction string
+
+	switch options.Sort {
+	case entity.TenderSortBudgetAsc:
+		direction = "ASC"
+	case entity.TenderSortBudgetDesc:
+		direction = "DESC"
+	default:
+		return orderByName
+	}
+
+	return fmt.Sprintf(` ORDER BY CASE WHEN %[1]s THEN budget_currency END, CASE WHEN %[1]s THEN budget_amount END %[2]s NULLS LAST, name `,
+		visible, direction)
+}
+
+func budgetAmountArg(budget *entity.Money) *int64 {
+	if budget == nil {
+		return nil
+	}
+
+	return &budget.Amount
+}
+
+func budgetCurrencyArg(budget *entity.Money) *entity.Currency {
+	if budget == nil {
+		return nil
+	}
+
+	return &budget.Currency
+}
+
 func buildLimitOffset(query string, args []any, limitOffset *entity.RequestLimitOffset) (string, []any) {
 	var s strings.Builder
 	s.WriteString(query)
 
-	var offsetSet bool
-
+	// The placeholders follow the arguments of the query.
 	if limitOffset.Offset > 0 {
 		args = append(args, limitOffset.Offset)
-		s.WriteString("OFFSET $2")
+		s.WriteString("OFFSET $")
+		s.WriteString(strconv.Itoa(len(args)))
 		s.WriteString(" ")
-		offsetSet = true
 	}
 
 	if limitOffset.Limit > 0 {
 		args = append(args, limitOffset.Limit)
 		s.WriteString("LIMIT $")
-		if offsetSet {
-			s.WriteString("3")
-		} else {
-			s.WriteString("2")
-		}
+		s.WriteString(strconv.Itoa(len(args)))
 	}
 
 	return s.String(), args
 }
+
+func (r *TenderRepo) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
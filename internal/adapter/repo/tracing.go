@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"avito2024/internal/tracing"
+)
+
+var tracer = otel.Tracer("avito2024/internal/adapter/repo")
+
+type statementSpan struct {
+	span   trace.Span
+	cancel context.CancelFunc
+}
+
+// startStatement starts a client span for a single SQL statement and bounds
+// the statement with timeout. Rows of a query must be read before end is called.
+func startStatement(ctx context.Context, timeout time.Duration, name, query string) (context.Context, *statementSpan) {
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", name),
+			attribute.String("db.query.text", query),
+		),
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	return ctx, &statementSpan{span: span, cancel: cancel}
+}
+
+// end releases the statement deadline, records the number of rows returned
+// or affected by the statement and ends the span.
+func (r *statementSpan) end(rows int64, err error) {
+	r.cancel()
+	r.span.SetAttributes(attribute.Int64("db.response.returned_rows", rows))
+	tracing.End(r.span, err)
+}
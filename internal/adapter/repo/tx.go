@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"avito2024/internal/tracing"
+)
+
+// querier is implemented by both the pool and a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// txState is a transaction, or a savepoint in one, and what to run once it has committed.
+type txState struct {
+	tx          pgx.Tx
+	afterCommit []func(ctx context.Context)
+}
+
+// conn returns the transaction of ctx started by WithinTransaction, or pool outside of one.
+func conn(ctx context.Context, pool *pgxpool.Pool) querier {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+
+	return pool
+}
+
+// WithinTransaction implements port.Transactor. A call nested in another
+// transaction runs in a savepoint, so its failure leaves the outer one usable.
+func (r *PostgresRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	begin := r.db.Begin
+	parent, nested := ctx.Value(txKey{}).(*txState)
+	if nested {
+		begin = parent.tx.Begin
+	}
+
+	ctx, span := tracer.Start(ctx, "transaction")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			// The statement that failed may have closed the connection, the rollback error adds nothing then.
+			if rollbackErr := tx.Rollback(context.WithoutCancel(ctx)); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+				err = errors.Join(err, rollbackErr)
+			}
+		}
+	}()
+
+	state := &txState{tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	// Releasing a savepoint commits nothing yet, its hooks wait for the outer transaction.
+	if nested {
+		parent.afterCommit = append(parent.afterCommit, state.afterCommit...)
+		return nil
+	}
+
+	for _, hook := range state.afterCommit {
+		hook(ctx)
+	}
+
+	return nil
+}
+
+// AfterCommit implements port.Transactor.
+func (r *PostgresRepo) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+
+	fn(ctx)
+}
+
+// InTransaction implements port.Transactor.
+func (r *PostgresRepo) InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
@@ -2,11 +2,13 @@ package repo
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"time"
 
 	"avito2024/internal/app/core/entity"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
@@ -29,39 +31,59 @@ var userTables = map[string]string{
 }
 
 type UserRepo struct {
-	db     *sql.DB
-	logger *zap.Logger
+	db      *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
 }
 
-func (r *UserRepo) FindUserId(ctx context.Context, userName string) (entity.UserID, error) {
+func (r *UserRepo) FindUserId(ctx context.Context, userName string) (_ entity.UserID, err error) {
+	ctx, span := startStatement(ctx, r.timeout, "user.find_id", queryUserIDByUsername)
+
+	var found int64
+	defer func() { span.end(found, err) }()
+
 	var userID entity.UserID
 
-	if err := r.db.QueryRowContext(ctx, queryUserIDByUsername, userName).Scan(&userID); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+	if err := conn(ctx, r.db).QueryRow(ctx, queryUserIDByUsername, userName).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return "", nil
 		}
 
 		return "", err
 	}
 
+	found = 1
+
 	return userID, nil
 }
 
 func (r *UserRepo) Exists(ctx context.Context, userID entity.UserID) bool {
+	ctx, span := startStatement(ctx, r.timeout, "user.exists", queryUserIDByID)
+
 	var id string
 
-	err := r.db.QueryRowContext(ctx, queryUserIDByID, userID).Scan(&id)
+	err := conn(ctx, r.db).QueryRow(ctx, queryUserIDByID, uuidArg(userID)).Scan(&id)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+		}
+
+		span.end(0, err)
 		return false
 	}
 
-	return id == string(userID)
+	span.end(1, nil)
+
+	return true
 }
 
 func (r *PostgresRepo) NewUserRepo(ctx context.Context, isTest bool) (*UserRepo, error) {
+	r.requireRelations("employee")
+
 	ur := &UserRepo{
-		db:     r.db,
-		logger: r.logger.Named("tender"),
+		db:      r.db,
+		timeout: r.cfg.QueryTimeout.Std(),
+		logger:  r.logger.Named("user"),
 	}
 
 	if isTest {
@@ -2,27 +2,49 @@ package core
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"go.uber.org/zap"
 
+	"avito2024/internal/adapter/blob"
+	"avito2024/internal/adapter/cache"
+	"avito2024/internal/adapter/event"
+	"avito2024/internal/adapter/metrics"
+	"avito2024/internal/adapter/notify"
 	"avito2024/internal/adapter/repo"
+	"avito2024/internal/app/core/port"
 	"avito2024/internal/app/core/service"
 	"avito2024/internal/config"
 	v1 "avito2024/internal/controller/api/v1"
+	applog "avito2024/internal/logger"
+	"avito2024/internal/ratelimit"
+	"avito2024/internal/tracing"
 )
 
 func Run(cfg *config.Config) {
-	logger, err := zap.NewProduction()
+	logger, err := applog.New(cfg.Log.Level, cfg.Log.Format)
 	if err != nil {
 		panic(err)
 	}
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	postgresRepo, err := repo.NewPostgresRepo(ctx, cfg.ConnectionString, logger)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Config())
 	if err != nil {
 		panic(err)
 	}
+	defer shutdownTracing(context.Background())
+
+	postgresRepo, err := repo.NewPostgresRepo(ctx, &cfg.Postgres, logger)
+	if err != nil {
+		panic(err)
+	}
+	defer postgresRepo.Close()
 
 	tenderRepo, err := postgresRepo.NewTenderRepo(ctx)
 	if err != nil {
@@ -44,8 +66,290 @@ func Run(cfg *config.Config) {
 		panic(err)
 	}
 
-	tenderService := service.NewTenderService(tenderRepo, userRepo, orgRepo)
-	bidService := service.NewBidService(bidRepo, userRepo, orgRepo, tenderRepo)
-	v1.NewAPI(tenderService, bidService, logger).Run(cfg.Host)
+	idempotencyRepo, err := postgresRepo.NewIdempotencyRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	attachmentRepo, err := postgresRepo.NewAttachmentRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	evaluationRepo, err := postgresRepo.NewEvaluationRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	lotRepo, err := postgresRepo.NewLotRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	invitationRepo, err := postgresRepo.NewInvitationRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	serviceTypeRepo, err := postgresRepo.NewServiceTypeRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	auctionRepo, err := postgresRepo.NewAuctionRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	questionRepo, err := postgresRepo.NewQuestionRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	notificationRepo, err := postgresRepo.NewNotificationRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
 
+	auditRepo, err := postgresRepo.NewAuditRepo(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	// Migrations may change any table, so they run once every repo has created its own.
+	if err := postgresRepo.Migrate(ctx); err != nil {
+		panic(err)
+	}
+
+	blobStore, err := blob.NewLocal(cfg.Attachments.Dir)
+	if err != nil {
+		panic(err)
+	}
+
+	go postgresRepo.MonitorPool(ctx)
+
+	var (
+		userPort        port.UserRepo         = userRepo
+		orgPort         port.OrganizationRepo = orgRepo
+		tenderPort      port.TenderRepo       = tenderRepo
+		invitationPort  port.InvitationRepo   = invitationRepo
+		serviceTypePort port.ServiceTypeRepo  = serviceTypeRepo
+	)
+
+	if cfg.Cache.Enabled {
+		backend := cache.NewLRU(cfg.Cache.Size)
+
+		userPort = cache.NewUserRepo(userRepo, backend, cfg.Cache.UserTTL.Std(), logger)
+		orgPort = cache.NewOrganizationRepo(orgRepo, backend, cfg.Cache.OrganizationTTL.Std(), logger)
+		tenderCache := cache.NewTenderRepo(tenderRepo, postgresRepo, backend, cfg.Cache.TenderTTL.Std(), cfg.Cache.TenderListTTL.Std(), logger)
+		tenderPort = tenderCache
+		invitationPort = cache.NewInvitationRepo(invitationRepo, tenderCache)
+		serviceTypePort = cache.NewServiceTypeRepo(serviceTypeRepo, postgresRepo, backend, cfg.Cache.ServiceTypeTTL.Std(), logger)
+	}
+
+	prometheus := metrics.NewPrometheus()
+	prometheus.RegisterPool(postgresRepo.Pool(), "postgres")
+
+	var httpMetrics v1.HTTPMetrics
+	if cfg.Features.Metrics {
+		httpMetrics = prometheus
+	}
+
+	workerMonitor := service.NewWorkerMonitor()
+	eventBus := event.NewBus(logger)
+
+	auditService := service.NewAuditService(auditRepo, userPort, orgPort, cfg.Admin.Usernames, logger)
+	catalogService := service.NewCatalogService(serviceTypePort, userPort, cfg.Admin.Usernames, logger)
+	tenderService := service.NewTenderService(
+		tenderPort,
+		lotRepo,
+		invitationPort,
+		auctionRepo,
+		catalogService,
+		auditService,
+		userPort,
+		orgPort,
+		postgresRepo,
+		prometheus,
+		eventBus,
+		logger,
+	)
+	bidService := service.NewBidService(
+		bidRepo,
+		userPort,
+		orgPort,
+		tenderPort,
+		tenderService,
+		auditService,
+		postgresRepo,
+		prometheus,
+		eventBus,
+		logger,
+	)
+	healthService := service.NewHealthService(append(postgresRepo.HealthCheckers(), workerMonitor)...)
+	idempotencyService := service.NewIdempotencyService(
+		idempotencyRepo,
+		cfg.Idempotency.Window.Std(),
+		cfg.Idempotency.LockTimeout.Std(),
+		int64(cfg.Idempotency.MaxBodyMB)<<20,
+		logger,
+	)
+
+	attachmentService := service.NewAttachmentService(
+		attachmentRepo,
+		blobStore,
+		tenderService,
+		tenderPort,
+		bidRepo,
+		userPort,
+		orgPort,
+		postgresRepo,
+		service.AttachmentLimits{
+			MaxSize:      int64(cfg.Attachments.MaxSizeMB) << 20,
+			AllowedTypes: cfg.Attachments.AllowedTypes,
+		},
+		logger,
+	)
+
+	evaluationService := service.NewEvaluationService(
+		evaluationRepo,
+		bidService,
+		bidRepo,
+		tenderPort,
+		userPort,
+		postgresRepo,
+		logger,
+	)
+
+	auctionService := service.NewAuctionService(
+		auctionRepo,
+		tenderService,
+		bidService,
+		tenderPort,
+		bidRepo,
+		userPort,
+		orgPort,
+		postgresRepo,
+		logger,
+	)
+
+	questionService := service.NewQuestionService(
+		questionRepo,
+		tenderService,
+		tenderPort,
+		userPort,
+		orgPort,
+		eventBus,
+		logger,
+	)
+
+	notifiers := []port.Notifier{notify.NewInbox(notificationRepo)}
+
+	if cfg.Notify.SMTP.Enabled {
+		mailer, err := notify.NewSMTP(notify.SMTPConfig{
+			Addr:     cfg.Notify.SMTP.Addr,
+			Username: cfg.Notify.SMTP.Username,
+			Password: cfg.Notify.SMTP.Password.Value(),
+			From:     cfg.Notify.SMTP.From,
+			Timeout:  cfg.Notify.SMTP.Timeout.Std(),
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		notifiers = append(notifiers, mailer)
+	}
+
+	notificationService := service.NewNotificationService(
+		notificationRepo,
+		notifiers,
+		tenderPort,
+		bidRepo,
+		questionRepo,
+		userPort,
+		orgPort,
+		cfg.Notify.QueueSize,
+		logger,
+	)
+	eventBus.Subscribe(notificationService.Handle)
+
+	// Events are only seen by the replica they happen on, so every replica delivers its own.
+	go notificationService.Run(ctx)
+
+	if cfg.Workers.Enabled {
+		go idempotencyService.RunCleanup(ctx, workerMonitor, cfg.Idempotency.CleanupInterval.Std())
+		go auctionService.RunCloser(ctx, workerMonitor, cfg.Auctions.CloseInterval.Std())
+
+		retentionService := service.NewRetentionService(
+			tenderPort,
+			bidRepo,
+			blobStore,
+			cfg.Retention.DeletedFor.Std(),
+			cfg.Retention.ArchiveAfterMonths,
+			cfg.Retention.BatchSize,
+			logger,
+		)
+		go retentionService.Run(ctx, workerMonitor, cfg.Retention.Interval.Std())
+	}
+
+	var rateLimits *v1.RateLimits
+	if cfg.RateLimit.Enabled {
+		rateLimits = &v1.RateLimits{
+			Store:   ratelimit.NewMemory(),
+			Tenders: cfg.RateLimit.Tenders(),
+			Bids:    cfg.RateLimit.Bids(),
+		}
+	}
+
+	handler, err := v1.NewAPI(
+		&v1.Services{
+			Tender:       tenderService,
+			Bid:          bidService,
+			Health:       healthService,
+			Idempotency:  idempotencyService,
+			Attachment:   attachmentService,
+			Evaluation:   evaluationService,
+			Auction:      auctionService,
+			Catalog:      catalogService,
+			Question:     questionService,
+			Notification: notificationService,
+			Audit:        auditService,
+		},
+		httpMetrics,
+		rateLimits,
+		cfg.HTTP.TrustedProxies,
+		// Exports stream for as long as their statement may run, past http.writeTimeout.
+		cfg.Postgres.StreamTimeout.Std(),
+		cfg.Tracing.ServiceName,
+		logger,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	server := &http.Server{
+		Addr:              cfg.HTTP.Address,
+		Handler:           handler,
+		ReadTimeout:       cfg.HTTP.ReadTimeout.Std(),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout.Std(),
+		WriteTimeout:      cfg.HTTP.WriteTimeout.Std(),
+		IdleTimeout:       cfg.HTTP.IdleTimeout.Std(),
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout.Std())
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("graceful shutdown failed", zap.Error(err))
+		}
+	}()
+
+	logger.Info("server started", zap.String("address", cfg.HTTP.Address))
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		panic(err)
+	}
 }
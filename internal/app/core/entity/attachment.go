@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"io"
+	"time"
+)
+
+type AttachmentID string
+
+// AttachmentOwner is what an attachment belongs to: a bid when BidID is set,
+// the tender itself otherwise.
+type AttachmentOwner struct {
+	TenderID TenderID
+	BidID    BidId
+}
+
+// Attachment is a file of a tender or of a bid. Adding or removing one bumps
+// the version of its owner, so the attachments of any earlier version can
+// still be listed.
+type Attachment struct {
+	ID          AttachmentID `json:"id"`
+	TenderID    TenderID     `json:"tenderId"`
+	BidID       BidId        `json:"bidId,omitempty"`
+	Name        string       `json:"name"`
+	ContentType string       `json:"contentType"`
+	Size        int64        `json:"size"`
+	SHA256      string       `json:"sha256"`
+	// AddedInVersion is the owner version the attachment appeared in.
+	AddedInVersion int32 `json:"addedInVersion"`
+	// RemovedInVersion is set once the attachment is deleted.
+	RemovedInVersion *int32    `json:"removedInVersion,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+func (r *Attachment) Owner() AttachmentOwner {
+	return AttachmentOwner{TenderID: r.TenderID, BidID: r.BidID}
+}
+
+// BlobKey is where the content of the attachment is kept in the blob store.
+func (r *Attachment) BlobKey() string {
+	if r.BidID != "" {
+		return "bids/" + string(r.BidID) + "/" + string(r.ID)
+	}
+
+	return "tenders/" + string(r.TenderID) + "/" + string(r.ID)
+}
+
+type AttachmentUpload struct {
+	Name string
+	// SHA256 is the hex checksum announced by the client, empty to skip the check.
+	SHA256 string
+	Body   io.Reader
+}
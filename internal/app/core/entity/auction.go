@@ -0,0 +1,65 @@
+package entity
+
+import "time"
+
+type (
+	AuctionOfferID string
+	AuctionStatus  string
+)
+
+const (
+	AuctionStatusScheduled AuctionStatus = "Scheduled"
+	AuctionStatusRunning   AuctionStatus = "Running"
+	// AuctionStatusEnded is an auction past its end that is not closed yet.
+	AuctionStatusEnded  AuctionStatus = "Ended"
+	AuctionStatusClosed AuctionStatus = "Closed"
+)
+
+// Auction is the reverse auction of a tender: between StartsAt and EndsAt the
+// authors of its bids place ever lower prices, the lowest compliant one wins.
+type Auction struct {
+	TenderID   TenderID `json:"tenderId"`
+	StartPrice Money    `json:"startPrice"`
+	// MinDecrement is how much an offer has to undercut the best price by.
+	MinDecrement Money     `json:"minDecrement"`
+	StartsAt     time.Time `json:"startsAt"`
+	EndsAt       time.Time `json:"endsAt"`
+	// AntiSnipingSeconds moves EndsAt to that many seconds after an offer placed
+	// within the last AntiSnipingSeconds, so nobody wins by bidding at the last moment.
+	AntiSnipingSeconds int32  `json:"antiSnipingSeconds"`
+	BestPrice          *Money `json:"bestPrice,omitempty"`
+	// BestBidID is only shown to the tender organization.
+	BestBidID   BidId         `json:"bestBidId,omitempty"`
+	Offers      int32         `json:"offers"`
+	Status      AuctionStatus `json:"status"`
+	WinnerBidID BidId         `json:"winnerBidId,omitempty"`
+	ClosedAt    *time.Time    `json:"closedAt,omitempty"`
+}
+
+// StatusAt is the status of the auction at now.
+func (r *Auction) StatusAt(now time.Time) AuctionStatus {
+	switch {
+	case r.ClosedAt != nil:
+		return AuctionStatusClosed
+	case now.Before(r.StartsAt):
+		return AuctionStatusScheduled
+	case now.Before(r.EndsAt):
+		return AuctionStatusRunning
+	default:
+		return AuctionStatusEnded
+	}
+}
+
+// AuctionOffer is a price a bid author placed in the auction.
+type AuctionOffer struct {
+	ID       AuctionOfferID `json:"id"`
+	TenderID TenderID       `json:"tenderId"`
+	BidID    BidId          `json:"bidId"`
+	Amount   Money          `json:"amount"`
+	PlacedAt time.Time      `json:"placedAt"`
+}
+
+type AuctionOfferInput struct {
+	BidID  BidId `json:"bidId"`
+	Amount Money `json:"amount"`
+}
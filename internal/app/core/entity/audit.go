@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	AuditEntryID    string
+	AuditEntityType string
+	AuditAction     string
+)
+
+const (
+	AuditEntityTender     AuditEntityType = "tender"
+	AuditEntityBid        AuditEntityType = "bid"
+	AuditEntityLot        AuditEntityType = "lot"
+	AuditEntityInvitation AuditEntityType = "invitation"
+)
+
+const (
+	AuditActionCreate       AuditAction = "create"
+	AuditActionEdit         AuditAction = "edit"
+	AuditActionStatusChange AuditAction = "statusChange"
+	AuditActionDecision     AuditAction = "decision"
+	AuditActionRevoke       AuditAction = "revoke"
+	AuditActionRespond      AuditAction = "respond"
+	AuditActionDelete       AuditAction = "delete"
+	AuditActionRestore      AuditAction = "restore"
+)
+
+// AuditEntry is the durable record of a change, kept for as long as the
+// database and never updated.
+type AuditEntry struct {
+	ID         AuditEntryID    `json:"id"`
+	EntityType AuditEntityType `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Action     AuditAction     `json:"action"`
+	// ActorID is empty for changes made by the service itself, such as closing an ended auction.
+	ActorID UserID `json:"actorId,omitempty"`
+	// OrganizationID is the organization of the tender the change belongs to.
+	OrganizationID OrganizationID `json:"organizationId"`
+	// TenderID is set for bid entries, the tender the bid is for.
+	TenderID TenderID `json:"tenderId,omitempty"`
+	// Changes are the fields that differ before and after the change.
+	Changes   map[string]AuditChange `json:"changes"`
+	RequestID string                 `json:"requestId,omitempty"`
+	IP        string                 `json:"ip,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+type AuditFilter struct {
+	EntityType     AuditEntityType
+	EntityID       string
+	Action         AuditAction
+	ActorID        UserID
+	OrganizationID OrganizationID
+	From           *time.Time
+	To             *time.Time
+	// HideSealedBids leaves out the bid entries of tenders whose bids are
+	// still sealed, see Tender.BidsSealed, and of tenders no longer known.
+	HideSealedBids bool
+}
@@ -21,10 +21,69 @@ type Bid struct {
 	Description BidDescription `json:"description"`
 	Status      BidStatus      `json:"organization" bindig:"status"`
 	TenderID    TenderID       `json:"tenderId" `
+	LotID       LotID          `json:"lotId,omitempty"`
 	AuthorType  BidAuthorType  `json:"authorType"`
 	AuthorID    BidAuthorId    `json:"AuthorId"`
 	Version     BidVersion     `json:"version"`
-	CreatedAt   time.Time
+	Offer       *BidOffer      `json:"offer,omitempty"`
+	// Sealed is set when the contents of the bid are hidden, see Tender.BidsSealed.
+	Sealed bool `json:"sealed,omitempty"`
+	// Warnings point out what does not reject the bid but needs attention, such as a price over the budget.
+	Warnings  []string `json:"warnings,omitempty"`
+	CreatedAt time.Time
+}
+
+// Redacted is the bid as the tender organization sees it while bids are sealed:
+// it can be counted, but neither its author nor its contents are shown.
+func (r *Bid) Redacted() *Bid {
+	return &Bid{
+		ID:        r.ID,
+		Status:    r.Status,
+		TenderID:  r.TenderID,
+		LotID:     r.LotID,
+		Version:   r.Version,
+		Sealed:    true,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// BidOffer is the commercial part of a bid, what bids of a tender are compared by.
+type BidOffer struct {
+	Price Money `json:"price"`
+	// DeliveryDays is how long the delivery takes once the bid is approved.
+	DeliveryDays int32 `json:"deliveryDays"`
+	// ValidUntil is when the offer expires.
+	ValidUntil time.Time     `json:"validUntil"`
+	Items      []BidLineItem `json:"items,omitempty"`
+}
+
+type BidLineItem struct {
+	Name      string `json:"name"`
+	Quantity  int64  `json:"quantity"`
+	Unit      string `json:"unit,omitempty"`
+	UnitPrice Money  `json:"unitPrice"`
+}
+
+type BidUpdate struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Offer       *BidOffer `json:"offer"`
+}
+
+func (r *Bid) Apply(update *BidUpdate) *Bid {
+	if update.Name != "" {
+		r.Name = BidName(update.Name)
+	}
+
+	if update.Description != "" {
+		r.Description = BidDescription(update.Description)
+	}
+
+	if update.Offer != nil {
+		r.Offer = update.Offer
+	}
+
+	return r
 }
 
 type BidReview struct {
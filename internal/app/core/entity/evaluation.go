@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+type CriterionID string
+
+// MaxScore is the best score a reviewer can give a bid on a criterion.
+const MaxScore = 100
+
+// EvaluationCriterion is one of the weighted criteria bids of a tender are ranked by.
+type EvaluationCriterion struct {
+	ID       CriterionID `json:"id"`
+	TenderID TenderID    `json:"tenderId"`
+	Name     string      `json:"name"`
+	// Weight is the share of the criterion in the total, in percent.
+	Weight int32 `json:"weight"`
+}
+
+// BidScore is the score a reviewer gave a bid on a criterion, from 0 to MaxScore.
+type BidScore struct {
+	BidID       BidId       `json:"bidId"`
+	CriterionID CriterionID `json:"criterionId"`
+	ReviewerID  UserID      `json:"reviewerId"`
+	Score       int32       `json:"score"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+type BidScoreInput struct {
+	CriterionID CriterionID `json:"criterionId"`
+	Score       int32       `json:"score"`
+}
+
+// BidComparison is the bids of a tender ranked by their weighted total.
+type BidComparison struct {
+	TenderID TenderID               `json:"tenderId"`
+	Criteria []*EvaluationCriterion `json:"criteria"`
+	Rows     []*BidComparisonRow    `json:"rows"`
+}
+
+type BidComparisonRow struct {
+	// Rank is shared by bids with the same total.
+	Rank int  `json:"rank"`
+	Bid  *Bid `json:"bid"`
+	// Scores are the average reviewer scores by criterion, unscored criteria are left out.
+	Scores map[CriterionID]float64 `json:"scores"`
+	// Total is the weighted sum of the scores, from 0 to MaxScore.
+	Total float64 `json:"total"`
+	// Complete is false while some criterion has no score yet.
+	Complete bool `json:"complete"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+type EventType string
+
+const (
+	EventTenderEdited     EventType = "tender.edited"
+	EventTenderPublished  EventType = "tender.published"
+	EventTenderClosed     EventType = "tender.closed"
+	EventBidCreated       EventType = "bid.created"
+	EventBidApproved      EventType = "bid.approved"
+	EventBidRejected      EventType = "bid.rejected"
+	EventQuestionAsked    EventType = "question.asked"
+	EventQuestionAnswered EventType = "question.answered"
+)
+
+// EventTypes are the events users can be notified about.
+var EventTypes = []EventType{
+	EventTenderEdited,
+	EventTenderPublished,
+	EventTenderClosed,
+	EventBidCreated,
+	EventBidApproved,
+	EventBidRejected,
+	EventQuestionAsked,
+	EventQuestionAnswered,
+}
+
+// Event is a change in the domain that others may react to, for example by
+// notifying users. It names what changed, subscribers read the details they need.
+type Event struct {
+	Type       EventType  `json:"type"`
+	TenderID   TenderID   `json:"tenderId"`
+	BidID      BidId      `json:"bidId,omitempty"`
+	QuestionID QuestionID `json:"questionId,omitempty"`
+	// ActorID is the user who made the change.
+	ActorID    UserID    `json:"actorId"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
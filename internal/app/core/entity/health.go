@@ -0,0 +1,20 @@
+package entity
+
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "up"
+	HealthStatusDown HealthStatus = "down"
+)
+
+type HealthCheck struct {
+	Name      string       `json:"name"`
+	Status    HealthStatus `json:"status"`
+	LatencyMs float64      `json:"latencyMs"`
+	Error     string       `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status HealthStatus   `json:"status"`
+	Checks []*HealthCheck `json:"checks,omitempty"`
+}
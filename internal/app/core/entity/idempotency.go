@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// IdempotencyRecord remembers the first request made with an idempotency key
+// within Scope, usually the route and the caller, and the response it got.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	// Response is nil while the first request is still being served.
+	Response  *IdempotentResponse
+	ExpiresAt time.Time
+}
+
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+type (
+	InvitationID     string
+	InvitationStatus string
+)
+
+const (
+	InvitationStatusPending  InvitationStatus = "Pending"
+	InvitationStatusAccepted InvitationStatus = "Accepted"
+	InvitationStatusDeclined InvitationStatus = "Declined"
+)
+
+// Invitation lets an organization or a user see an invitation-only tender and
+// bid on it. The invitee is named the way bids name their author, a declined
+// invitation grants nothing.
+type Invitation struct {
+	ID          InvitationID     `json:"id"`
+	TenderID    TenderID         `json:"tenderId"`
+	InviteeType BidAuthorType    `json:"inviteeType"`
+	InviteeID   BidAuthorId      `json:"inviteeId"`
+	Status      InvitationStatus `json:"status"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	RespondedAt *time.Time       `json:"respondedAt,omitempty"`
+}
+
+// Viewer is who invitation-only tenders are matched against: a user and the
+// organizations they are responsible for.
+type Viewer struct {
+	UserID        UserID
+	Organizations []OrganizationID
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+type (
+	LotID     string
+	LotStatus string
+)
+
+const (
+	LotStatusOpen     LotStatus = "Open"
+	LotStatusAwarded  LotStatus = "Awarded"
+	LotStatusCanceled LotStatus = "Canceled"
+)
+
+// Lot is a part of a tender that gets its own bids and is awarded on its own.
+// Bids of a tender with lots name their lot, and the tender closes once none
+// of its lots is open.
+type Lot struct {
+	ID          LotID             `json:"id"`
+	TenderID    TenderID          `json:"tenderId"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	ServiceType TenderServiceType `json:"serviceType"`
+	Quantity    int64             `json:"quantity"`
+	Unit        string            `json:"unit,omitempty"`
+	Budget      Money             `json:"budget"`
+	Status      LotStatus         `json:"status"`
+	// AwardedBidID is the bid the lot was awarded to.
+	AwardedBidID BidId     `json:"awardedBidId,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultExponent is the number of decimals of amounts in a currency that is
+// not an ISO 4217 code, such amounts are rejected once the currency is checked.
+const defaultExponent = 2
+
+type Currency string
+
+// Valid reports whether r is an active ISO 4217 code.
+func (r Currency) Valid() bool {
+	_, ok := currencies[r]
+	return ok
+}
+
+// Exponent is the number of decimals of the minor unit of the currency: 2 for
+// RUB, 0 for JPY and 3 for KWD.
+func (r Currency) Exponent() int {
+	if exponent, ok := currencies[r]; ok {
+		return exponent
+	}
+
+	return defaultExponent
+}
+
+// Money is an amount in minor units of its currency, so sums never pick up
+// float rounding. It is written as {"amount": "1234.50", "currency": "RUB"} in JSON.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+type moneyJSON struct {
+	Amount   string   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+func (r Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: r.String(), Currency: r.Currency})
+}
+
+func (r *Money) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency Currency        `json:"currency"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// Numbers are accepted too, they are parsed from their text and not as float64.
+	amount, err := ParseAmount(strings.Trim(string(raw.Amount), `"`), raw.Currency)
+	if err != nil {
+		return err
+	}
+
+	*r = Money{Amount: amount, Currency: raw.Currency}
+
+	return nil
+}
+
+// String formats the amount as a decimal with as many digits after the point
+// as the currency has decimals.
+func (r Money) String() string {
+	sign := ""
+	amount := r.Amount
+
+	if amount < 0 {
+		sign = "-"
+		// Negating math.MinInt64 overflows, its digits are those of the unsigned value.
+		amount = -amount
+	}
+
+	digits := strconv.FormatUint(uint64(amount), 10)
+
+	exponent := r.Currency.Exponent()
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Mul multiplies the amount by quantity and reports false on overflow.
+func (r Money) Mul(quantity int64) (Money, bool) {
+	if quantity != 0 && (r.Amount > math.MaxInt64/quantity || r.Amount < math.MinInt64/quantity) {
+		return Money{}, false
+	}
+
+	return Money{Amount: r.Amount * quantity, Currency: r.Currency}, true
+}
+
+// ParseAmount parses a decimal such as "1234.5" into minor units of currency.
+// Decimals past those of the currency are only accepted as trailing zeros.
+func ParseAmount(value string, currency Currency) (int64, error) {
+	if value == "" {
+		return 0, errors.New("amount is empty")
+	}
+
+	exponent := currency.Exponent()
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) > exponent {
+		if strings.Trim(fraction[exponent:], "0") != "" {
+			return 0, fmt.Errorf("amount %q: at most %d decimals are allowed in %s", value, exponent, currency)
+		}
+
+		fraction = fraction[:exponent]
+	}
+
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q: %w", value, err)
+	}
+
+	return units, nil
+}
@@ -0,0 +1,152 @@
+package entity
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency Currency
+		want     int64
+		wantErr  bool
+	}{
+		{value: "1234.5", currency: "RUB", want: 123450},
+		{value: "1234.56", currency: "RUB", want: 123456},
+		{value: "1234", currency: "RUB", want: 123400},
+		{value: "0.01", currency: "RUB", want: 1},
+		{value: "-1.5", currency: "RUB", want: -150},
+		{value: "1234.567", currency: "RUB", wantErr: true},
+		{value: "1234.560", currency: "RUB", want: 123456},
+		{value: "1500", currency: "JPY", want: 1500},
+		{value: "1500.00", currency: "JPY", want: 1500},
+		{value: "1500.5", currency: "JPY", wantErr: true},
+		{value: "1.234", currency: "KWD", want: 1234},
+		{value: "1.2", currency: "KWD", want: 1200},
+		{value: "1.2345", currency: "KWD", wantErr: true},
+		{value: "1.23", currency: "XXX", want: 123},
+		{value: "", currency: "RUB", wantErr: true},
+		{value: "abc", currency: "RUB", wantErr: true},
+		{value: "1.2.3", currency: "RUB", wantErr: true},
+		{value: "1e3", currency: "RUB", wantErr: true},
+		{value: "92233720368547758.08", currency: "RUB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.currency)+" "+tt.value, func(t *testing.T) {
+			got, err := ParseAmount(tt.value, tt.currency)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseAmount(%q, %s) = %d, want an error", tt.value, tt.currency, got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseAmount(%q, %s): unexpected error: %v", tt.value, tt.currency, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseAmount(%q, %s) = %d, want %d", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: Money{Amount: 123450, Currency: "RUB"}, want: "1234.50"},
+		{money: Money{Amount: 5, Currency: "RUB"}, want: "0.05"},
+		{money: Money{Amount: 0, Currency: "RUB"}, want: "0.00"},
+		{money: Money{Amount: -150, Currency: "RUB"}, want: "-1.50"},
+		{money: Money{Amount: 1500, Currency: "JPY"}, want: "1500"},
+		{money: Money{Amount: -7, Currency: "JPY"}, want: "-7"},
+		{money: Money{Amount: 1234, Currency: "KWD"}, want: "1.234"},
+		{money: Money{Amount: 5, Currency: "KWD"}, want: "0.005"},
+		{money: Money{Amount: math.MinInt64, Currency: "RUB"}, want: "-92233720368547758.08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want+" "+string(tt.money.Currency), func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("%#v.String() = %q, want %q", tt.money, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    Money
+		wantErr bool
+	}{
+		{data: `{"amount": "1234.50", "currency": "RUB"}`, want: Money{Amount: 123450, Currency: "RUB"}},
+		{data: `{"amount": 1234.5, "currency": "RUB"}`, want: Money{Amount: 123450, Currency: "RUB"}},
+		{data: `{"currency": "JPY", "amount": "1500"}`, want: Money{Amount: 1500, Currency: "JPY"}},
+		{data: `{"amount": "1.234", "currency": "KWD"}`, want: Money{Amount: 1234, Currency: "KWD"}},
+		{data: `{"amount": "1.234", "currency": "RUB"}`, wantErr: true},
+		{data: `{"amount": "", "currency": "RUB"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			var got Money
+
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("unmarshal %s = %#v, want an error", tt.data, got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unmarshal %s: unexpected error: %v", tt.data, err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("unmarshal %s = %#v, want %#v", tt.data, got, tt.want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("marshal: unexpected error: %v", err)
+			}
+
+			var again Money
+			if err := json.Unmarshal(data, &again); err != nil || again != got {
+				t.Errorf("round trip through %s = %#v, %v, want %#v", data, again, err, got)
+			}
+		})
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		quantity int64
+		want     int64
+		wantOK   bool
+	}{
+		{amount: 150, quantity: 3, want: 450, wantOK: true},
+		{amount: 150, quantity: 0, want: 0, wantOK: true},
+		{amount: -150, quantity: 2, want: -300, wantOK: true},
+		{amount: math.MaxInt64, quantity: 2},
+		{amount: math.MinInt64 / 2, quantity: 3},
+	}
+
+	for _, tt := range tests {
+		got, ok := Money{Amount: tt.amount, Currency: "RUB"}.Mul(tt.quantity)
+		if ok != tt.wantOK || (ok && got.Amount != tt.want) {
+			t.Errorf("%d * %d = %d, %t, want %d, %t", tt.amount, tt.quantity, got.Amount, ok, tt.want, tt.wantOK)
+		}
+	}
+}
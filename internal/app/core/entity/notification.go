@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+type (
+	NotificationID      string
+	NotificationChannel string
+)
+
+const (
+	NotificationChannelInApp NotificationChannel = "inApp"
+	NotificationChannelEmail NotificationChannel = "email"
+)
+
+// Notification tells a user about an event, it is kept in their inbox
+// and may also be sent over other channels.
+type Notification struct {
+	ID         NotificationID `json:"id"`
+	UserID     UserID         `json:"-"`
+	Event      EventType      `json:"event"`
+	TenderID   TenderID       `json:"tenderId"`
+	BidID      BidId          `json:"bidId,omitempty"`
+	QuestionID QuestionID     `json:"questionId,omitempty"`
+	Subject    string         `json:"subject"`
+	Text       string         `json:"text"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	ReadAt     *time.Time     `json:"readAt,omitempty"`
+}
+
+// NotificationPreferences are the choices of a user about notifications.
+// Everything is delivered everywhere unless disabled.
+type NotificationPreferences struct {
+	// Email is the address for the email channel, nothing is mailed without it.
+	Email string `json:"email,omitempty"`
+	// Disabled lists the events the user does not want over each channel.
+	Disabled map[NotificationChannel][]EventType `json:"disabled,omitempty"`
+}
+
+// Allows reports whether the event may be delivered to the user over the channel.
+func (r *NotificationPreferences) Allows(channel NotificationChannel, event EventType) bool {
+	if r == nil {
+		return true
+	}
+
+	return !slices.Contains(r.Disabled[channel], event)
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type QuestionID string
+
+// Question is a clarification a prospective bidder asks about the terms of a
+// tender. The tender organization answers either publicly, sharing the answer
+// with every bidder without naming the asker, or privately to the asker only.
+type Question struct {
+	ID       QuestionID `json:"id"`
+	TenderID TenderID   `json:"tenderId"`
+	// AuthorID is only shown to the tender organization and the asker.
+	AuthorID   UserID     `json:"authorId,omitempty"`
+	Text       string     `json:"text"`
+	Answer     string     `json:"answer,omitempty"`
+	Public     bool       `json:"public"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	AnsweredAt *time.Time `json:"answeredAt,omitempty"`
+}
+
+type QuestionAnswer struct {
+	Answer string `json:"answer"`
+	Public bool   `json:"public"`
+}
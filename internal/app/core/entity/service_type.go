@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// ServiceType is an entry of the service type catalog tenders and lots are
+// classified by. A type may refine a parent type, filtering by the parent
+// includes its subtypes. Archived types stay on existing tenders but are not
+// accepted for new ones.
+type ServiceType struct {
+	Name        TenderServiceType `json:"name"`
+	Parent      TenderServiceType `json:"parent,omitempty"`
+	Description string            `json:"description"`
+	Archived    bool              `json:"archived"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+// ServiceTypeUpdate changes an existing catalog entry, the name is never changed
+// because tenders refer to it. A nil field is left as is, an empty Parent makes
+// the type a root.
+type ServiceTypeUpdate struct {
+	Parent      *TenderServiceType `json:"parent"`
+	Description *string            `json:"description"`
+	Archived    *bool              `json:"archived"`
+}
+
+func (r *ServiceType) Apply(update *ServiceTypeUpdate) *ServiceType {
+	if update.Parent != nil {
+		r.Parent = *update.Parent
+	}
+
+	if update.Description != nil {
+		r.Description = *update.Description
+	}
+
+	if update.Archived != nil {
+		r.Archived = *update.Archived
+	}
+
+	return r
+}
@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"strconv"
+	"time"
+)
 
 type (
 	TenderID          string
@@ -12,26 +16,6 @@ type (
 	TenderVersion     int32
 )
 
-const (
-	TenderServiceTypeUndefined    TenderServiceType = ""
-	TenderServiceTypeConstruction TenderServiceType = "Construction"
-	TenderServiceTypeDelivery     TenderServiceType = "Delivery"
-	TenderServiceTypeManufacture  TenderServiceType = "Manufacture"
-)
-
-func NewTenderServiceType(serviceType string) TenderServiceType {
-	switch serviceType {
-	case string(TenderServiceTypeConstruction):
-		return TenderServiceTypeConstruction
-	case string(TenderServiceTypeDelivery):
-		return TenderServiceTypeDelivery
-	case string(TenderServiceTypeManufacture):
-		return TenderServiceTypeManufacture
-	default:
-		return TenderServiceTypeUndefined
-	}
-}
-
 const (
 	TenderStatusCreated   TenderStatus = "Created"
 	TenderStatusPublished TenderStatus = "Published"
@@ -46,7 +30,31 @@ type Tender struct {
 	Status         TenderStatus      `json:"status"`
 	OrganizationID OrganizationID    `json:"organizationId"`
 	Version        TenderVersion     `json:"version"`
-	CreatedAt      time.Time         `json:"createdAt"`
+	// Sealed hides the contents of bids from the tender organization until they are opened.
+	Sealed bool `json:"sealed"`
+	// BidDeadline is when bidding ends, no bids are accepted or changed afterwards.
+	BidDeadline *time.Time `json:"bidDeadline,omitempty"`
+	// InviteOnly tenders are seen and bid on only by invited organizations and users.
+	InviteOnly bool `json:"inviteOnly"`
+	// Budget is what the organization plans to spend, BudgetHidden keeps it from suppliers.
+	Budget       *Money    `json:"budget,omitempty"`
+	BudgetHidden bool      `json:"budgetHidden"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// BidsSealed reports whether the bids of the tender are still sealed at now.
+// Sealed bids open once the deadline passes or the tender is closed.
+func (r *Tender) BidsSealed(now time.Time) bool {
+	if !r.Sealed || r.Status == TenderStatusClosed {
+		return false
+	}
+
+	return r.BidDeadline == nil || now.Before(*r.BidDeadline)
+}
+
+// BiddingClosed reports whether the deadline for bids has passed at now.
+func (r *Tender) BiddingClosed(now time.Time) bool {
+	return r.BidDeadline != nil && !now.Before(*r.BidDeadline)
 }
 
 func (r *Tender) Apply(update *TenderUpdate) *Tender {
@@ -62,6 +70,26 @@ func (r *Tender) Apply(update *TenderUpdate) *Tender {
 		r.ServiceType = TenderServiceType(update.ServiceType)
 	}
 
+	if update.Sealed != nil {
+		r.Sealed = *update.Sealed
+	}
+
+	if update.BidDeadline != nil {
+		r.BidDeadline = update.BidDeadline
+	}
+
+	if update.InviteOnly != nil {
+		r.InviteOnly = *update.InviteOnly
+	}
+
+	if update.Budget != nil {
+		r.Budget = update.Budget
+	}
+
+	if update.BudgetHidden != nil {
+		r.BudgetHidden = *update.BudgetHidden
+	}
+
 	return r
 }
 
@@ -74,4 +102,70 @@ type TenderUpdate struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	ServiceType string `json:"serviceType"`
+	// Sealed, BidDeadline and InviteOnly can only change before the tender is published.
+	Sealed      *bool      `json:"sealed"`
+	BidDeadline *time.Time `json:"bidDeadline"`
+	InviteOnly  *bool      `json:"inviteOnly"`
+	// The budget may be revised at any time.
+	Budget       *Money `json:"budget"`
+	BudgetHidden *bool  `json:"budgetHidden"`
+}
+
+type TenderSort string
+
+const (
+	TenderSortName       TenderSort = "name"
+	TenderSortBudgetAsc  TenderSort = "budget"
+	TenderSortBudgetDesc TenderSort = "-budget"
+)
+
+// TenderListOptions filter and order tender listings. Budgets are only
+// comparable within one currency, so the bounds require Currency. Sorting by
+// budget groups tenders by currency and puts those without a visible budget last.
+type TenderListOptions struct {
+	Currency  Currency
+	BudgetMin *int64
+	BudgetMax *int64
+	Sort      TenderSort
+	// IncludeArchived adds the archived tenders to the listing.
+	IncludeArchived bool
+}
+
+// ParseTenderListOptions reads the options from query values, the budget
+// bounds are decimals in major units of the currency such as "1500.50".
+func ParseTenderListOptions(currency, budgetMin, budgetMax, sort, includeArchived string) (*TenderListOptions, error) {
+	options := &TenderListOptions{
+		Currency: Currency(currency),
+		Sort:     TenderSort(sort),
+	}
+
+	if includeArchived != "" {
+		archived, err := strconv.ParseBool(includeArchived)
+		if err != nil {
+			return nil, errors.New("includeArchived must be true or false")
+		}
+
+		options.IncludeArchived = archived
+	}
+
+	for _, bound := range []struct {
+		value string
+		dst   **int64
+	}{
+		{budgetMin, &options.BudgetMin},
+		{budgetMax, &options.BudgetMax},
+	} {
+		if bound.value == "" {
+			continue
+		}
+
+		amount, err := ParseAmount(bound.value, options.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		*bound.dst = &amount
+	}
+
+	return options, nil
 }
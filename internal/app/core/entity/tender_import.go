@@ -0,0 +1,30 @@
+package entity
+
+// TenderImportRow is the outcome of a single imported row, Row counts from 1.
+type TenderImportRow struct {
+	Row      int      `json:"row"`
+	TenderID TenderID `json:"tenderId,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type TenderImportReport struct {
+	// Atomic imports create every tender or none of them.
+	Atomic    bool               `json:"atomic"`
+	Committed bool               `json:"committed"`
+	Created   int                `json:"created"`
+	Failed    int                `json:"failed"`
+	Rows      []*TenderImportRow `json:"rows"`
+}
+
+// Count fills Created and Failed from Rows.
+func (r *TenderImportReport) Count() {
+	r.Created, r.Failed = 0, 0
+
+	for _, row := range r.Rows {
+		if row.Error == "" {
+			r.Created++
+		} else {
+			r.Failed++
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type AttachmentRepo interface {
+	Create(context.Context, *entity.Attachment) error
+	Read(context.Context, entity.AttachmentID) (*entity.Attachment, error)
+	// List returns the attachments of the owner at version, or the current ones for a zero version.
+	List(context.Context, entity.AttachmentOwner, int32) ([]*entity.Attachment, error)
+	MarkRemoved(context.Context, entity.AttachmentID, int32) error
+}
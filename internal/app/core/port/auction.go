@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type AuctionRepo interface {
+	Create(context.Context, *entity.Auction) error
+	Read(context.Context, entity.TenderID) (*entity.Auction, error)
+	// Lock reads the auction and locks it until the transaction of ctx ends,
+	// which orders concurrent offers on the same auction.
+	Lock(context.Context, entity.TenderID) (*entity.Auction, error)
+	// Update writes the best price, end, offer count and closing of the auction.
+	Update(context.Context, *entity.Auction) error
+	CreateOffer(context.Context, *entity.AuctionOffer) error
+	// LowestOffers returns the lowest offer of every bid, lowest and earliest first.
+	LowestOffers(context.Context, entity.TenderID) ([]*entity.AuctionOffer, error)
+	// ListDue returns the tenders of auctions ended by now and not closed yet.
+	ListDue(context.Context, time.Time) ([]entity.TenderID, error)
+}
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// AuditRepo is append-only, entries cannot be changed or removed once written.
+type AuditRepo interface {
+	Append(context.Context, *entity.AuditEntry) error
+	// List returns the entries matching every set field of the filter, the newest first.
+	List(context.Context, *entity.AuditFilter, *entity.RequestLimitOffset) ([]*entity.AuditEntry, error)
+}
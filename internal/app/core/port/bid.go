@@ -2,14 +2,27 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"avito2024/internal/app/core/entity"
 )
 
 type BidRepo interface {
 	Create(context.Context, *entity.Bid) error
-	ReadMyBids(context.Context, entity.UserID) ([]*entity.Bid, error)
+	ReadMyBids(ctx context.Context, userID entity.UserID, includeArchived bool) ([]*entity.Bid, error)
 	ReadTenderBids(context.Context, entity.TenderID) ([]*entity.Bid, error)
 	ReadBidResponsibleUsers(context.Context, []entity.BidId) ([]entity.UserID, error)
 	ReadBidByID(context.Context, entity.BidId) (*entity.Bid, error)
+	// Update writes the name, description and offer of the bid and returns its new version.
+	Update(context.Context, *entity.Bid) (entity.BidVersion, error)
+	BumpVersion(context.Context, entity.BidId) (entity.BidVersion, error)
+	// ReadDeleted returns the bid only if it is soft deleted.
+	ReadDeleted(context.Context, entity.BidId) (*entity.Bid, error)
+	// Delete soft deletes the bid, it reports false for a missing or already deleted bid.
+	Delete(ctx context.Context, bidID entity.BidId, deletedAt time.Time) (bool, error)
+	// Restore undoes Delete, it reports false for a bid that is not deleted.
+	Restore(context.Context, entity.BidId) (bool, error)
+	// Purge removes the bids deleted before the time together with their scores
+	// and attachments. It returns how many bids and the attachments whose blobs are left to delete.
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, []*entity.Attachment, error)
 }
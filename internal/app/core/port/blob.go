@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore keeps file contents by key. Get of a missing key returns an error
+// matching fs.ErrNotExist.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,14 @@
+package port
+
+import "errors"
+
+// Repos return these, wrapped, when a write breaks a constraint of the schema,
+// such as a bid for a tender that does not exist. The service errors of the
+// same meaning are these values.
+var (
+	ErrWrongInputFormat    = errors.New("wrong format or parameters")
+	ErrUserNotExists       = errors.New("user not exists")
+	ErrTenderNotFound      = errors.New("tender not found")
+	ErrLotNotFound         = errors.New("lot not found")
+	ErrServiceTypeNotFound = errors.New("service type not found")
+)
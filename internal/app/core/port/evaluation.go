@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type EvaluationRepo interface {
+	// ReplaceCriteria swaps the criteria of the tender, scores on the old ones are dropped with them.
+	ReplaceCriteria(context.Context, entity.TenderID, []*entity.EvaluationCriterion) error
+	ListCriteria(context.Context, entity.TenderID) ([]*entity.EvaluationCriterion, error)
+	// SaveScore creates the score or replaces the one the reviewer gave earlier.
+	SaveScore(context.Context, *entity.BidScore) error
+	// ListScores returns the scores of every bid of the tender.
+	ListScores(context.Context, entity.TenderID) ([]*entity.BidScore, error)
+}
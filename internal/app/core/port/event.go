@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// EventPublisher hands domain events to their subscribers once the change is
+// made. Publishing never fails the change, subscribers deal with their own errors.
+type EventPublisher interface {
+	Publish(context.Context, *entity.Event)
+}
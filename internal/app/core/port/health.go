@@ -0,0 +1,9 @@
+package port
+
+import "context"
+
+// HealthChecker reports whether a dependency is able to serve traffic.
+type HealthChecker interface {
+	Name() string
+	Check(context.Context) error
+}
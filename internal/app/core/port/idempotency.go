@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type IdempotencyRepo interface {
+	// Acquire stores record unless an unexpired record with the same scope and
+	// key exists, which is returned instead. A nil record means it was acquired.
+	Acquire(ctx context.Context, record *entity.IdempotencyRecord, lockTimeout time.Duration) (*entity.IdempotencyRecord, error)
+	Complete(ctx context.Context, scope, key string, response *entity.IdempotentResponse, window time.Duration) error
+	Delete(ctx context.Context, scope, key string) error
+	DeleteExpired(context.Context) (int64, error)
+}
@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type InvitationRepo interface {
+	// Create stores the invitation and reports false if the invitee was already invited to the tender.
+	Create(context.Context, *entity.Invitation) (bool, error)
+	Read(context.Context, entity.InvitationID) (*entity.Invitation, error)
+	ListByTender(context.Context, entity.TenderID) ([]*entity.Invitation, error)
+	// ListFor returns the invitations addressed to the viewer or one of their organizations.
+	ListFor(context.Context, *entity.Viewer) ([]*entity.Invitation, error)
+	// Invited reports whether the viewer holds an invitation to the tender that was not declined.
+	Invited(context.Context, entity.TenderID, *entity.Viewer) (bool, error)
+	UpdateStatus(context.Context, entity.InvitationID, entity.InvitationStatus, time.Time) error
+	Delete(context.Context, entity.InvitationID) error
+}
@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type LotRepo interface {
+	Create(context.Context, *entity.Lot) error
+	Read(context.Context, entity.LotID) (*entity.Lot, error)
+	List(context.Context, entity.TenderID) ([]*entity.Lot, error)
+	// Lock lists the lots of the tender and locks them until the transaction of
+	// ctx ends, so lots settled concurrently still see each other.
+	Lock(context.Context, entity.TenderID) ([]*entity.Lot, error)
+	UpdateStatus(context.Context, entity.LotID, entity.LotStatus, entity.BidId) error
+}
@@ -0,0 +1,11 @@
+package port
+
+import "avito2024/internal/app/core/entity"
+
+// Metrics receives business events from the services.
+type Metrics interface {
+	TenderCreated()
+	TenderStatusChanged(entity.TenderStatus)
+	BidCreated()
+	BidDecision(entity.BidReviewDecision)
+}
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// Notifier delivers notifications over one channel.
+type Notifier interface {
+	Channel() entity.NotificationChannel
+	Notify(context.Context, *entity.Notification, *entity.NotificationPreferences) error
+}
+
+type NotificationRepo interface {
+	Create(context.Context, *entity.Notification) error
+	List(ctx context.Context, userID entity.UserID, unreadOnly bool, limitOffset *entity.RequestLimitOffset) ([]*entity.Notification, error)
+	// MarkRead marks the notification of the user read, it reports false if the user has no such notification.
+	MarkRead(ctx context.Context, userID entity.UserID, notificationID entity.NotificationID, readAt time.Time) (bool, error)
+	// ReadPreferences returns the preferences of the users who have set any.
+	ReadPreferences(context.Context, []entity.UserID) (map[entity.UserID]*entity.NotificationPreferences, error)
+	SavePreferences(context.Context, entity.UserID, *entity.NotificationPreferences) error
+}
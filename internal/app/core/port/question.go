@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type QuestionRepo interface {
+	Create(context.Context, *entity.Question) error
+	Read(context.Context, entity.QuestionID) (*entity.Question, error)
+	// List returns every question on the tender.
+	List(context.Context, entity.TenderID) ([]*entity.Question, error)
+	// ListFor returns the questions the user asked on the tender and the publicly answered ones.
+	ListFor(context.Context, entity.TenderID, entity.UserID) ([]*entity.Question, error)
+	Answer(ctx context.Context, questionID entity.QuestionID, answer string, public bool, answeredAt time.Time) error
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+)
+
+type ServiceTypeRepo interface {
+	// Create stores the type and reports false if a type with the name exists.
+	Create(context.Context, *entity.ServiceType) (bool, error)
+	// List returns the whole catalog, it is small enough to be walked in memory.
+	List(context.Context) ([]*entity.ServiceType, error)
+	Update(context.Context, *entity.ServiceType) error
+}
@@ -2,15 +2,44 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"avito2024/internal/app/core/entity"
 )
 
 type TenderRepo interface {
 	Create(context.Context, *entity.Tender) error
-	List(context.Context, []entity.TenderServiceType, *entity.RequestLimitOffset) ([]*entity.Tender, error)
+	// List returns published tenders of the service types. Invitation-only
+	// tenders are included only for their owners and invitees among the viewer.
+	List(
+		context.Context,
+		[]entity.TenderServiceType,
+		*entity.Viewer,
+		*entity.TenderListOptions,
+		*entity.RequestLimitOffset,
+	) ([]*entity.Tender, error)
+	// Read returns the tender unless it is deleted or archived.
 	Read(context.Context, entity.TenderID) (*entity.Tender, error)
+	// Lock is Read that also locks the row of the tender until the transaction of ctx ends.
+	Lock(context.Context, entity.TenderID) (*entity.Tender, error)
+	// ReadDeleted returns the tender only if it is soft deleted.
+	ReadDeleted(context.Context, entity.TenderID) (*entity.Tender, error)
 	UpdateStatus(context.Context, entity.TenderID, entity.TenderStatus) error
-	ListMy(context.Context, []entity.OrganizationID, *entity.RequestLimitOffset) ([]*entity.Tender, error)
+	ListMy(context.Context, []entity.OrganizationID, *entity.TenderListOptions, *entity.RequestLimitOffset) ([]*entity.Tender, error)
 	Update(context.Context, entity.TenderID, *entity.TenderUpdate) error
+	// BumpVersion increments the version of the tender and returns the new one.
+	BumpVersion(context.Context, entity.TenderID) (entity.TenderVersion, error)
+	// StreamMy calls fn for every tender of organizations without loading them all at once.
+	StreamMy(ctx context.Context, organizations []entity.OrganizationID, fn func(*entity.Tender) error) error
+	// Delete soft deletes the tender, it reports false for a missing or already deleted tender.
+	Delete(ctx context.Context, tenderID entity.TenderID, deletedAt time.Time) (bool, error)
+	// Restore undoes Delete, it reports false for a tender that is not deleted.
+	Restore(context.Context, entity.TenderID) (bool, error)
+	// Purge removes the tenders deleted before the time together with their
+	// bids, lots, invitations, questions, criteria, auctions and attachments.
+	// It returns how many tenders and the attachments whose blobs are left to delete.
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, []*entity.Attachment, error)
+	// Archive moves up to limit closed tenders created before the time, with
+	// their bids, to the archive and returns their IDs.
+	Archive(ctx context.Context, createdBefore time.Time, limit int) ([]entity.TenderID, error)
 }
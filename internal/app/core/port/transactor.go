@@ -0,0 +1,14 @@
+package port
+
+import "context"
+
+// Transactor runs fn in a transaction carried by the context passed to fn. Repos
+// called with that context take part in it, fn returning an error rolls it back.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// AfterCommit runs fn once the outermost transaction of ctx has committed,
+	// and never if it rolls back. Outside a transaction fn runs right away.
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+	// InTransaction reports whether ctx carries a transaction.
+	InTransaction(ctx context.Context) bool
+}
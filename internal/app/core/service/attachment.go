@@ -0,0 +1,442 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+const (
+	// sniffLength is how much of an upload is read to detect its type.
+	sniffLength = 3072
+
+	maxAttachmentNameLength = 255
+)
+
+type AttachmentLimits struct {
+	MaxSize int64
+	// AllowedTypes are MIME types accepted after detection from the content.
+	AllowedTypes []string
+}
+
+type AttachmentService struct {
+	tenderService    *TenderService
+	userRepo         port.UserRepo
+	organizationRepo port.OrganizationRepo
+	tenderRepo       port.TenderRepo
+	bidRepo          port.BidRepo
+	attachmentRepo   port.AttachmentRepo
+	blobs            port.BlobStore
+	transactor       port.Transactor
+	limits           AttachmentLimits
+	logger           *zap.Logger
+}
+
+func NewAttachmentService(
+	attachmentRepo port.AttachmentRepo,
+	blobs port.BlobStore,
+	tenderService *TenderService,
+	tenderRepo port.TenderRepo,
+	bidRepo port.BidRepo,
+	userRepo port.UserRepo,
+	orgRepo port.OrganizationRepo,
+	transactor port.Transactor,
+	limits AttachmentLimits,
+	logger *zap.Logger,
+) *AttachmentService {
+	return &AttachmentService{
+		tenderService:    tenderService,
+		userRepo:         userRepo,
+		organizationRepo: orgRepo,
+		tenderRepo:       tenderRepo,
+		bidRepo:          bidRepo,
+		attachmentRepo:   attachmentRepo,
+		blobs:            blobs,
+		transactor:       transactor,
+		limits:           limits,
+		logger:           logger.Named("attachment"),
+	}
+}
+
+func (r *AttachmentService) MaxSize() int64 {
+	return r.limits.MaxSize
+}
+
+// Upload stores the file and adds it to the owner as a new version of the owner.
+func (r *AttachmentService) Upload(
+	ctx context.Context,
+	owner entity.AttachmentOwner,
+	userName string,
+	upload *entity.AttachmentUpload,
+) (_ *entity.Attachment, err error) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.Upload")
+	defer func() { tracing.End(span, err) }()
+
+	name := path.Base(strings.ReplaceAll(upload.Name, `\`, "/"))
+	if name == "" || name == "." || name == "/" || len(name) > maxAttachmentNameLength {
+		return nil, fmt.Errorf("%w: attachment name must be 1 to %d characters", ErrWrongInputFormat, maxAttachmentNameLength)
+	}
+
+	owner, err = r.authorize(ctx, owner, userName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	head, contentType, err := sniff(upload.Body, r.limits.AllowedTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &entity.Attachment{
+		ID:          entity.AttachmentID(uuid.NewString()),
+		TenderID:    owner.TenderID,
+		BidID:       owner.BidID,
+		Name:        name,
+		ContentType: contentType.String(),
+		CreatedAt:   time.Now(),
+	}
+
+	hash := sha256.New()
+	body := &sizeLimitReader{
+		reader: io.TeeReader(io.MultiReader(bytes.NewReader(head), upload.Body), hash),
+		limit:  r.limits.MaxSize,
+	}
+
+	if err := r.blobs.Put(ctx, attachment.BlobKey(), body); err != nil {
+		return nil, fmt.Errorf("store attachment: %w", err)
+	}
+
+	attachment.Size = body.read
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if upload.SHA256 != "" && !strings.EqualFold(upload.SHA256, attachment.SHA256) {
+		r.deleteBlob(ctx, attachment)
+		return nil, fmt.Errorf("%w: got %s", ErrChecksumMismatch, attachment.SHA256)
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		version, err := r.bumpVersion(ctx, owner)
+		if err != nil {
+			return err
+		}
+
+		attachment.AddedInVersion = version
+
+		return r.attachmentRepo.Create(ctx, attachment)
+	})
+	if err != nil {
+		r.deleteBlob(ctx, attachment)
+		return nil, fmt.Errorf("create attachment: %w", err)
+	}
+
+	r.log(ctx).Info("attachment uploaded",
+		zap.String("tenderId", string(owner.TenderID)),
+		zap.String("bidId", string(owner.BidID)),
+		zap.String("attachmentId", string(attachment.ID)),
+		zap.Int64("size", attachment.Size),
+	)
+
+	return attachment, nil
+}
+
+// List returns the attachments of the owner at version, the current ones for a zero version.
+func (r *AttachmentService) List(
+	ctx context.Context,
+	owner entity.AttachmentOwner,
+	userName string,
+	version int32,
+) (_ []*entity.Attachment, err error) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.List")
+	defer func() { tracing.End(span, err) }()
+
+	owner, err = r.authorize(ctx, owner, userName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.attachmentRepo.List(ctx, owner, version)
+}
+
+// Download opens the content of the attachment, the caller closes it. Removed
+// attachments stay downloadable, they belong to earlier versions of the owner.
+func (r *AttachmentService) Download(
+	ctx context.Context,
+	owner entity.AttachmentOwner,
+	attachmentID entity.AttachmentID,
+	userName string,
+) (_ *entity.Attachment, _ io.ReadCloser, err error) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.Download")
+	defer func() { tracing.End(span, err) }()
+
+	owner, err = r.authorize(ctx, owner, userName, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := r.read(ctx, owner, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := r.blobs.Get(ctx, attachment.BlobKey())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			r.log(ctx).Error("attachment content is missing", zap.String("attachmentId", string(attachmentID)))
+			return nil, nil, ErrAttachmentNotFound
+		}
+
+		return nil, nil, err
+	}
+
+	return attachment, body, nil
+}
+
+// Delete removes the attachment from the owner as a new version of the owner.
+// The content is kept for the versions that still have the attachment.
+func (r *AttachmentService) Delete(
+	ctx context.Context,
+	owner entity.AttachmentOwner,
+	attachmentID entity.AttachmentID,
+	userName string,
+) (_ *entity.Attachment, err error) {
+	ctx, span := tracer.Start(ctx, "AttachmentService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	owner, err = r.authorize(ctx, owner, userName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := r.read(ctx, owner, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.RemovedInVersion != nil {
+		return nil, ErrAttachmentNotFound
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		version, err := r.bumpVersion(ctx, owner)
+		if err != nil {
+			return err
+		}
+
+		attachment.RemovedInVersion = &version
+
+		return r.attachmentRepo.MarkRemoved(ctx, attachmentID, version)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delete attachment: %w", err)
+	}
+
+	r.log(ctx).Info("attachment deleted",
+		zap.String("tenderId", string(owner.TenderID)),
+		zap.String("bidId", string(owner.BidID)),
+		zap.String("attachmentId", string(attachmentID)),
+	)
+
+	return attachment, nil
+}
+
+// authorize checks what the user may do with the attachments of the owner and
+// returns the owner with the tender of a bid filled in.
+//
+// The responsible users of the tender organization read and write the tender
+// attachments, anyone else who sees the tender may only read those of a
+// published tender. Bid
+// attachments are written by the bid author until the bid deadline and read by
+// the author and, once the bids are no longer sealed, by the responsible users
+// of the tender organization reviewing the bid.
+func (r *AttachmentService) authorize(ctx context.Context, owner entity.AttachmentOwner, userName string, write bool) (entity.AttachmentOwner, error) {
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil {
+		return owner, err
+	}
+
+	if userID == "" {
+		return owner, ErrUserNotExists
+	}
+
+	if owner.BidID != "" {
+		return r.authorizeBid(ctx, owner.BidID, userID, write)
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, owner.TenderID)
+	if err != nil {
+		return owner, err
+	}
+
+	if tender == nil {
+		return owner, ErrTenderNotFound
+	}
+
+	if !write && tender.Status == entity.TenderStatusPublished {
+		return owner, r.tenderService.requireVisible(ctx, tender, userID)
+	}
+
+	return owner, r.requireResponsible(ctx, tender.OrganizationID, userID)
+}
+
+func (r *AttachmentService) authorizeBid(ctx context.Context, bidID entity.BidId, userID entity.UserID, write bool) (entity.AttachmentOwner, error) {
+	owner := entity.AttachmentOwner{BidID: bidID}
+
+	bid, err := r.bidRepo.ReadBidByID(ctx, bidID)
+	if err != nil {
+		return owner, err
+	}
+
+	if bid == nil {
+		return owner, ErrBidNotFound
+	}
+
+	owner.TenderID = bid.TenderID
+
+	tender, err := r.tenderRepo.Read(ctx, bid.TenderID)
+	if err != nil {
+		return owner, err
+	}
+
+	if tender == nil {
+		return owner, ErrTenderNotFound
+	}
+
+	author, err := isBidAuthor(ctx, r.organizationRepo, bid, userID)
+	if err != nil {
+		return owner, err
+	}
+
+	now := time.Now()
+
+	if author {
+		if write && tender.BiddingClosed(now) {
+			return owner, ErrBiddingClosed
+		}
+
+		return owner, nil
+	}
+
+	if write {
+		return owner, ErrNotEnoughRights
+	}
+
+	if err := r.requireResponsible(ctx, tender.OrganizationID, userID); err != nil {
+		return owner, err
+	}
+
+	if tender.BidsSealed(now) {
+		return owner, ErrBidsSealed
+	}
+
+	return owner, nil
+}
+
+func (r *AttachmentService) requireResponsible(ctx context.Context, organizationID entity.OrganizationID, userID entity.UserID) error {
+	users, err := r.organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{organizationID})
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(users, userID) {
+		return ErrNotEnoughRights
+	}
+
+	return nil
+}
+
+// read returns the attachment if it belongs to the owner.
+func (r *AttachmentService) read(ctx context.Context, owner entity.AttachmentOwner, attachmentID entity.AttachmentID) (*entity.Attachment, error) {
+	attachment, err := r.attachmentRepo.Read(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment == nil || attachment.Owner() != owner {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+func (r *AttachmentService) bumpVersion(ctx context.Context, owner entity.AttachmentOwner) (int32, error) {
+	if owner.BidID != "" {
+		version, err := r.bidRepo.BumpVersion(ctx, owner.BidID)
+		return int32(version), err
+	}
+
+	version, err := r.tenderRepo.BumpVersion(ctx, owner.TenderID)
+
+	return int32(version), err
+}
+
+func (r *AttachmentService) deleteBlob(ctx context.Context, attachment *entity.Attachment) {
+	if err := r.blobs.Delete(context.WithoutCancel(ctx), attachment.BlobKey()); err != nil {
+		r.log(ctx).Error("failed to delete attachment content",
+			zap.String("attachmentId", string(attachment.ID)),
+			zap.Error(err),
+		)
+	}
+}
+
+func (r *AttachmentService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
+
+// sniff reads the head of an upload and detects its type from the content,
+// which must be one of allowedTypes. The head is no longer in body.
+func sniff(body io.Reader, allowedTypes []string) ([]byte, *mimetype.MIME, error) {
+	head := make([]byte, sniffLength)
+
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	head = head[:n]
+
+	if n == 0 {
+		return nil, nil, fmt.Errorf("%w: attachment is empty", ErrWrongInputFormat)
+	}
+
+	contentType := mimetype.Detect(head)
+	if !slices.ContainsFunc(allowedTypes, contentType.Is) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrAttachmentTypeNotAllowed, contentType.String())
+	}
+
+	return head, contentType, nil
+}
+
+// sizeLimitReader fails the upload as soon as it grows past limit.
+type sizeLimitReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	if r.read > r.limit {
+		return n, fmt.Errorf("%w: limit is %d bytes", ErrAttachmentTooLarge, r.limit)
+	}
+
+	return n, err
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var pdfHead = []byte("%PDF-1.7\n")
+
+func TestSniff(t *testing.T) {
+	allowed := []string{"application/pdf", "image/png"}
+
+	tests := []struct {
+		name     string
+		body     []byte
+		want     string
+		wantHead int
+		wantErr  error
+	}{
+		{name: "pdf", body: pdfHead, want: "application/pdf", wantHead: len(pdfHead)},
+		{
+			name:     "head is cut at the sniff length",
+			body:     append(append([]byte{}, pdfHead...), bytes.Repeat([]byte("a"), 2*sniffLength)...),
+			want:     "application/pdf",
+			wantHead: sniffLength,
+		},
+		{name: "type not allowed", body: []byte("just some text"), wantErr: ErrAttachmentTypeNotAllowed},
+		{name: "declared type is ignored", body: []byte("<html><body>pdf</body></html>"), wantErr: ErrAttachmentTypeNotAllowed},
+		{name: "empty", body: nil, wantErr: ErrWrongInputFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := bytes.NewReader(tt.body)
+
+			head, contentType, err := sniff(body, allowed)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !contentType.Is(tt.want) {
+				t.Errorf("got type %s, want %s", contentType, tt.want)
+			}
+
+			if len(head) != tt.wantHead {
+				t.Errorf("got head of %d bytes, want %d", len(head), tt.wantHead)
+			}
+
+			if rest := body.Len(); len(head)+rest != len(tt.body) {
+				t.Errorf("head of %d bytes and %d left do not add up to %d", len(head), rest, len(tt.body))
+			}
+		})
+	}
+}
+
+func TestSizeLimitReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		limit   int64
+		wantErr bool
+	}{
+		{name: "below the limit", size: 9, limit: 10},
+		{name: "at the limit", size: 10, limit: 10},
+		{name: "past the limit", size: 11, limit: 10, wantErr: true},
+		{name: "far past the limit", size: 1 << 20, limit: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &sizeLimitReader{reader: strings.NewReader(strings.Repeat("a", tt.size)), limit: tt.limit}
+
+			n, err := io.Copy(io.Discard, reader)
+			if tt.wantErr {
+				if !errors.Is(err, ErrAttachmentTooLarge) {
+					t.Fatalf("got error %v, want %v", err, ErrAttachmentTooLarge)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if n != int64(tt.size) || reader.read != int64(tt.size) {
+				t.Errorf("copied %d, counted %d, want %d", n, reader.read, tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+const (
+	auctionCloserWorker = "auction_closer"
+
+	maxAntiSnipingSeconds = 60 * 60
+)
+
+// AuctionService runs reverse auctions on tenders. Offers on one auction are
+// ordered by locking its row, so the best price only ever goes down.
+type AuctionService struct {
+	tenderService    *TenderService
+	bidService       *BidService
+	userRepo         port.UserRepo
+	organizationRepo port.OrganizationRepo
+	tenderRepo       port.TenderRepo
+	bidRepo          port.BidRepo
+	auctionRepo      port.AuctionRepo
+	transactor       port.Transactor
+	logger           *zap.Logger
+}
+
+func NewAuctionService(
+	auctionRepo port.AuctionRepo,
+	tenderService *TenderService,
+	bidService *BidService,
+	tenderRepo port.TenderRepo,
+	bidRepo port.BidRepo,
+	userRepo port.UserRepo,
+	orgRepo port.OrganizationRepo,
+	transactor port.Transactor,
+	logger *zap.Logger,
+) *AuctionService {
+	return &AuctionService{
+		tenderService:    tenderService,
+		bidService:       bidService,
+		userRepo:         userRepo,
+		organizationRepo: orgRepo,
+		tenderRepo:       tenderRepo,
+		bidRepo:          bidRepo,
+		auctionRepo:      auctionRepo,
+		transactor:       transactor,
+		logger:           logger.Named("auction"),
+	}
+}
+
+// Create turns the tender into a reverse auction. It has to happen before the
+// tender is published, and sealed tenders cannot have one since the best price is public.
+func (r *AuctionService) Create(ctx context.Context, tenderID entity.TenderID, auction *entity.Auction, userName string) (_ *entity.Auction, err error) {
+	ctx, span := tracer.Start(ctx, "AuctionService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	now := time.Now()
+
+	if err := validateAuction(auction, now); err != nil {
+		return nil, err
+	}
+
+	tender, _, err := r.bidService.authorizeReviewer(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender.Status != entity.TenderStatusCreated {
+		return nil, fmt.Errorf("%w: an auction can only be added before the tender is published", ErrWrongInputFormat)
+	}
+
+	if tender.Sealed {
+		return nil, fmt.Errorf("%w: a sealed tender cannot be auctioned", ErrWrongInputFormat)
+	}
+
+	existing, err := r.auctionRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		return nil, fmt.Errorf("%w: the tender already has an auction", ErrWrongInputFormat)
+	}
+
+	auction.TenderID = tenderID
+	auction.BestPrice = nil
+	auction.BestBidID = ""
+	auction.Offers = 0
+	auction.WinnerBidID = ""
+	auction.ClosedAt = nil
+
+	if err := r.auctionRepo.Create(ctx, auction); err != nil {
+		return nil, fmt.Errorf("create auction: %w", err)
+	}
+
+	auction.Status = auction.StatusAt(now)
+
+	r.log(ctx).Info("auction created",
+		zap.String("tenderId", string(tenderID)),
+		zap.Time("startsAt", auction.StartsAt),
+		zap.Time("endsAt", auction.EndsAt),
+	)
+
+	return auction, nil
+}
+
+// Get returns the auction of the tender, closing it first if it has ended.
+// The best price is public, the bid holding it is only shown to the tender organization.
+func (r *AuctionService) Get(ctx context.Context, tenderID entity.TenderID, userName string) (_ *entity.Auction, err error) {
+	ctx, span := tracer.Start(ctx, "AuctionService.Get")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender == nil {
+		return nil, ErrTenderNotFound
+	}
+
+	users, err := r.organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{tender.OrganizationID})
+	if err != nil {
+		return nil, err
+	}
+
+	owner := slices.Contains(users, userID)
+
+	if !owner {
+		if tender.Status == entity.TenderStatusCreated {
+			return nil, ErrNotEnoughRights
+		}
+
+		if err := r.tenderService.requireVisible(ctx, tender, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	auction, err := r.auctionRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction == nil {
+		return nil, ErrAuctionNotFound
+	}
+
+	if auction.StatusAt(time.Now()) == entity.AuctionStatusEnded {
+		if auction, err = r.close(ctx, tenderID); err != nil {
+			return nil, err
+		}
+	}
+
+	auction.Status = auction.StatusAt(time.Now())
+
+	if !owner {
+		auction.BestBidID = ""
+	}
+
+	return auction, nil
+}
+
+// PlaceOffer lowers the price of the bid in the running auction of the tender.
+// The offer has to undercut the best price by the minimal decrement, or not
+// exceed the start price for the first offer.
+func (r *AuctionService) PlaceOffer(
+	ctx context.Context,
+	tenderID entity.TenderID,
+	input *entity.AuctionOfferInput,
+	userName string,
+) (_ *entity.AuctionOffer, err error) {
+	ctx, span := tracer.Start(ctx, "AuctionService.PlaceOffer")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	bid, err := r.bidRepo.ReadBidByID(ctx, input.BidID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bid == nil || bid.TenderID != tenderID {
+		return nil, ErrBidNotFound
+	}
+
+	author, err := isBidAuthor(ctx, r.organizationRepo, bid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !author {
+		return nil, ErrNotEnoughRights
+	}
+
+	if bid.Status == entity.BidStatus(Canceled) {
+		return nil, fmt.Errorf("%w: the bid is canceled", ErrWrongInputFormat)
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender == nil {
+		return nil, ErrTenderNotFound
+	}
+
+	if tender.Status != entity.TenderStatusPublished {
+		return nil, ErrAuctionNotRunning
+	}
+
+	offer := &entity.AuctionOffer{
+		ID:       entity.AuctionOfferID(uuid.NewString()),
+		TenderID: tenderID,
+		BidID:    bid.ID,
+		Amount:   input.Amount,
+	}
+
+	var extended bool
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		auction, err := r.auctionRepo.Lock(ctx, tenderID)
+		if err != nil {
+			return err
+		}
+
+		if auction == nil {
+			return ErrAuctionNotFound
+		}
+
+		// The time is taken once the lock is held, offers waiting on it are judged by when they got it.
+		offer.PlacedAt = time.Now()
+
+		if auction.StatusAt(offer.PlacedAt) != entity.AuctionStatusRunning {
+			return ErrAuctionNotRunning
+		}
+
+		if extended, err = applyOffer(auction, offer); err != nil {
+			return err
+		}
+
+		if err := r.auctionRepo.CreateOffer(ctx, offer); err != nil {
+			return err
+		}
+
+		return r.auctionRepo.Update(ctx, auction)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("place offer: %w", err)
+	}
+
+	r.log(ctx).Info("auction offer placed",
+		zap.String("tenderId", string(tenderID)),
+		zap.String("bidId", string(bid.ID)),
+		zap.Stringer("amount", offer.Amount),
+		zap.Bool("extended", extended),
+	)
+
+	return offer, nil
+}
+
+// RunCloser closes ended auctions every interval until ctx is done.
+func (r *AuctionService) RunCloser(ctx context.Context, monitor *WorkerMonitor, interval time.Duration) {
+	monitor.Register(auctionCloserWorker, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		tenderIDs, err := r.auctionRepo.ListDue(ctx, time.Now())
+		if err != nil {
+			r.logger.Error("list ended auctions failed", zap.Error(err))
+		}
+
+		for _, tenderID := range tenderIDs {
+			if _, err = r.close(ctx, tenderID); err != nil {
+				r.logger.Error("close auction failed", zap.String("tenderId", string(tenderID)), zap.Error(err))
+			}
+		}
+
+		monitor.Beat(auctionCloserWorker, err)
+	}
+}
+
+// close picks the winner of an ended auction and closes the tender. The lowest
+// offer of a bid that is still valid wins, later offers never beat an equal earlier one.
+func (r *AuctionService) close(ctx context.Context, tenderID entity.TenderID) (auction *entity.Auction, err error) {
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		auction, err = r.auctionRepo.Lock(ctx, tenderID)
+		if err != nil {
+			return err
+		}
+
+		if auction == nil {
+			return ErrAuctionNotFound
+		}
+
+		now := time.Now()
+
+		// Another replica closed it, or an offer extended it, while we waited for the lock.
+		if auction.StatusAt(now) != entity.AuctionStatusEnded {
+			return nil
+		}
+
+		offers, err := r.auctionRepo.LowestOffers(ctx, tenderID)
+		if err != nil {
+			return err
+		}
+
+		for _, offer := range offers {
+			bid, err := r.bidRepo.ReadBidByID(ctx, offer.BidID)
+			if err != nil {
+				return err
+			}
+
+			if bid != nil && bid.Status != entity.BidStatus(Canceled) {
+				auction.WinnerBidID = bid.ID
+				break
+			}
+		}
+
+		auction.ClosedAt = &now
+
+		if err := r.auctionRepo.Update(ctx, auction); err != nil {
+			return err
+		}
+
+		tender, err := r.tenderRepo.Read(ctx, tenderID)
+		if err != nil {
+			return err
+		}
+
+		if tender != nil && tender.Status != entity.TenderStatusClosed {
+			if err := r.tenderService.changeStatus(ctx, tender, entity.TenderStatusClosed, ""); err != nil {
+				return err
+			}
+		}
+
+		r.log(ctx).Info("auction closed",
+			zap.String("tenderId", string(tenderID)),
+			zap.String("winnerBidId", string(auction.WinnerBidID)),
+		)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("close auction: %w", err)
+	}
+
+	return auction, nil
+}
+
+// acceptOffer checks the offer against the locked auction.
+func acceptOffer(auction *entity.Auction, offer *entity.AuctionOffer) error {
+	if offer.Amount.Currency != auction.StartPrice.Currency {
+		return fmt.Errorf("%w: the auction is in %s", ErrWrongInputFormat, auction.StartPrice.Currency)
+	}
+
+	limit := auction.StartPrice
+	if auction.BestPrice != nil {
+		limit.Amount = auction.BestPrice.Amount - auction.MinDecrement.Amount
+	}
+
+	if offer.Amount.Amount <= 0 {
+		return fmt.Errorf("%w: the amount must be positive", ErrWrongInputFormat)
+	}
+
+	if offer.Amount.Amount > limit.Amount {
+		return fmt.Errorf("%w: at most %s %s is accepted", ErrOfferTooHigh, limit, limit.Currency)
+	}
+
+	return nil
+}
+
+// applyOffer makes the accepted offer the best price of the locked auction.
+// An offer in the last antiSnipingSeconds extends the auction to that long
+// after it, which it reports.
+func applyOffer(auction *entity.Auction, offer *entity.AuctionOffer) (bool, error) {
+	if err := acceptOffer(auction, offer); err != nil {
+		return false, err
+	}
+
+	auction.BestPrice = &offer.Amount
+	auction.BestBidID = offer.BidID
+	auction.Offers++
+
+	window := time.Duration(auction.AntiSnipingSeconds) * time.Second
+	if window > 0 && auction.EndsAt.Sub(offer.PlacedAt) < window {
+		auction.EndsAt = offer.PlacedAt.Add(window)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func validateAuction(auction *entity.Auction, now time.Time) error {
+	if !auction.StartPrice.Currency.Valid() || auction.StartPrice.Amount <= 0 {
+		return fmt.Errorf("%w: startPrice must be a positive amount in an ISO 4217 currency", ErrWrongInputFormat)
+	}
+
+	if auction.MinDecrement.Currency == "" {
+		auction.MinDecrement.Currency = auction.StartPrice.Currency
+	}
+
+	if auction.MinDecrement.Currency != auction.StartPrice.Currency {
+		return fmt.Errorf("%w: minDecrement must be in the startPrice currency", ErrWrongInputFormat)
+	}
+
+	if auction.MinDecrement.Amount <= 0 || auction.MinDecrement.Amount >= auction.StartPrice.Amount {
+		return fmt.Errorf("%w: minDecrement must be positive and below startPrice", ErrWrongInputFormat)
+	}
+
+	if !auction.EndsAt.After(auction.StartsAt) || !auction.EndsAt.After(now) {
+		return fmt.Errorf("%w: endsAt must be in the future and after startsAt", ErrWrongInputFormat)
+	}
+
+	if auction.AntiSnipingSeconds < 0 || auction.AntiSnipingSeconds > maxAntiSnipingSeconds {
+		return fmt.Errorf("%w: antiSnipingSeconds must be from 0 to %d", ErrWrongInputFormat, maxAntiSnipingSeconds)
+	}
+
+	return nil
+}
+
+func (r *AuctionService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+func TestApplyOffer(t *testing.T) {
+	endsAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	rub := func(amount int64) entity.Money {
+		return entity.Money{Amount: amount, Currency: "RUB"}
+	}
+
+	newAuction := func(bestPrice *entity.Money, antiSnipingSeconds int32) *entity.Auction {
+		return &entity.Auction{
+			StartPrice:         rub(100000),
+			MinDecrement:       rub(1000),
+			StartsAt:           endsAt.Add(-time.Hour),
+			EndsAt:             endsAt,
+			AntiSnipingSeconds: antiSnipingSeconds,
+			BestPrice:          bestPrice,
+		}
+	}
+
+	best := rub(50000)
+
+	tests := []struct {
+		name         string
+		auction      *entity.Auction
+		amount       entity.Money
+		placedBefore time.Duration
+		wantErr      error
+		wantEndsAt   time.Time
+		wantExtended bool
+	}{
+		{
+			name:         "first offer at the start price",
+			auction:      newAuction(nil, 60),
+			amount:       rub(100000),
+			placedBefore: time.Hour,
+			wantEndsAt:   endsAt,
+		},
+		{
+			name:         "first offer above the start price",
+			auction:      newAuction(nil, 60),
+			amount:       rub(100001),
+			placedBefore: time.Hour,
+			wantErr:      ErrOfferTooHigh,
+		},
+		{
+			name:         "undercuts by the decrement",
+			auction:      newAuction(&best, 60),
+			amount:       rub(49000),
+			placedBefore: time.Hour,
+			wantEndsAt:   endsAt,
+		},
+		{
+			name:         "undercuts by less than the decrement",
+			auction:      newAuction(&best, 60),
+			amount:       rub(49001),
+			placedBefore: time.Hour,
+			wantErr:      ErrOfferTooHigh,
+		},
+		{
+			name:         "other currency",
+			auction:      newAuction(&best, 60),
+			amount:       entity.Money{Amount: 100, Currency: "USD"},
+			placedBefore: time.Hour,
+			wantErr:      ErrWrongInputFormat,
+		},
+		{
+			name:         "not positive",
+			auction:      newAuction(nil, 60),
+			amount:       rub(0),
+			placedBefore: time.Hour,
+			wantErr:      ErrWrongInputFormat,
+		},
+		{
+			name:         "before the anti-sniping window",
+			auction:      newAuction(&best, 60),
+			amount:       rub(40000),
+			placedBefore: time.Minute,
+			wantEndsAt:   endsAt,
+		},
+		{
+			name:         "within the anti-sniping window",
+			auction:      newAuction(&best, 60),
+			amount:       rub(40000),
+			placedBefore: 10 * time.Second,
+			wantEndsAt:   endsAt.Add(50 * time.Second),
+			wantExtended: true,
+		},
+		{
+			name:         "at the last moment",
+			auction:      newAuction(&best, 60),
+			amount:       rub(40000),
+			placedBefore: time.Nanosecond,
+			wantEndsAt:   endsAt.Add(time.Minute - time.Nanosecond),
+			wantExtended: true,
+		},
+		{
+			name:         "anti-sniping disabled",
+			auction:      newAuction(&best, 0),
+			amount:       rub(40000),
+			placedBefore: time.Second,
+			wantEndsAt:   endsAt,
+		},
+		{
+			name:         "rejected offer does not extend",
+			auction:      newAuction(&best, 60),
+			amount:       rub(50000),
+			placedBefore: time.Second,
+			wantErr:      ErrOfferTooHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bestBefore := tt.auction.BestPrice
+			offer := &entity.AuctionOffer{BidID: "bid", Amount: tt.amount, PlacedAt: endsAt.Add(-tt.placedBefore)}
+
+			extended, err := applyOffer(tt.auction, offer)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+
+				if tt.auction.BestPrice != bestBefore || tt.auction.Offers != 0 || !tt.auction.EndsAt.Equal(endsAt) {
+					t.Error("rejected offer changed the auction")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if extended != tt.wantExtended || !tt.auction.EndsAt.Equal(tt.wantEndsAt) {
+				t.Errorf("got extended %t, ends at %s, want %t, %s", extended, tt.auction.EndsAt, tt.wantExtended, tt.wantEndsAt)
+			}
+
+			if *tt.auction.BestPrice != tt.amount || tt.auction.BestBidID != "bid" || tt.auction.Offers != 1 {
+				t.Errorf("got best price %v of %q after %d offers, want %v of %q after 1",
+					tt.auction.BestPrice, tt.auction.BestBidID, tt.auction.Offers, tt.amount, "bid")
+			}
+		})
+	}
+}
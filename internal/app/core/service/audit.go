@@ -0,0 +1,213 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+// AuditService keeps the audit log, the durable answer to who changed what and
+// when. Services record changes through it in the transaction of the change.
+type AuditService struct {
+	auditRepo        port.AuditRepo
+	userRepo         port.UserRepo
+	organizationRepo port.OrganizationRepo
+	admins           []string
+	logger           *zap.Logger
+}
+
+func NewAuditService(
+	auditRepo port.AuditRepo,
+	userRepo port.UserRepo,
+	orgRepo port.OrganizationRepo,
+	admins []string,
+	logger *zap.Logger,
+) *AuditService {
+	return &AuditService{
+		auditRepo:        auditRepo,
+		userRepo:         userRepo,
+		organizationRepo: orgRepo,
+		admins:           admins,
+		logger:           logger.Named("audit"),
+	}
+}
+
+// List returns the audit entries matching the filter. Admins read the whole
+// log, anyone else only the entries of an organization they are responsible for,
+// without the bid entries of tenders whose bids are still sealed.
+func (r *AuditService) List(
+	ctx context.Context,
+	filter *entity.AuditFilter,
+	limitOffset *entity.RequestLimitOffset,
+	userName string,
+) (_ []*entity.AuditEntry, err error) {
+	ctx, span := tracer.Start(ctx, "AuditService.List")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateAuditFilter(filter); err != nil {
+		return nil, err
+	}
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	if !slices.Contains(r.admins, userName) {
+		if filter.OrganizationID == "" {
+			return nil, fmt.Errorf("%w: organizationId is required", ErrWrongInputFormat)
+		}
+
+		organizations, err := r.organizationRepo.ReadResponsibleUserOrganization(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(organizations, filter.OrganizationID) {
+			return nil, ErrNotEnoughRights
+		}
+
+		// Bid entries carry the author and the offer, which the tender
+		// organization must not see before the bids open.
+		filter.HideSealedBids = true
+	}
+
+	return r.auditRepo.List(ctx, filter, limitOffset)
+}
+
+// record appends an entry about the change of the entity from before to after,
+// either of them is nil for a created or removed entity. Call it within the
+// transaction of the change, so the change is never kept without its entry.
+func (r *AuditService) record(
+	ctx context.Context,
+	entityType entity.AuditEntityType,
+	entityID string,
+	action entity.AuditAction,
+	organizationID entity.OrganizationID,
+	actorID entity.UserID,
+	before, after any,
+) error {
+	return r.append(ctx, &entity.AuditEntry{
+		EntityType:     entityType,
+		EntityID:       entityID,
+		Action:         action,
+		ActorID:        actorID,
+		OrganizationID: organizationID,
+	}, before, after)
+}
+
+// recordBid is record for a bid of the tender. The entry keeps the tender, so
+// List can hide it from the tender organization while the bids are sealed.
+func (r *AuditService) recordBid(
+	ctx context.Context,
+	tender *entity.Tender,
+	bidID entity.BidId,
+	action entity.AuditAction,
+	actorID entity.UserID,
+	before, after any,
+) error {
+	return r.append(ctx, &entity.AuditEntry{
+		EntityType:     entity.AuditEntityBid,
+		EntityID:       string(bidID),
+		Action:         action,
+		ActorID:        actorID,
+		OrganizationID: tender.OrganizationID,
+		TenderID:       tender.ID,
+	}, before, after)
+}
+
+func (r *AuditService) append(ctx context.Context, entry *entity.AuditEntry, before, after any) error {
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return fmt.Errorf("audit %s %s: %w", entry.EntityType, entry.Action, err)
+	}
+
+	entry.ID = entity.AuditEntryID(uuid.NewString())
+	entry.Changes = changes
+	entry.RequestID = logger.RequestID(ctx)
+	entry.IP = logger.ClientIP(ctx)
+	entry.CreatedAt = time.Now()
+
+	if err := r.auditRepo.Append(ctx, entry); err != nil {
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// auditChanges compares the JSON forms of before and after field by field.
+func auditChanges(before, after any) (map[string]entity.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]entity.AuditChange)
+
+	keys := append(maps.Keys(beforeFields), maps.Keys(afterFields)...)
+	for _, key := range keys {
+		b, a := beforeFields[key], afterFields[key]
+		if !bytes.Equal(b, a) {
+			changes[key] = entity.AuditChange{Before: b, After: a}
+		}
+	}
+
+	return changes, nil
+}
+
+func auditFields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func validateAuditFilter(filter *entity.AuditFilter) error {
+	for name, id := range map[string]string{
+		"entityId":       filter.EntityID,
+		"actorId":        string(filter.ActorID),
+		"organizationId": string(filter.OrganizationID),
+	} {
+		if id == "" {
+			continue
+		}
+
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%w: %s must be a UUID", ErrWrongInputFormat, name)
+		}
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("%w: from must be before to", ErrWrongInputFormat)
+	}
+
+	return nil
+}
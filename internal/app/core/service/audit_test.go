@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+type auditRepoStub struct {
+	entries []*entity.AuditEntry
+	filter  *entity.AuditFilter
+}
+
+func (r *auditRepoStub) Append(_ context.Context, entry *entity.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *auditRepoStub) List(_ context.Context, filter *entity.AuditFilter, _ *entity.RequestLimitOffset) ([]*entity.AuditEntry, error) {
+	r.filter = filter
+	return r.entries, nil
+}
+
+type userRepoStub map[string]entity.UserID
+
+func (r userRepoStub) FindUserId(_ context.Context, userName string) (entity.UserID, error) {
+	return r[userName], nil
+}
+
+func (r userRepoStub) Exists(_ context.Context, userID entity.UserID) bool {
+	for _, id := range r {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+type organizationRepoStub struct {
+	port.OrganizationRepo
+	responsible map[entity.UserID][]entity.OrganizationID
+}
+
+func (r organizationRepoStub) ReadResponsibleUserOrganization(_ context.Context, userID entity.UserID) ([]entity.OrganizationID, error) {
+	return r.responsible[userID], nil
+}
+
+func (r organizationRepoStub) Exists(_ context.Context, organizationID entity.OrganizationID) bool {
+	for _, organizations := range r.responsible {
+		if slices.Contains(organizations, organizationID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r organizationRepoStub) FindOrganizationsByResponsibleUserID(_ context.Context, userID entity.UserID) ([]entity.OrganizationID, error) {
+	return r.responsible[userID], nil
+}
+
+func (r organizationRepoStub) FindResponsibleUsers(_ context.Context, organizations []entity.OrganizationID) ([]entity.UserID, error) {
+	var users []entity.UserID
+
+	for userID, responsible := range r.responsible {
+		for _, organizationID := range organizations {
+			if slices.Contains(responsible, organizationID) {
+				users = append(users, userID)
+				break
+			}
+		}
+	}
+
+	slices.Sort(users)
+
+	return users, nil
+}
+
+func TestAuditBidEntriesOfSealedTenders(t *testing.T) {
+	const (
+		ownerID        = entity.UserID("6f1c3a52-0a3e-4c1f-9d4b-1b2e3c4d5e6f")
+		authorID       = entity.UserID("0e9d8c7b-6a5f-4e3d-8c2b-1a0f9e8d7c6b")
+		adminID        = entity.UserID("5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d")
+		organizationID = entity.OrganizationID("9b8a7c6d-5e4f-4321-9fed-cba987654321")
+	)
+
+	tender := &entity.Tender{
+		ID:             "3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a",
+		OrganizationID: organizationID,
+		Sealed:         true,
+		Status:         entity.TenderStatusPublished,
+	}
+
+	repo := &auditRepoStub{}
+	audit := NewAuditService(
+		repo,
+		userRepoStub{"owner": ownerID, "admin": adminID},
+		organizationRepoStub{responsible: map[entity.UserID][]entity.OrganizationID{ownerID: {organizationID}}},
+		[]string{"admin"},
+		zap.NewNop(),
+	)
+
+	bid := &entity.Bid{
+		ID:       "7d6c5b4a-3f2e-4d1c-8b0a-9f8e7d6c5b4a",
+		TenderID: tender.ID,
+		AuthorID: entity.BidAuthorId(authorID),
+		Offer:    &entity.BidOffer{Price: entity.Money{Amount: 100000, Currency: "RUB"}},
+	}
+
+	if err := audit.recordBid(context.Background(), tender, bid.ID, entity.AuditActionCreate, authorID, nil, bid); err != nil {
+		t.Fatalf("recordBid() error = %v", err)
+	}
+
+	if got := repo.entries[0]; got.TenderID != tender.ID || got.OrganizationID != organizationID {
+		t.Fatalf("recorded entry is for tender %q of %q, want %q of %q", got.TenderID, got.OrganizationID, tender.ID, organizationID)
+	}
+
+	tests := []struct {
+		name     string
+		userName string
+		want     bool
+	}{
+		{name: "owner", userName: "owner", want: true},
+		{name: "admin", userName: "admin", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := &entity.AuditFilter{OrganizationID: organizationID}
+
+			if _, err := audit.List(context.Background(), filter, &entity.RequestLimitOffset{}, tt.userName); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			if repo.filter.HideSealedBids != tt.want {
+				t.Errorf("HideSealedBids = %v, want %v", repo.filter.HideSealedBids, tt.want)
+			}
+		})
+	}
+}
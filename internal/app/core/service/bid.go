@@ -3,13 +3,17 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
 
 	"avito2024/internal/app/core/entity"
 	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
 )
 
 type BidService struct {
@@ -17,6 +21,12 @@ type BidService struct {
 	organizationRepo port.OrganizationRepo
 	bidRepo          port.BidRepo
 	tenderRepo       port.TenderRepo
+	tenderService    *TenderService
+	audit            *AuditService
+	transactor       port.Transactor
+	metrics          port.Metrics
+	events           port.EventPublisher
+	logger           *zap.Logger
 }
 
 func NewBidService(
@@ -24,20 +34,41 @@ func NewBidService(
 	userRepo port.UserRepo,
 	organizationRepo port.OrganizationRepo,
 	tenderRepo port.TenderRepo,
+	tenderService *TenderService,
+	audit *AuditService,
+	transactor port.Transactor,
+	metrics port.Metrics,
+	events port.EventPublisher,
+	logger *zap.Logger,
 ) *BidService {
 	return &BidService{
 		bidRepo:          bidRepo,
 		userRepo:         userRepo,
 		organizationRepo: organizationRepo,
 		tenderRepo:       tenderRepo,
+		tenderService:    tenderService,
+		audit:            audit,
+		transactor:       transactor,
+		metrics:          metrics,
+		events:           events,
+		logger:           logger.Named("bid"),
 	}
 }
 
-func (r *BidService) Create(ctx context.Context, bid *entity.Bid) error {
+func (r *BidService) Create(ctx context.Context, bid *entity.Bid) (err error) {
+	ctx, span := tracer.Start(ctx, "BidService.Create")
+	defer func() { tracing.End(span, err) }()
+
 	bid.ID = entity.BidId(uuid.NewString())
 	bid.Status = entity.BidStatus(Created)
 	bid.CreatedAt = time.Now()
 
+	if bid.Offer != nil {
+		if err := validateOffer(bid.Offer, bid.CreatedAt); err != nil {
+			return err
+		}
+	}
+
 	switch bid.AuthorType {
 	case entity.BidAuthorOrganization:
 		exists := r.organizationRepo.Exists(ctx, entity.OrganizationID(bid.AuthorID))
@@ -62,20 +93,67 @@ func (r *BidService) Create(ctx context.Context, bid *entity.Bid) error {
 		return ErrTenderNotFound
 	}
 
-	if err := r.bidRepo.Create(ctx, bid); err != nil {
-		return fmt.Errorf("create bid: %w", err)
+	if tender.BiddingClosed(bid.CreatedAt) {
+		return ErrBiddingClosed
+	}
+
+	if err := r.tenderService.requireInvitedAuthor(ctx, tender, bid); err != nil {
+		return err
+	}
+
+	if err := r.checkLot(ctx, bid); err != nil {
+		return err
 	}
 
+	if err := r.warnAboutBudget(ctx, tender, bid, false); err != nil {
+		return err
+	}
+
+	// A bid made for an organization does not name the user behind it.
+	var actorID entity.UserID
+	if bid.AuthorType == entity.BidAuthorUser {
+		actorID = entity.UserID(bid.AuthorID)
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.bidRepo.Create(ctx, bid); err != nil {
+			return fmt.Errorf("create bid: %w", err)
+		}
+
+		return r.audit.recordBid(ctx, tender, bid.ID, entity.AuditActionCreate, actorID, nil, bid)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.metrics.BidCreated()
+	r.log(ctx).Info("bid created",
+		zap.String("bidId", string(bid.ID)),
+		zap.String("tenderId", string(bid.TenderID)),
+	)
+
+	r.events.Publish(ctx, &entity.Event{
+		Type:       entity.EventBidCreated,
+		TenderID:   bid.TenderID,
+		BidID:      bid.ID,
+		ActorID:    actorID,
+		OccurredAt: bid.CreatedAt,
+	})
+
 	return nil
 }
 
-func (r *BidService) ListBidsMy(ctx context.Context, userName string) ([]*entity.Bid, error) {
+// ListBidsMy lists the bids of the user, the bids of archived tenders only with includeArchived.
+func (r *BidService) ListBidsMy(ctx context.Context, userName string, includeArchived bool) (_ []*entity.Bid, err error) {
+	ctx, span := tracer.Start(ctx, "BidService.ListBidsMy")
+	defer func() { tracing.End(span, err) }()
+
 	userID, err := r.userRepo.FindUserId(ctx, userName)
 	if err != nil {
 		return nil, ErrUserNotExists
 	}
 
-	bids, err := r.bidRepo.ReadMyBids(ctx, userID)
+	bids, err := r.bidRepo.ReadMyBids(ctx, userID, includeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("list bids: %w", err)
 	}
@@ -83,44 +161,89 @@ func (r *BidService) ListBidsMy(ctx context.Context, userName string) ([]*entity
 	return bids, nil
 }
 
-func (r *BidService) ListTenderBids(ctx context.Context, tenderID entity.TenderID, userName string) ([]*entity.Bid, error) {
-	userID, err := r.userRepo.FindUserId(ctx, userName)
-	if err != nil {
-		return nil, ErrUserNotExists
-	}
+func (r *BidService) ListTenderBids(ctx context.Context, tenderID entity.TenderID, userName string) (_ []*entity.Bid, err error) {
+	ctx, span := tracer.Start(ctx, "BidService.ListTenderBids")
+	defer func() { tracing.End(span, err) }()
 
-	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	tender, _, err := r.authorizeReviewer(ctx, tenderID, userName)
 	if err != nil {
 		return nil, err
 	}
 
-	if tender == nil {
+	bids, err := r.bidRepo.ReadTenderBids(ctx, tenderID)
+	if err != nil {
 		return nil, ErrTenderOrBidNotFound
 	}
 
-	orgIDs, err := r.organizationRepo.ReadResponsibleUserOrganization(ctx, userID)
-	if err != nil {
+	if tender.BidsSealed(time.Now()) {
+		for i, bid := range bids {
+			bids[i] = bid.Redacted()
+		}
+
+		return bids, nil
+	}
+
+	if err := r.warnAboutBudgets(ctx, tender, bids); err != nil {
 		return nil, err
 	}
 
-	tenderOrgID := tender.OrganizationID
+	return bids, nil
+}
 
-	if !slices.Contains(orgIDs, tenderOrgID) {
-		return nil, ErrNotEnoughRights
+// ListLotBids is ListTenderBids narrowed to the bids for one lot.
+func (r *BidService) ListLotBids(ctx context.Context, tenderID entity.TenderID, lotID entity.LotID, userName string) ([]*entity.Bid, error) {
+	bids, err := r.ListTenderBids(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
 	}
 
-	bids, err := r.bidRepo.ReadTenderBids(ctx, tenderID)
-	if err != nil {
-		return nil, ErrTenderOrBidNotFound
+	lotBids := make([]*entity.Bid, 0, len(bids))
+	for _, bid := range bids {
+		if bid.LotID == lotID {
+			lotBids = append(lotBids, bid)
+		}
 	}
 
-	return bids, nil
+	return lotBids, nil
 }
 
-func (r *BidService) SubmitDecision(ctx context.Context, bidID entity.BidId, decision string, userName string) (*entity.Bid, error) {
+// authorizeReviewer lets the responsible users of the tender organization review its bids.
+func (r *BidService) authorizeReviewer(ctx context.Context, tenderID entity.TenderID, userName string) (*entity.Tender, entity.UserID, error) {
 	userID, err := r.userRepo.FindUserId(ctx, userName)
 	if err != nil {
-		return nil, ErrUserNotExists
+		return nil, "", ErrUserNotExists
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if tender == nil {
+		return nil, "", ErrTenderOrBidNotFound
+	}
+
+	orgIDs, err := r.organizationRepo.ReadResponsibleUserOrganization(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !slices.Contains(orgIDs, tender.OrganizationID) {
+		return nil, "", ErrNotEnoughRights
+	}
+
+	return tender, userID, nil
+}
+
+// SubmitDecision records the decision of the tender organization on the bid.
+// Approving a bid awards its lot, or closes the whole tender for a tender without lots.
+func (r *BidService) SubmitDecision(ctx context.Context, bidID entity.BidId, decision string, userName string) (_ *entity.Bid, err error) {
+	ctx, span := tracer.Start(ctx, "BidService.SubmitDecision")
+	defer func() { tracing.End(span, err) }()
+
+	reviewDecision := entity.BidReviewDecision(decision)
+	if reviewDecision != entity.BidReviewApproved && reviewDecision != entity.BidReviewRejected {
+		return nil, fmt.Errorf("%w: decision must be %s or %s", ErrWrongInputFormat, entity.BidReviewApproved, entity.BidReviewRejected)
 	}
 
 	bid, err := r.bidRepo.ReadBidByID(ctx, bidID)
@@ -132,34 +255,119 @@ func (r *BidService) SubmitDecision(ctx context.Context, bidID entity.BidId, dec
 		return nil, ErrBidNotFound
 	}
 
-	users, err := r.bidRepo.ReadBidResponsibleUsers(ctx, []entity.BidId{bidID})
+	tender, userID, err := r.authorizeReviewer(ctx, bid.TenderID, userName)
 	if err != nil {
-		return nil, ErrBidNotFound
+		return nil, err
 	}
 
-	if !slices.Contains(users, entity.UserID(userID)) {
-		return nil, ErrNotEnoughRights
+	if tender.BidsSealed(time.Now()) {
+		return nil, ErrBidsSealed
 	}
 
-	tender, err := r.tenderRepo.Read(ctx, bid.TenderID)
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if reviewDecision == entity.BidReviewApproved {
+			if err := r.award(ctx, tender, bid, userID); err != nil {
+				return err
+			}
+		}
+
+		decided := struct {
+			Decision entity.BidReviewDecision `json:"decision"`
+		}{reviewDecision}
+
+		return r.audit.recordBid(ctx, tender, bidID, entity.AuditActionDecision, userID, nil, decided)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	r.metrics.BidDecision(reviewDecision)
+	r.log(ctx).Info("bid decision submitted",
+		zap.String("bidId", string(bidID)),
+		zap.String("decision", decision),
+	)
+
+	eventType := entity.EventBidApproved
+	if reviewDecision == entity.BidReviewRejected {
+		eventType = entity.EventBidRejected
+	}
+
+	r.events.Publish(ctx, &entity.Event{
+		Type:       eventType,
+		TenderID:   tender.ID,
+		BidID:      bidID,
+		ActorID:    userID,
+		OccurredAt: time.Now(),
+	})
+
+	return bid, nil
+}
+
+// award settles the lot of the bid or closes its tender, which must still be
+// published. The tender passed in may come from the cache, so it is locked and
+// read again before its status is checked.
+func (r *BidService) award(ctx context.Context, tender *entity.Tender, bid *entity.Bid, actorID entity.UserID) error {
+	tender, err := r.tenderRepo.Lock(ctx, tender.ID)
+	if err != nil {
+		return err
+	}
+
 	if tender == nil {
-		return nil, ErrTenderNotFound
+		return ErrTenderOrBidNotFound
 	}
 
-	switch entity.BidReviewDecision(decision) {
-	case entity.BidReviewApproved:
-		tender.Status = entity.TenderStatusClosed
-		r.tenderRepo.UpdateStatus(ctx, tender.ID, tender.Status)
+	if tender.Status != entity.TenderStatusPublished {
+		return fmt.Errorf("%w: tender is %s", ErrTenderNotPublished, tender.Status)
 	}
 
-	return bid, nil
+	if bid.LotID != "" {
+		_, err := r.tenderService.settleLot(ctx, tender, bid.LotID, entity.LotStatusAwarded, bid.ID, actorID)
+		return err
+	}
+
+	hasLots, err := r.tenderService.hasLots(ctx, tender.ID)
+	if err != nil {
+		return err
+	}
+
+	if hasLots {
+		return fmt.Errorf("%w: the bid is not for a lot of the tender", ErrWrongInputFormat)
+	}
+
+	return r.tenderService.changeStatus(ctx, tender, entity.TenderStatusClosed, actorID)
 }
 
-func (r *BidService) Status(ctx context.Context, bidID entity.BidId, userName string) (entity.BidStatus, error) {
+// checkLot requires a bid on a tender with lots to be for one of its open lots.
+func (r *BidService) checkLot(ctx context.Context, bid *entity.Bid) error {
+	if bid.LotID == "" {
+		hasLots, err := r.tenderService.hasLots(ctx, bid.TenderID)
+		if err != nil {
+			return err
+		}
+
+		if hasLots {
+			return fmt.Errorf("%w: lotId is required, the tender is split into lots", ErrWrongInputFormat)
+		}
+
+		return nil
+	}
+
+	lot, err := r.tenderService.lot(ctx, bid.TenderID, bid.LotID)
+	if err != nil {
+		return err
+	}
+
+	if lot.Status != entity.LotStatusOpen {
+		return fmt.Errorf("%w: lot is %s", ErrLotSettled, lot.Status)
+	}
+
+	return nil
+}
+
+func (r *BidService) Status(ctx context.Context, bidID entity.BidId, userName string) (_ entity.BidStatus, err error) {
+	ctx, span := tracer.Start(ctx, "BidService.Status")
+	defer func() { tracing.End(span, err) }()
+
 	userID, err := r.userRepo.FindUserId(ctx, userName)
 	if err != nil {
 		return "", ErrUserNotExists
@@ -184,3 +392,278 @@ func (r *BidService) Status(ctx context.Context, bidID entity.BidId, userName st
 	return bid.Status, nil
 
 }
+
+// Edit changes the name, description or offer of the bid as a new bid version.
+// Only the author of the bid, or a responsible user of the authoring organization, may edit it.
+func (r *BidService) Edit(ctx context.Context, bidID entity.BidId, update *entity.BidUpdate, userName string) (_ *entity.Bid, err error) {
+	ctx, span := tracer.Start(ctx, "BidService.Edit")
+	defer func() { tracing.End(span, err) }()
+
+	if update.Offer != nil {
+		if err := validateOffer(update.Offer, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	bid, err := r.bidRepo.ReadBidByID(ctx, bidID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bid == nil {
+		return nil, ErrBidNotFound
+	}
+
+	author, err := isBidAuthor(ctx, r.organizationRepo, bid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !author {
+		return nil, ErrNotEnoughRights
+	}
+
+	tender, err := r.requireBiddingOpen(ctx, bid.TenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *bid
+	bid.Apply(update)
+
+	if err := r.warnAboutBudget(ctx, tender, bid, false); err != nil {
+		return nil, err
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		version, err := r.bidRepo.Update(ctx, bid)
+		if err != nil {
+			return fmt.Errorf("update bid: %w", err)
+		}
+
+		bid.Version = version
+
+		return r.audit.recordBid(ctx, tender, bidID, entity.AuditActionEdit, userID, &before, bid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log(ctx).Info("bid edited",
+		zap.String("bidId", string(bidID)),
+		zap.Int32("version", int32(bid.Version)),
+	)
+
+	return bid, nil
+}
+
+// Delete soft deletes the bid while bidding on its tender is open. Only its
+// author may delete it, and restore it until the retention period ends.
+func (r *BidService) Delete(ctx context.Context, bidID entity.BidId, userName string) (err error) {
+	ctx, span := tracer.Start(ctx, "BidService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	bid, err := r.bidRepo.ReadBidByID(ctx, bidID)
+	if err != nil {
+		return err
+	}
+
+	if bid == nil {
+		return ErrBidNotFound
+	}
+
+	userID, tender, err := r.authorizeRemoval(ctx, bid, userName)
+	if err != nil {
+		return err
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := r.bidRepo.Delete(ctx, bidID, time.Now())
+		if err != nil {
+			return fmt.Errorf("delete bid: %w", err)
+		}
+
+		// Deleted concurrently.
+		if !deleted {
+			return ErrBidNotFound
+		}
+
+		return r.audit.recordBid(ctx, tender, bidID, entity.AuditActionDelete, userID, bid, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.log(ctx).Info("bid deleted", zap.String("bidId", string(bidID)))
+
+	return nil
+}
+
+// Restore undoes Delete while bidding on the tender is still open.
+func (r *BidService) Restore(ctx context.Context, bidID entity.BidId, userName string) (_ *entity.Bid, err error) {
+	ctx, span := tracer.Start(ctx, "BidService.Restore")
+	defer func() { tracing.End(span, err) }()
+
+	bid, err := r.bidRepo.ReadDeleted(ctx, bidID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bid == nil {
+		return nil, ErrBidNotFound
+	}
+
+	userID, tender, err := r.authorizeRemoval(ctx, bid, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		restored, err := r.bidRepo.Restore(ctx, bidID)
+		if err != nil {
+			return fmt.Errorf("restore bid: %w", err)
+		}
+
+		if !restored {
+			return ErrBidNotFound
+		}
+
+		return r.audit.recordBid(ctx, tender, bidID, entity.AuditActionRestore, userID, nil, bid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log(ctx).Info("bid restored", zap.String("bidId", string(bidID)))
+
+	return bid, nil
+}
+
+// authorizeRemoval checks that the user authored the bid and that its tender still takes bids.
+func (r *BidService) authorizeRemoval(ctx context.Context, bid *entity.Bid, userName string) (entity.UserID, *entity.Tender, error) {
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return "", nil, ErrUserNotExists
+	}
+
+	author, err := isBidAuthor(ctx, r.organizationRepo, bid, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !author {
+		return "", nil, ErrNotEnoughRights
+	}
+
+	tender, err := r.requireBiddingOpen(ctx, bid.TenderID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return userID, tender, nil
+}
+
+// requireBiddingOpen returns the tender unless the deadline for bids on it has passed.
+func (r *BidService) requireBiddingOpen(ctx context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender == nil {
+		return nil, ErrTenderNotFound
+	}
+
+	if tender.BiddingClosed(time.Now()) {
+		return nil, ErrBiddingClosed
+	}
+
+	return tender, nil
+}
+
+// isBidAuthor reports whether the user wrote the bid, directly or for their organization.
+func isBidAuthor(ctx context.Context, organizationRepo port.OrganizationRepo, bid *entity.Bid, userID entity.UserID) (bool, error) {
+	switch bid.AuthorType {
+	case entity.BidAuthorUser:
+		return entity.UserID(bid.AuthorID) == userID, nil
+	case entity.BidAuthorOrganization:
+		users, err := organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{entity.OrganizationID(bid.AuthorID)})
+		if err != nil {
+			return false, err
+		}
+
+		return slices.Contains(users, userID), nil
+	default:
+		return false, nil
+	}
+}
+
+// validateOffer checks that the offer is complete and that its line items add up to the price.
+func validateOffer(offer *entity.BidOffer, now time.Time) error {
+	if !offer.Price.Currency.Valid() {
+		return fmt.Errorf("%w: price currency must be an ISO 4217 code, got %q", ErrWrongInputFormat, offer.Price.Currency)
+	}
+
+	if offer.Price.Amount <= 0 {
+		return fmt.Errorf("%w: price must be positive", ErrWrongInputFormat)
+	}
+
+	if offer.DeliveryDays <= 0 {
+		return fmt.Errorf("%w: deliveryDays must be positive", ErrWrongInputFormat)
+	}
+
+	if !offer.ValidUntil.After(now) {
+		return fmt.Errorf("%w: validUntil must be in the future", ErrWrongInputFormat)
+	}
+
+	if len(offer.Items) == 0 {
+		return nil
+	}
+
+	var total int64
+
+	for i, item := range offer.Items {
+		if item.Name == "" {
+			return fmt.Errorf("%w: items[%d]: name is required", ErrWrongInputFormat, i)
+		}
+
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: items[%d]: quantity must be positive", ErrWrongInputFormat, i)
+		}
+
+		if item.UnitPrice.Amount <= 0 {
+			return fmt.Errorf("%w: items[%d]: unitPrice must be positive", ErrWrongInputFormat, i)
+		}
+
+		if item.UnitPrice.Currency != offer.Price.Currency {
+			return fmt.Errorf("%w: items[%d]: unitPrice currency must match the price currency", ErrWrongInputFormat, i)
+		}
+
+		cost, ok := item.UnitPrice.Mul(item.Quantity)
+		if !ok || cost.Amount > math.MaxInt64-total {
+			return fmt.Errorf("%w: items[%d]: amount is too large", ErrWrongInputFormat, i)
+		}
+
+		total += cost.Amount
+	}
+
+	if total != offer.Price.Amount {
+		return fmt.Errorf("%w: items add up to %s, not to the price %s", ErrWrongInputFormat,
+			entity.Money{Amount: total, Currency: offer.Price.Currency}, offer.Price)
+	}
+
+	return nil
+}
+
+func (r *BidService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+// The users and organizations of the services built by newFixture: owner is
+// responsible for the tender organization, supplier for a bidding organization
+// and author bids on their own.
+const (
+	testOwnerID        = entity.UserID("6f1c3a52-0a3e-4c1f-9d4b-1b2e3c4d5e6f")
+	testAuthorID       = entity.UserID("0e9d8c7b-6a5f-4e3d-8c2b-1a0f9e8d7c6b")
+	testSupplierID     = entity.UserID("5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d")
+	testStrangerID     = entity.UserID("2b3c4d5e-6f7a-4b8c-9d0e-1f2a3b4c5d6e")
+	testOrganizationID = entity.OrganizationID("9b8a7c6d-5e4f-4321-9fed-cba987654321")
+	testSupplierOrgID  = entity.OrganizationID("1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d")
+	testTenderID       = entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a")
+)
+
+type bidRepoStub struct {
+	port.BidRepo
+	bids map[entity.BidId]*entity.Bid
+}
+
+func (r *bidRepoStub) Create(_ context.Context, bid *entity.Bid) error {
+	created := *bid
+	r.bids[bid.ID] = &created
+
+	return nil
+}
+
+func (r *bidRepoStub) ReadTenderBids(_ context.Context, tenderID entity.TenderID) ([]*entity.Bid, error) {
+	var bids []*entity.Bid
+
+	for _, bid := range r.bids {
+		if bid.TenderID == tenderID {
+			read := *bid
+			bids = append(bids, &read)
+		}
+	}
+
+	return bids, nil
+}
+
+func (r *bidRepoStub) ReadBidByID(_ context.Context, bidID entity.BidId) (*entity.Bid, error) {
+	bid, ok := r.bids[bidID]
+	if !ok {
+		return nil, nil
+	}
+
+	read := *bid
+
+	return &read, nil
+}
+
+func (r *bidRepoStub) Update(_ context.Context, bid *entity.Bid) (entity.BidVersion, error) {
+	updated := *bid
+	updated.Version++
+	r.bids[bid.ID] = &updated
+
+	return updated.Version, nil
+}
+
+type lotRepoStub struct {
+	port.LotRepo
+	lots map[entity.LotID]*entity.Lot
+}
+
+func (r *lotRepoStub) Read(_ context.Context, lotID entity.LotID) (*entity.Lot, error) {
+	lot, ok := r.lots[lotID]
+	if !ok {
+		return nil, nil
+	}
+
+	read := *lot
+
+	return &read, nil
+}
+
+func (r *lotRepoStub) List(_ context.Context, tenderID entity.TenderID) ([]*entity.Lot, error) {
+	var lots []*entity.Lot
+
+	for _, lot := range r.lots {
+		if lot.TenderID == tenderID {
+			read := *lot
+			lots = append(lots, &read)
+		}
+	}
+
+	return lots, nil
+}
+
+func (r *lotRepoStub) Lock(ctx context.Context, tenderID entity.TenderID) ([]*entity.Lot, error) {
+	return r.List(ctx, tenderID)
+}
+
+func (r *lotRepoStub) UpdateStatus(_ context.Context, lotID entity.LotID, status entity.LotStatus, bidID entity.BidId) error {
+	r.lots[lotID].Status = status
+	r.lots[lotID].AwardedBidID = bidID
+
+	return nil
+}
+
+// fixture is the tender and bid services over in-memory repos holding the
+// published tender testTenderID of testOrganizationID.
+type fixture struct {
+	tenders       *tenderRepoStub
+	bids          *bidRepoStub
+	lots          *lotRepoStub
+	invitations   *invitationRepoStub
+	events        *eventPublisherStub
+	tenderService *TenderService
+	bidService    *BidService
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		tenders: &tenderRepoStub{tenders: map[entity.TenderID]*entity.Tender{
+			testTenderID: {
+				ID:             testTenderID,
+				Name:           "roads",
+				ServiceType:    "Construction",
+				Status:         entity.TenderStatusPublished,
+				OrganizationID: testOrganizationID,
+				Version:        1,
+			},
+		}},
+		bids:        &bidRepoStub{bids: make(map[entity.BidId]*entity.Bid)},
+		lots:        &lotRepoStub{lots: make(map[entity.LotID]*entity.Lot)},
+		invitations: &invitationRepoStub{invitations: make(map[entity.InvitationID]*entity.Invitation)},
+		events:      &eventPublisherStub{},
+	}
+
+	users := userRepoStub{"owner": testOwnerID, "author": testAuthorID, "supplier": testSupplierID, "stranger": testStrangerID}
+	organizations := organizationRepoStub{responsible: map[entity.UserID][]entity.OrganizationID{
+		testOwnerID:    {testOrganizationID},
+		testSupplierID: {testSupplierOrgID},
+	}}
+	logger := zap.NewNop()
+	audit := NewAuditService(&auditRepoStub{}, users, organizations, nil, logger)
+
+	f.tenderService = NewTenderService(
+		f.tenders,
+		f.lots,
+		f.invitations,
+		nil,
+		nil,
+		audit,
+		users,
+		organizations,
+		transactorStub{},
+		metricsStub{},
+		f.events,
+		logger,
+	)
+	f.bidService = NewBidService(
+		f.bids,
+		users,
+		organizations,
+		f.tenders,
+		f.tenderService,
+		audit,
+		transactorStub{},
+		metricsStub{},
+		f.events,
+		logger,
+	)
+
+	return f
+}
+
+// offer is a valid offer of two line items adding up to its price.
+func offer() *entity.BidOffer {
+	return &entity.BidOffer{
+		Price:        entity.Money{Amount: 70000, Currency: "RUB"},
+		DeliveryDays: 30,
+		ValidUntil:   time.Now().AddDate(0, 1, 0),
+		Items: []entity.BidLineItem{
+			{Name: "asphalt", Quantity: 10, Unit: "t", UnitPrice: entity.Money{Amount: 5000, Currency: "RUB"}},
+			{Name: "labour", Quantity: 1, UnitPrice: entity.Money{Amount: 20000, Currency: "RUB"}},
+		},
+	}
+}
+
+func TestBidCreateValidatesOffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(offer *entity.BidOffer) *entity.BidOffer
+		wantErr error
+	}{
+		{name: "valid offer", change: func(offer *entity.BidOffer) *entity.BidOffer { return offer }},
+		{name: "no offer", change: func(*entity.BidOffer) *entity.BidOffer { return nil }},
+		{
+			name: "items not adding up to the price",
+			change: func(offer *entity.BidOffer) *entity.BidOffer {
+				offer.Price.Amount++
+				return offer
+			},
+			wantErr: ErrWrongInputFormat,
+		},
+		{
+			name: "item in another currency",
+			change: func(offer *entity.BidOffer) *entity.BidOffer {
+				offer.Items[1].UnitPrice.Currency = "USD"
+				return offer
+			},
+			wantErr: ErrWrongInputFormat,
+		},
+		{
+			name: "unknown currency",
+			change: func(offer *entity.BidOffer) *entity.BidOffer {
+				offer.Price.Currency = "RUR"
+				return offer
+			},
+			wantErr: ErrWrongInputFormat,
+		},
+		{
+			name: "expired offer",
+			change: func(offer *entity.BidOffer) *entity.BidOffer {
+				offer.ValidUntil = time.Now().Add(-time.Minute)
+				return offer
+			},
+			wantErr: ErrWrongInputFormat,
+		},
+		{
+			name: "no delivery time",
+			change: func(offer *entity.BidOffer) *entity.BidOffer {
+				offer.DeliveryDays = 0
+				return offer
+			},
+			wantErr: ErrWrongInputFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			bid := &entity.Bid{
+				Name:       "offer",
+				TenderID:   testTenderID,
+				AuthorType: entity.BidAuthorUser,
+				AuthorID:   entity.BidAuthorId(testAuthorID),
+				Offer:      tt.change(offer()),
+			}
+
+			err := f.bidService.Create(context.Background(), bid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantStored := 0
+			if tt.wantErr == nil {
+				wantStored = 1
+			}
+
+			if len(f.bids.bids) != wantStored {
+				t.Errorf("stored bids = %d, want %d", len(f.bids.bids), wantStored)
+			}
+		})
+	}
+}
+
+func TestBidEdit(t *testing.T) {
+	const bidID = entity.BidId("7d6c5b4a-3f2e-4d1c-8b0a-9f8e7d6c5b4a")
+
+	expired := offer()
+	expired.ValidUntil = time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		authorType entity.BidAuthorType
+		authorID   entity.BidAuthorId
+		userName   string
+		offer      *entity.BidOffer
+		deadline   time.Duration
+		wantErr    error
+	}{
+		{
+			name:       "by the author",
+			authorType: entity.BidAuthorUser,
+			authorID:   entity.BidAuthorId(testAuthorID),
+			userName:   "author",
+			offer:      offer(),
+		},
+		{
+			name:       "by a responsible user of the authoring organization",
+			authorType: entity.BidAuthorOrganization,
+			authorID:   entity.BidAuthorId(testSupplierOrgID),
+			userName:   "supplier",
+			offer:      offer(),
+		},
+		{
+			name:       "by another user",
+			authorType: entity.BidAuthorUser,
+			authorID:   entity.BidAuthorId(testAuthorID),
+			userName:   "stranger",
+			wantErr:    ErrNotEnoughRights,
+		},
+		{
+			name:       "by the tender owner",
+			authorType: entity.BidAuthorOrganization,
+			authorID:   entity.BidAuthorId(testSupplierOrgID),
+			userName:   "owner",
+			wantErr:    ErrNotEnoughRights,
+		},
+		{
+			name:       "by an unknown user",
+			authorType: entity.BidAuthorUser,
+			authorID:   entity.BidAuthorId(testAuthorID),
+			userName:   "nobody",
+			wantErr:    ErrUserNotExists,
+		},
+		{
+			name:       "with an invalid offer",
+			authorType: entity.BidAuthorUser,
+			authorID:   entity.BidAuthorId(testAuthorID),
+			userName:   "author",
+			offer:      expired,
+			wantErr:    ErrWrongInputFormat,
+		},
+		{
+			name:       "after the deadline",
+			authorType: entity.BidAuthorUser,
+			authorID:   entity.BidAuthorId(testAuthorID),
+			userName:   "author",
+			deadline:   -time.Minute,
+			wantErr:    ErrBiddingClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.bids.bids[bidID] = &entity.Bid{
+				ID:         bidID,
+				Name:       "offer",
+				TenderID:   testTenderID,
+				AuthorType: tt.authorType,
+				AuthorID:   tt.authorID,
+				Version:    1,
+			}
+
+			if tt.deadline != 0 {
+				deadline := time.Now().Add(tt.deadline)
+				f.tenders.tenders[testTenderID].BidDeadline = &deadline
+			}
+
+			bid, err := f.bidService.Edit(context.Background(), bidID, &entity.BidUpdate{Name: "better offer", Offer: tt.offer}, tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Edit() error = %v, want %v", err, tt.wantErr)
+			}
+
+			stored := f.bids.bids[bidID]
+
+			if tt.wantErr != nil {
+				if stored.Version != 1 || stored.Name != "offer" {
+					t.Errorf("stored bid = %+v, want it unchanged", stored)
+				}
+
+				return
+			}
+
+			if bid.Version != 2 || stored.Name != "better offer" || stored.Offer == nil {
+				t.Errorf("stored bid = %+v, want version 2 with the new name and offer", stored)
+			}
+		})
+	}
+}
+
+func TestListTenderBidsSealed(t *testing.T) {
+	tests := []struct {
+		name       string
+		sealed     bool
+		deadline   time.Duration
+		status     entity.TenderStatus
+		userName   string
+		wantErr    error
+		wantSealed bool
+	}{
+		{name: "open tender", status: entity.TenderStatusPublished, userName: "owner"},
+		{name: "sealed before the deadline", sealed: true, deadline: time.Hour, status: entity.TenderStatusPublished, userName: "owner", wantSealed: true},
+		{name: "sealed without a deadline", sealed: true, status: entity.TenderStatusPublished, userName: "owner", wantSealed: true},
+		{name: "sealed after the deadline", sealed: true, deadline: -time.Minute, status: entity.TenderStatusPublished, userName: "owner"},
+		{name: "sealed and closed", sealed: true, deadline: time.Hour, status: entity.TenderStatusClosed, userName: "owner"},
+		{name: "by a user outside the tender organization", status: entity.TenderStatusPublished, userName: "supplier", wantErr: ErrNotEnoughRights},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			tender := f.tenders.tenders[testTenderID]
+			tender.Sealed = tt.sealed
+			tender.Status = tt.status
+
+			if tt.deadline != 0 {
+				deadline := time.Now().Add(tt.deadline)
+				tender.BidDeadline = &deadline
+			}
+
+			f.bids.bids["b1"] = &entity.Bid{
+				ID:         "b1",
+				Name:       "offer",
+				TenderID:   testTenderID,
+				AuthorType: entity.BidAuthorUser,
+				AuthorID:   entity.BidAuthorId(testAuthorID),
+				Version:    1,
+				Offer:      offer(),
+			}
+
+			bids, err := f.bidService.ListTenderBids(context.Background(), testTenderID, tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ListTenderBids() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if len(bids) != 1 {
+				t.Fatalf("bids = %d, want 1", len(bids))
+			}
+
+			bid := bids[0]
+			if tt.wantSealed {
+				if !bid.Sealed || bid.Name != "" || bid.AuthorID != "" || bid.Offer != nil {
+					t.Errorf("bid = %+v, want only its id and status before the deadline", bid)
+				}
+
+				return
+			}
+
+			if bid.Sealed || bid.Name != "offer" || bid.Offer == nil {
+				t.Errorf("bid = %+v, want its contents", bid)
+			}
+		})
+	}
+}
+
+func TestSubmitDecisionSealed(t *testing.T) {
+	tests := []struct {
+		name       string
+		deadline   time.Duration
+		wantErr    error
+		wantStatus entity.TenderStatus
+	}{
+		{name: "before the deadline", deadline: time.Hour, wantErr: ErrBidsSealed, wantStatus: entity.TenderStatusPublished},
+		{name: "after the deadline", deadline: -time.Minute, wantStatus: entity.TenderStatusClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			deadline := time.Now().Add(tt.deadline)
+			tender := f.tenders.tenders[testTenderID]
+			tender.Sealed = true
+			tender.BidDeadline = &deadline
+
+			f.bids.bids["b1"] = &entity.Bid{
+				ID:         "b1",
+				TenderID:   testTenderID,
+				AuthorType: entity.BidAuthorUser,
+				AuthorID:   entity.BidAuthorId(testAuthorID),
+			}
+
+			_, err := f.bidService.SubmitDecision(context.Background(), "b1", string(entity.BidReviewApproved), "owner")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SubmitDecision() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if status := f.tenders.tenders[testTenderID].Status; status != tt.wantStatus {
+				t.Errorf("tender status = %s, want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+)
+
+func validateBudget(budget *entity.Money) error {
+	if !budget.Currency.Valid() {
+		return fmt.Errorf("%w: budget currency must be an ISO 4217 code, got %q", ErrWrongInputFormat, budget.Currency)
+	}
+
+	if budget.Amount <= 0 {
+		return fmt.Errorf("%w: budget must be positive", ErrWrongInputFormat)
+	}
+
+	return nil
+}
+
+func validateListOptions(options *entity.TenderListOptions) error {
+	switch options.Sort {
+	case "", entity.TenderSortName, entity.TenderSortBudgetAsc, entity.TenderSortBudgetDesc:
+	default:
+		return fmt.Errorf("%w: sort must be %s, %s or %s", ErrWrongInputFormat,
+			entity.TenderSortName, entity.TenderSortBudgetAsc, entity.TenderSortBudgetDesc)
+	}
+
+	if options.Currency != "" && !options.Currency.Valid() {
+		return fmt.Errorf("%w: currency must be an ISO 4217 code, got %q", ErrWrongInputFormat, options.Currency)
+	}
+
+	if (options.BudgetMin != nil || options.BudgetMax != nil) && options.Currency == "" {
+		return fmt.Errorf("%w: budget bounds require a currency", ErrWrongInputFormat)
+	}
+
+	if options.BudgetMin != nil && options.BudgetMax != nil && *options.BudgetMin > *options.BudgetMax {
+		return fmt.Errorf("%w: budget_min is greater than budget_max", ErrWrongInputFormat)
+	}
+
+	return nil
+}
+
+// redactBudgets drops the hidden budgets of tenders the viewer does not own.
+func redactBudgets(tenders []*entity.Tender, viewer *entity.Viewer) {
+	for _, tender := range tenders {
+		if !tender.BudgetHidden {
+			continue
+		}
+
+		if viewer != nil && slices.Contains(viewer.Organizations, tender.OrganizationID) {
+			continue
+		}
+
+		tender.Budget = nil
+	}
+}
+
+// budgetWarning describes how the price goes over the budget, it is empty for
+// a price within the budget or without one.
+func budgetWarning(price entity.Money, budget *entity.Money) string {
+	switch {
+	case budget == nil:
+		return ""
+	case price.Currency != budget.Currency:
+		return fmt.Sprintf("price is in %s, the budget is in %s", price.Currency, budget.Currency)
+	case price.Amount > budget.Amount:
+		return fmt.Sprintf("price %s %s exceeds the budget of %s %s", price, price.Currency, budget, budget.Currency)
+	default:
+		return ""
+	}
+}
+
+// warnAboutBudget compares the offer of the bid to the budget of its lot, or of
+// the tender for a bid without a lot. Bids over the budget are still accepted,
+// the warning is for the author and the reviewers deciding on the bid. An
+// author is not told about a budget the tender hides.
+func (r *BidService) warnAboutBudget(ctx context.Context, tender *entity.Tender, bid *entity.Bid, reviewer bool) error {
+	if bid.Offer == nil {
+		return nil
+	}
+
+	budget := tender.Budget
+
+	if bid.LotID != "" {
+		lot, err := r.tenderService.lot(ctx, tender.ID, bid.LotID)
+		if err != nil {
+			return err
+		}
+
+		budget = &lot.Budget
+	} else if tender.BudgetHidden && !reviewer {
+		return nil
+	}
+
+	if warning := budgetWarning(bid.Offer.Price, budget); warning != "" {
+		bid.Warnings = append(bid.Warnings, warning)
+
+		r.log(ctx).Info("bid over budget",
+			zap.String("bidId", string(bid.ID)),
+			zap.String("tenderId", string(tender.ID)),
+			zap.String("warning", warning),
+		)
+	}
+
+	return nil
+}
+
+// warnAboutBudgets is warnAboutBudget for the bids of one tender, reading its lots once.
+func (r *BidService) warnAboutBudgets(ctx context.Context, tender *entity.Tender, bids []*entity.Bid) error {
+	lots, err := r.tenderService.lotRepo.List(ctx, tender.ID)
+	if err != nil {
+		return err
+	}
+
+	budgets := make(map[entity.LotID]*entity.Money, len(lots))
+	for _, lot := range lots {
+		budgets[lot.ID] = &lot.Budget
+	}
+
+	for _, bid := range bids {
+		if bid.Offer == nil {
+			continue
+		}
+
+		budget := tender.Budget
+		if bid.LotID != "" {
+			budget = budgets[bid.LotID]
+		}
+
+		if warning := budgetWarning(bid.Offer.Price, budget); warning != "" {
+			bid.Warnings = append(bid.Warnings, warning)
+		}
+	}
+
+	return nil
+}
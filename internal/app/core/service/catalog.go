@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+const (
+	maxServiceTypeNameLength        = 50
+	maxServiceTypeDescriptionLength = 500
+)
+
+// CatalogService manages the service types tenders and lots are classified by.
+// The catalog is changed by the admins named in the configuration and read by anyone.
+type CatalogService struct {
+	serviceTypeRepo port.ServiceTypeRepo
+	userRepo        port.UserRepo
+	admins          []string
+	logger          *zap.Logger
+}
+
+func NewCatalogService(
+	serviceTypeRepo port.ServiceTypeRepo,
+	userRepo port.UserRepo,
+	admins []string,
+	logger *zap.Logger,
+) *CatalogService {
+	return &CatalogService{
+		serviceTypeRepo: serviceTypeRepo,
+		userRepo:        userRepo,
+		admins:          admins,
+		logger:          logger.Named("catalog"),
+	}
+}
+
+func (r *CatalogService) ListServiceTypes(ctx context.Context) (_ []*entity.ServiceType, err error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.ListServiceTypes")
+	defer func() { tracing.End(span, err) }()
+
+	return r.serviceTypeRepo.List(ctx)
+}
+
+// CreateServiceType adds a type to the catalog, under an existing parent if one is given.
+func (r *CatalogService) CreateServiceType(ctx context.Context, serviceType *entity.ServiceType, userName string) (err error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.CreateServiceType")
+	defer func() { tracing.End(span, err) }()
+
+	if err := r.requireAdmin(ctx, userName); err != nil {
+		return err
+	}
+
+	serviceType.Name = entity.TenderServiceType(strings.TrimSpace(string(serviceType.Name)))
+
+	if err := validateServiceType(serviceType); err != nil {
+		return err
+	}
+
+	if serviceType.Parent != "" {
+		serviceTypes, err := r.serviceTypeRepo.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		if findServiceType(serviceTypes, serviceType.Parent) == nil {
+			return fmt.Errorf("%w: unknown parent %q", ErrWrongInputFormat, serviceType.Parent)
+		}
+	}
+
+	serviceType.CreatedAt = time.Now()
+
+	created, err := r.serviceTypeRepo.Create(ctx, serviceType)
+	if err != nil {
+		return fmt.Errorf("create service type: %w", err)
+	}
+
+	if !created {
+		return ErrServiceTypeExists
+	}
+
+	r.log(ctx).Info("service type created",
+		zap.String("name", string(serviceType.Name)),
+		zap.String("parent", string(serviceType.Parent)),
+	)
+
+	return nil
+}
+
+// UpdateServiceType moves a type under another parent, changes its description
+// or archives it. A type cannot be moved under itself or one of its subtypes.
+func (r *CatalogService) UpdateServiceType(
+	ctx context.Context,
+	name entity.TenderServiceType,
+	update *entity.ServiceTypeUpdate,
+	userName string,
+) (_ *entity.ServiceType, err error) {
+	ctx, span := tracer.Start(ctx, "CatalogService.UpdateServiceType")
+	defer func() { tracing.End(span, err) }()
+
+	if err := r.requireAdmin(ctx, userName); err != nil {
+		return nil, err
+	}
+
+	serviceTypes, err := r.serviceTypeRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceType := findServiceType(serviceTypes, name)
+	if serviceType == nil {
+		return nil, ErrServiceTypeNotFound
+	}
+
+	if update.Parent != nil && *update.Parent != "" {
+		if findServiceType(serviceTypes, *update.Parent) == nil {
+			return nil, fmt.Errorf("%w: unknown parent %q", ErrWrongInputFormat, *update.Parent)
+		}
+
+		if slices.Contains(descendants(serviceTypes, []entity.TenderServiceType{name}), *update.Parent) {
+			return nil, fmt.Errorf("%w: %q cannot be moved under itself or its subtype", ErrWrongInputFormat, name)
+		}
+	}
+
+	serviceType.Apply(update)
+
+	if err := validateServiceType(serviceType); err != nil {
+		return nil, err
+	}
+
+	if err := r.serviceTypeRepo.Update(ctx, serviceType); err != nil {
+		return nil, fmt.Errorf("update service type: %w", err)
+	}
+
+	r.log(ctx).Info("service type updated",
+		zap.String("name", string(name)),
+		zap.String("parent", string(serviceType.Parent)),
+		zap.Bool("archived", serviceType.Archived),
+	)
+
+	return serviceType, nil
+}
+
+// requireServiceType accepts a type for a new or edited tender or lot, it must
+// be in the catalog and not archived.
+func (r *CatalogService) requireServiceType(ctx context.Context, name entity.TenderServiceType) error {
+	serviceTypes, err := r.serviceTypeRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	serviceType := findServiceType(serviceTypes, name)
+	if serviceType == nil {
+		return fmt.Errorf("%w: unknown serviceType %q", ErrWrongInputFormat, name)
+	}
+
+	if serviceType.Archived {
+		return fmt.Errorf("%w: serviceType %q is archived", ErrWrongInputFormat, name)
+	}
+
+	return nil
+}
+
+// expand returns the types with all their subtypes, or the whole catalog for no types.
+func (r *CatalogService) expand(ctx context.Context, names []entity.TenderServiceType) ([]entity.TenderServiceType, error) {
+	serviceTypes, err := r.serviceTypeRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		all := make([]entity.TenderServiceType, 0, len(serviceTypes))
+		for _, serviceType := range serviceTypes {
+			all = append(all, serviceType.Name)
+		}
+
+		return all, nil
+	}
+
+	return descendants(serviceTypes, names), nil
+}
+
+func (r *CatalogService) requireAdmin(ctx context.Context, userName string) error {
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return ErrUserNotExists
+	}
+
+	if !slices.Contains(r.admins, userName) {
+		return ErrNotEnoughRights
+	}
+
+	return nil
+}
+
+func (r *CatalogService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
+
+// descendants returns roots followed by every type below them. Unknown roots
+// are kept, they simply have no subtypes.
+func descendants(serviceTypes []*entity.ServiceType, roots []entity.TenderServiceType) []entity.TenderServiceType {
+	children := make(map[entity.TenderServiceType][]entity.TenderServiceType, len(serviceTypes))
+	for _, serviceType := range serviceTypes {
+		if serviceType.Parent != "" {
+			children[serviceType.Parent] = append(children[serviceType.Parent], serviceType.Name)
+		}
+	}
+
+	var (
+		found = make([]entity.TenderServiceType, 0, len(roots))
+		seen  = make(map[entity.TenderServiceType]bool, len(serviceTypes))
+		queue = slices.Clone(roots)
+	)
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		found = append(found, name)
+		queue = append(queue, children[name]...)
+	}
+
+	return found
+}
+
+func findServiceType(serviceTypes []*entity.ServiceType, name entity.TenderServiceType) *entity.ServiceType {
+	i := slices.IndexFunc(serviceTypes, func(serviceType *entity.ServiceType) bool { return serviceType.Name == name })
+	if i < 0 {
+		return nil
+	}
+
+	return serviceTypes[i]
+}
+
+func validateServiceType(serviceType *entity.ServiceType) error {
+	name := string(serviceType.Name)
+
+	if name == "" || len(name) > maxServiceTypeNameLength || strings.Contains(name, ",") {
+		return fmt.Errorf("%w: name must be 1 to %d characters without commas", ErrWrongInputFormat, maxServiceTypeNameLength)
+	}
+
+	if serviceType.Parent == serviceType.Name {
+		return fmt.Errorf("%w: %q cannot be its own parent", ErrWrongInputFormat, name)
+	}
+
+	if len(serviceType.Description) > maxServiceTypeDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrWrongInputFormat, maxServiceTypeDescriptionLength)
+	}
+
+	return nil
+}
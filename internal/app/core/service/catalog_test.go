@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+const testAdminID = entity.UserID("8c7b6a5f-4e3d-4c2b-9a1f-0e9d8c7b6a5f")
+
+type serviceTypeRepoStub struct {
+	port.ServiceTypeRepo
+	types []*entity.ServiceType
+}
+
+func (r *serviceTypeRepoStub) Create(_ context.Context, serviceType *entity.ServiceType) (bool, error) {
+	if findServiceType(r.types, serviceType.Name) != nil {
+		return false, nil
+	}
+
+	created := *serviceType
+	r.types = append(r.types, &created)
+
+	return true, nil
+}
+
+func (r *serviceTypeRepoStub) List(context.Context) ([]*entity.ServiceType, error) {
+	types := make([]*entity.ServiceType, 0, len(r.types))
+	for _, serviceType := range r.types {
+		read := *serviceType
+		types = append(types, &read)
+	}
+
+	return types, nil
+}
+
+func (r *serviceTypeRepoStub) Update(_ context.Context, serviceType *entity.ServiceType) error {
+	updated := *serviceType
+	r.types[slices.IndexFunc(r.types, func(t *entity.ServiceType) bool { return t.Name == serviceType.Name })] = &updated
+
+	return nil
+}
+
+// newCatalog returns a catalog administered by admin with Construction, its
+// subtype Roadworks and its sub-subtype Asphalt, and the archived Delivery.
+func newCatalog() (*CatalogService, *serviceTypeRepoStub) {
+	repo := &serviceTypeRepoStub{types: []*entity.ServiceType{
+		{Name: "Construction"},
+		{Name: "Roadworks", Parent: "Construction"},
+		{Name: "Asphalt", Parent: "Roadworks"},
+		{Name: "Delivery", Archived: true},
+	}}
+	users := userRepoStub{"admin": testAdminID, "owner": testOwnerID}
+
+	return NewCatalogService(repo, users, []string{"admin"}, zap.NewNop()), repo
+}
+
+func TestCreateServiceType(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceType entity.ServiceType
+		userName    string
+		wantErr     error
+	}{
+		{name: "a root type", serviceType: entity.ServiceType{Name: " Cleaning "}, userName: "admin"},
+		{name: "a subtype", serviceType: entity.ServiceType{Name: "Electrical", Parent: "Construction"}, userName: "admin"},
+		{name: "by a user who is not an admin", serviceType: entity.ServiceType{Name: "Cleaning"}, userName: "owner", wantErr: ErrNotEnoughRights},
+		{name: "by an unknown user", serviceType: entity.ServiceType{Name: "Cleaning"}, userName: "nobody", wantErr: ErrUserNotExists},
+		{name: "an existing type", serviceType: entity.ServiceType{Name: "Delivery"}, userName: "admin", wantErr: ErrServiceTypeExists},
+		{name: "under an unknown parent", serviceType: entity.ServiceType{Name: "Electrical", Parent: "Mining"}, userName: "admin", wantErr: ErrWrongInputFormat},
+		{name: "a name with a comma", serviceType: entity.ServiceType{Name: "Roads, bridges"}, userName: "admin", wantErr: ErrWrongInputFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, repo := newCatalog()
+
+			serviceType := tt.serviceType
+			err := catalog.CreateServiceType(context.Background(), &serviceType, tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateServiceType() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantTypes := 4
+			if tt.wantErr == nil {
+				wantTypes = 5
+
+				if created := repo.types[4]; created.Name != serviceType.Name || created.Name[0] == ' ' {
+					t.Errorf("created %q, want the trimmed name", created.Name)
+				}
+			}
+
+			if len(repo.types) != wantTypes {
+				t.Errorf("catalog has %d types, want %d", len(repo.types), wantTypes)
+			}
+		})
+	}
+}
+
+func TestUpdateServiceTypeRejectsCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		parent  entity.TenderServiceType
+		wantErr error
+	}{
+		{name: "under another root", parent: "Delivery"},
+		{name: "to a root", parent: ""},
+		{name: "under itself", parent: "Roadworks", wantErr: ErrWrongInputFormat},
+		{name: "under its subtype", parent: "Asphalt", wantErr: ErrWrongInputFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, repo := newCatalog()
+
+			_, err := catalog.UpdateServiceType(context.Background(), "Roadworks", &entity.ServiceTypeUpdate{Parent: &tt.parent}, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateServiceType() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantParent := tt.parent
+			if tt.wantErr != nil {
+				wantParent = "Construction"
+			}
+
+			if parent := findServiceType(repo.types, "Roadworks").Parent; parent != wantParent {
+				t.Errorf("parent = %q, want %q", parent, wantParent)
+			}
+		})
+	}
+}
+
+func TestRequireServiceType(t *testing.T) {
+	tests := []struct {
+		name    entity.TenderServiceType
+		wantErr error
+	}{
+		{name: "Construction"},
+		{name: "Asphalt"},
+		{name: "Delivery", wantErr: ErrWrongInputFormat},
+		{name: "Mining", wantErr: ErrWrongInputFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.name), func(t *testing.T) {
+			catalog, _ := newCatalog()
+
+			if err := catalog.requireServiceType(context.Background(), tt.name); !errors.Is(err, tt.wantErr) {
+				t.Errorf("requireServiceType() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandServiceTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []entity.TenderServiceType
+		want  []entity.TenderServiceType
+	}{
+		{name: "a type with subtypes", names: []entity.TenderServiceType{"Construction"}, want: []entity.TenderServiceType{"Asphalt", "Construction", "Roadworks"}},
+		{name: "a leaf type", names: []entity.TenderServiceType{"Asphalt"}, want: []entity.TenderServiceType{"Asphalt"}},
+		{name: "no types", want: []entity.TenderServiceType{"Asphalt", "Construction", "Delivery", "Roadworks"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, _ := newCatalog()
+
+			got, err := catalog.expand(context.Background(), tt.names)
+			if err != nil {
+				t.Fatalf("expand() error = %v", err)
+			}
+
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
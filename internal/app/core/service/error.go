@@ -1,13 +1,51 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"avito2024/internal/app/core/port"
+)
 
 var (
-	ErrUserNotExists   = errors.New("user not exists")
+	ErrUserNotExists   = port.ErrUserNotExists
 	ErrNotEnoughRights = errors.New("user does not have enough rights")
 
-	ErrTenderNotFound      = errors.New("tender not found")
-	ErrWrongInputFormat    = errors.New("wrong format or parameters")
+	ErrTenderNotFound      = port.ErrTenderNotFound
+	ErrWrongInputFormat    = port.ErrWrongInputFormat
 	ErrTenderOrBidNotFound = errors.New("tender or bid not found")
 	ErrBidNotFound         = errors.New("bid not found")
+	ErrBiddingClosed       = errors.New("bidding on the tender is closed")
+	ErrBidsSealed          = errors.New("bids of the tender are sealed until the deadline")
+	ErrTenderNotPublished  = errors.New("tender is not published")
+
+	ErrServiceTypeNotFound = port.ErrServiceTypeNotFound
+	ErrServiceTypeExists   = errors.New("service type already exists")
+
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrAlreadyInvited     = errors.New("invitee is already invited to the tender")
+	ErrNotInvited         = errors.New("the tender is invitation-only and the author is not invited")
+
+	ErrQuestionNotFound = errors.New("question not found")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	ErrLotNotFound = port.ErrLotNotFound
+	ErrLotSettled  = errors.New("lot is already awarded or canceled")
+
+	ErrAuctionNotFound   = errors.New("auction not found")
+	ErrAuctionNotRunning = errors.New("auction is not running")
+	ErrOfferTooHigh      = errors.New("offer does not undercut the best price")
+
+	ErrAttachmentNotFound       = errors.New("attachment not found")
+	ErrAttachmentTooLarge       = errors.New("attachment is too large")
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type is not allowed")
+	ErrChecksumMismatch         = errors.New("attachment checksum does not match")
+
+	ErrWorkerUnhealthy = errors.New("worker unhealthy")
+
+	// errImportRolledBack rolls back an atomic import that has failed rows.
+	errImportRolledBack = errors.New("import rolled back")
+
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
 )
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+const (
+	maxCriteria            = 20
+	maxCriterionNameLength = 100
+	criteriaWeightTotal    = 100
+)
+
+// EvaluationService ranks the bids of a tender by weighted criteria the
+// reviewers score. Who may review is decided by BidService, the same way as
+// for listing the bids of a tender.
+type EvaluationService struct {
+	bidService     *BidService
+	userRepo       port.UserRepo
+	tenderRepo     port.TenderRepo
+	bidRepo        port.BidRepo
+	evaluationRepo port.EvaluationRepo
+	transactor     port.Transactor
+	logger         *zap.Logger
+}
+
+func NewEvaluationService(
+	evaluationRepo port.EvaluationRepo,
+	bidService *BidService,
+	bidRepo port.BidRepo,
+	tenderRepo port.TenderRepo,
+	userRepo port.UserRepo,
+	transactor port.Transactor,
+	logger *zap.Logger,
+) *EvaluationService {
+	return &EvaluationService{
+		bidService:     bidService,
+		userRepo:       userRepo,
+		tenderRepo:     tenderRepo,
+		bidRepo:        bidRepo,
+		evaluationRepo: evaluationRepo,
+		transactor:     transactor,
+		logger:         logger.Named("evaluation"),
+	}
+}
+
+// SetCriteria replaces the criteria of the tender. Their weights must add up to
+// 100, scores given on the previous criteria are dropped.
+func (r *EvaluationService) SetCriteria(
+	ctx context.Context,
+	tenderID entity.TenderID,
+	criteria []*entity.EvaluationCriterion,
+	userName string,
+) (_ []*entity.EvaluationCriterion, err error) {
+	ctx, span := tracer.Start(ctx, "EvaluationService.SetCriteria")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateCriteria(criteria); err != nil {
+		return nil, err
+	}
+
+	if _, _, err := r.bidService.authorizeReviewer(ctx, tenderID, userName); err != nil {
+		return nil, err
+	}
+
+	for _, criterion := range criteria {
+		criterion.ID = entity.CriterionID(uuid.NewString())
+		criterion.TenderID = tenderID
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return r.evaluationRepo.ReplaceCriteria(ctx, tenderID, criteria)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("replace criteria: %w", err)
+	}
+
+	r.log(ctx).Info("evaluation criteria set",
+		zap.String("tenderId", string(tenderID)),
+		zap.Int("criteria", len(criteria)),
+	)
+
+	return criteria, nil
+}
+
+// Criteria returns the criteria of the tender. They are public once the tender
+// is published so that bidders know what they are judged by.
+func (r *EvaluationService) Criteria(ctx context.Context, tenderID entity.TenderID, userName string) (_ []*entity.EvaluationCriterion, err error) {
+	ctx, span := tracer.Start(ctx, "EvaluationService.Criteria")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender == nil {
+		return nil, ErrTenderNotFound
+	}
+
+	if tender.Status != entity.TenderStatusPublished {
+		if _, _, err := r.bidService.authorizeReviewer(ctx, tenderID, userName); err != nil {
+			return nil, err
+		}
+	} else if err := r.bidService.tenderService.requireVisible(ctx, tender, userID); err != nil {
+		return nil, err
+	}
+
+	return r.evaluationRepo.ListCriteria(ctx, tenderID)
+}
+
+// Score saves the scores the user gives the published bid, replacing their earlier ones.
+func (r *EvaluationService) Score(
+	ctx context.Context,
+	bidID entity.BidId,
+	inputs []entity.BidScoreInput,
+	userName string,
+) (_ []*entity.BidScore, err error) {
+	ctx, span := tracer.Start(ctx, "EvaluationService.Score")
+	defer func() { tracing.End(span, err) }()
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: at least one score is required", ErrWrongInputFormat)
+	}
+
+	bid, err := r.bidRepo.ReadBidByID(ctx, bidID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bid == nil {
+		return nil, ErrBidNotFound
+	}
+
+	tender, reviewerID, err := r.bidService.authorizeReviewer(ctx, bid.TenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if bid.Status != entity.BidStatus(Published) {
+		return nil, fmt.Errorf("%w: only published bids are scored", ErrWrongInputFormat)
+	}
+
+	if tender.BidsSealed(time.Now()) {
+		return nil, ErrBidsSealed
+	}
+
+	criteria, err := r.evaluationRepo.ListCriteria(ctx, bid.TenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[entity.CriterionID]bool, len(criteria))
+	for _, criterion := range criteria {
+		known[criterion.ID] = true
+	}
+
+	now := time.Now()
+	scores := make([]*entity.BidScore, 0, len(inputs))
+	seen := make(map[entity.CriterionID]bool, len(inputs))
+
+	for i, input := range inputs {
+		if !known[input.CriterionID] {
+			return nil, fmt.Errorf("%w: scores[%d]: criterion %q is not a criterion of the tender", ErrWrongInputFormat, i, input.CriterionID)
+		}
+
+		if seen[input.CriterionID] {
+			return nil, fmt.Errorf("%w: scores[%d]: criterion %q is scored twice", ErrWrongInputFormat, i, input.CriterionID)
+		}
+		seen[input.CriterionID] = true
+
+		if input.Score < 0 || input.Score > entity.MaxScore {
+			return nil, fmt.Errorf("%w: scores[%d]: score must be from 0 to %d", ErrWrongInputFormat, i, entity.MaxScore)
+		}
+
+		scores = append(scores, &entity.BidScore{
+			BidID:       bidID,
+			CriterionID: input.CriterionID,
+			ReviewerID:  reviewerID,
+			Score:       input.Score,
+			UpdatedAt:   now,
+		})
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, score := range scores {
+			if err := r.evaluationRepo.SaveScore(ctx, score); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save scores: %w", err)
+	}
+
+	r.log(ctx).Info("bid scored",
+		zap.String("bidId", string(bidID)),
+		zap.String("reviewerId", string(reviewerID)),
+		zap.Int("scores", len(scores)),
+	)
+
+	return scores, nil
+}
+
+// Compare ranks the bids of the tender by the weighted total of their average
+// reviewer scores. Criteria nobody has scored yet count as zero. Only published
+// bids are ranked, a bid canceled after it was scored drops out. Sealed bids
+// are listed redacted, they cannot have been scored yet.
+func (r *EvaluationService) Compare(ctx context.Context, tenderID entity.TenderID, userName string) (_ *entity.BidComparison, err error) {
+	ctx, span := tracer.Start(ctx, "EvaluationService.Compare")
+	defer func() { tracing.End(span, err) }()
+
+	bids, err := r.bidService.ListTenderBids(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	criteria, err := r.evaluationRepo.ListCriteria(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := r.evaluationRepo.ListScores(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareBids(tenderID, bids, criteria, scores), nil
+}
+
+func compareBids(
+	tenderID entity.TenderID,
+	bids []*entity.Bid,
+	criteria []*entity.EvaluationCriterion,
+	scores []*entity.BidScore,
+) *entity.BidComparison {
+	type key struct {
+		bid       entity.BidId
+		criterion entity.CriterionID
+	}
+
+	type sum struct {
+		total int64
+		count int64
+	}
+
+	sums := make(map[key]*sum)
+
+	for _, score := range scores {
+		k := key{bid: score.BidID, criterion: score.CriterionID}
+		if sums[k] == nil {
+			sums[k] = &sum{}
+		}
+
+		sums[k].total += int64(score.Score)
+		sums[k].count++
+	}
+
+	comparison := &entity.BidComparison{
+		TenderID: tenderID,
+		Criteria: criteria,
+		Rows:     make([]*entity.BidComparisonRow, 0, len(bids)),
+	}
+
+	for _, bid := range bids {
+		if bid.Status != entity.BidStatus(Published) {
+			continue
+		}
+
+		row := &entity.BidComparisonRow{
+			Bid:      bid,
+			Scores:   make(map[entity.CriterionID]float64, len(criteria)),
+			Complete: len(criteria) > 0,
+		}
+
+		var total float64
+
+		for _, criterion := range criteria {
+			s := sums[key{bid: bid.ID, criterion: criterion.ID}]
+			if s == nil {
+				row.Complete = false
+				continue
+			}
+
+			average := float64(s.total) / float64(s.count)
+			row.Scores[criterion.ID] = roundScore(average)
+			total += average * float64(criterion.Weight) / criteriaWeightTotal
+		}
+
+		row.Total = roundScore(total)
+		comparison.Rows = append(comparison.Rows, row)
+	}
+
+	sort.SliceStable(comparison.Rows, func(i, j int) bool {
+		a, b := comparison.Rows[i], comparison.Rows[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+
+		return a.Bid.CreatedAt.Before(b.Bid.CreatedAt)
+	})
+
+	for i, row := range comparison.Rows {
+		row.Rank = i + 1
+		if i > 0 && row.Total == comparison.Rows[i-1].Total {
+			row.Rank = comparison.Rows[i-1].Rank
+		}
+	}
+
+	return comparison
+}
+
+func validateCriteria(criteria []*entity.EvaluationCriterion) error {
+	if len(criteria) == 0 || len(criteria) > maxCriteria {
+		return fmt.Errorf("%w: a tender has 1 to %d criteria", ErrWrongInputFormat, maxCriteria)
+	}
+
+	var weights int32
+	names := make(map[string]bool, len(criteria))
+
+	for i, criterion := range criteria {
+		if criterion == nil {
+			return fmt.Errorf("%w: criteria[%d] is empty", ErrWrongInputFormat, i)
+		}
+
+		if criterion.Name == "" || len(criterion.Name) > maxCriterionNameLength {
+			return fmt.Errorf("%w: criteria[%d]: name must be 1 to %d characters", ErrWrongInputFormat, i, maxCriterionNameLength)
+		}
+
+		if names[criterion.Name] {
+			return fmt.Errorf("%w: criteria[%d]: name %q is used twice", ErrWrongInputFormat, i, criterion.Name)
+		}
+		names[criterion.Name] = true
+
+		if criterion.Weight <= 0 || criterion.Weight > criteriaWeightTotal {
+			return fmt.Errorf("%w: criteria[%d]: weight must be from 1 to %d", ErrWrongInputFormat, i, criteriaWeightTotal)
+		}
+
+		weights += criterion.Weight
+	}
+
+	if weights != criteriaWeightTotal {
+		return fmt.Errorf("%w: weights add up to %d instead of %d", ErrWrongInputFormat, weights, criteriaWeightTotal)
+	}
+
+	return nil
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+func (r *EvaluationService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
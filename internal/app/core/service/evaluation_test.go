@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+)
+
+func TestCompareBids(t *testing.T) {
+	start := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	bid := func(id string, status string, minutes int) *entity.Bid {
+		return &entity.Bid{ID: entity.BidId(id), Status: entity.BidStatus(status), CreatedAt: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	score := func(bidID, criterionID, reviewerID string, value int32) *entity.BidScore {
+		return &entity.BidScore{BidID: entity.BidId(bidID), CriterionID: entity.CriterionID(criterionID), ReviewerID: entity.UserID(reviewerID), Score: value}
+	}
+
+	criteria := []*entity.EvaluationCriterion{
+		{ID: "price", Weight: 60},
+		{ID: "quality", Weight: 40},
+	}
+
+	type row struct {
+		bid      entity.BidId
+		rank     int
+		total    float64
+		complete bool
+	}
+
+	tests := []struct {
+		name     string
+		bids     []*entity.Bid
+		criteria []*entity.EvaluationCriterion
+		scores   []*entity.BidScore
+		want     []row
+	}{
+		{
+			name:     "ranked by weighted total",
+			bids:     []*entity.Bid{bid("a", Published, 0), bid("b", Published, 1)},
+			criteria: criteria,
+			scores: []*entity.BidScore{
+				score("a", "price", "r1", 50), score("a", "quality", "r1", 100),
+				score("b", "price", "r1", 100), score("b", "quality", "r1", 50),
+			},
+			want: []row{{bid: "b", rank: 1, total: 80, complete: true}, {bid: "a", rank: 2, total: 70, complete: true}},
+		},
+		{
+			name:     "reviewer scores are averaged",
+			bids:     []*entity.Bid{bid("a", Published, 0)},
+			criteria: criteria,
+			scores: []*entity.BidScore{
+				score("a", "price", "r1", 40), score("a", "price", "r2", 81),
+				score("a", "quality", "r1", 100),
+			},
+			want: []row{{bid: "a", rank: 1, total: 76.3, complete: true}},
+		},
+		{
+			name:     "ties share the rank and keep the earlier bid first",
+			bids:     []*entity.Bid{bid("late", Published, 5), bid("early", Published, 0), bid("low", Published, 1)},
+			criteria: criteria,
+			scores: []*entity.BidScore{
+				score("late", "price", "r1", 50), score("late", "quality", "r1", 50),
+				score("early", "price", "r1", 50), score("early", "quality", "r1", 50),
+				score("low", "price", "r1", 10), score("low", "quality", "r1", 10),
+			},
+			want: []row{
+				{bid: "early", rank: 1, total: 50, complete: true},
+				{bid: "late", rank: 1, total: 50, complete: true},
+				{bid: "low", rank: 3, total: 10, complete: true},
+			},
+		},
+		{
+			name:     "unscored criteria count as zero",
+			bids:     []*entity.Bid{bid("a", Published, 0), bid("b", Published, 1)},
+			criteria: criteria,
+			scores:   []*entity.BidScore{score("a", "price", "r1", 100)},
+			want:     []row{{bid: "a", rank: 1, total: 60}, {bid: "b", rank: 2, total: 0}},
+		},
+		{
+			name:     "bids that are not published are left out",
+			bids:     []*entity.Bid{bid("canceled", Canceled, 0), bid("draft", Created, 1), bid("a", Published, 2)},
+			criteria: criteria,
+			scores: []*entity.BidScore{
+				score("canceled", "price", "r1", 100), score("canceled", "quality", "r1", 100),
+				score("a", "price", "r1", 10), score("a", "quality", "r1", 10),
+			},
+			want: []row{{bid: "a", rank: 1, total: 10, complete: true}},
+		},
+		{
+			name: "no criteria",
+			bids: []*entity.Bid{bid("a", Published, 0)},
+			want: []row{{bid: "a", rank: 1, total: 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := compareBids("tender", tt.bids, tt.criteria, tt.scores)
+
+			if len(comparison.Rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(comparison.Rows), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				got := comparison.Rows[i]
+				if got.Bid.ID != want.bid || got.Rank != want.rank || got.Total != want.total || got.Complete != want.complete {
+					t.Errorf("row %d: got bid %s, rank %d, total %v, complete %t, want %s, %d, %v, %t",
+						i, got.Bid.ID, got.Rank, got.Total, got.Complete, want.bid, want.rank, want.total, want.complete)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCriteria(t *testing.T) {
+	criteria := func(weights ...int32) []*entity.EvaluationCriterion {
+		result := make([]*entity.EvaluationCriterion, 0, len(weights))
+		for i, weight := range weights {
+			result = append(result, &entity.EvaluationCriterion{Name: "criterion " + string(rune('a'+i)), Weight: weight})
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		criteria []*entity.EvaluationCriterion
+		wantErr  bool
+	}{
+		{name: "single criterion", criteria: criteria(100)},
+		{name: "weights add up", criteria: criteria(50, 30, 20)},
+		{name: "none", criteria: nil, wantErr: true},
+		{name: "too many", criteria: criteria(make([]int32, maxCriteria+1)...), wantErr: true},
+		{name: "weights below total", criteria: criteria(50, 40), wantErr: true},
+		{name: "weights above total", criteria: criteria(60, 50), wantErr: true},
+		{name: "zero weight", criteria: criteria(100, 0), wantErr: true},
+		{name: "negative weight", criteria: criteria(110, -10), wantErr: true},
+		{name: "nil criterion", criteria: []*entity.EvaluationCriterion{nil}, wantErr: true},
+		{name: "empty name", criteria: []*entity.EvaluationCriterion{{Weight: 100}}, wantErr: true},
+		{
+			name:     "name too long",
+			criteria: []*entity.EvaluationCriterion{{Name: strings.Repeat("a", maxCriterionNameLength+1), Weight: 100}},
+			wantErr:  true,
+		},
+		{
+			name:     "name used twice",
+			criteria: []*entity.EvaluationCriterion{{Name: "price", Weight: 50}, {Name: "price", Weight: 50}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCriteria(tt.criteria)
+			if tt.wantErr {
+				if !errors.Is(err, ErrWrongInputFormat) {
+					t.Errorf("got error %v, want %v", err, ErrWrongInputFormat)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+type HealthService struct {
+	checks  []port.HealthChecker
+	timeout time.Duration
+}
+
+func NewHealthService(checks ...port.HealthChecker) *HealthService {
+	return &HealthService{
+		checks:  checks,
+		timeout: defaultCheckTimeout,
+	}
+}
+
+// Live reports that the process is running. It never touches dependencies,
+// so a slow database does not get the replica restarted.
+func (r *HealthService) Live() *entity.HealthReport {
+	return &entity.HealthReport{Status: entity.HealthStatusUp}
+}
+
+// Ready runs every registered check concurrently and reports down if any of them fails.
+func (r *HealthService) Ready(ctx context.Context) *entity.HealthReport {
+	report := &entity.HealthReport{
+		Status: entity.HealthStatusUp,
+		Checks: make([]*entity.HealthCheck, len(r.checks)),
+	}
+
+	var wg sync.WaitGroup
+
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, check)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		if check.Status != entity.HealthStatusUp {
+			report.Status = entity.HealthStatusDown
+		}
+	}
+
+	return report
+}
+
+func (r *HealthService) run(ctx context.Context, check port.HealthChecker) *entity.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := check.Check(ctx)
+
+	result := &entity.HealthCheck{
+		Name:      check.Name(),
+		Status:    entity.HealthStatusUp,
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = entity.HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
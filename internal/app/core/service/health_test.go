@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+type healthCheckerStub struct {
+	name  string
+	err   error
+	delay time.Duration
+}
+
+func (r healthCheckerStub) Name() string {
+	return r.name
+}
+
+func (r healthCheckerStub) Check(ctx context.Context) error {
+	if r.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.delay):
+		}
+	}
+
+	return r.err
+}
+
+func TestHealthReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []port.HealthChecker
+		wantStatus entity.HealthStatus
+		wantChecks []entity.HealthStatus
+	}{
+		{
+			name:       "no checks",
+			wantStatus: entity.HealthStatusUp,
+			wantChecks: []entity.HealthStatus{},
+		},
+		{
+			name: "every check passes",
+			checks: []port.HealthChecker{
+				healthCheckerStub{name: "postgres"},
+				healthCheckerStub{name: "workers"},
+			},
+			wantStatus: entity.HealthStatusUp,
+			wantChecks: []entity.HealthStatus{entity.HealthStatusUp, entity.HealthStatusUp},
+		},
+		{
+			name: "one check fails",
+			checks: []port.HealthChecker{
+				healthCheckerStub{name: "postgres", err: errors.New("connection refused")},
+				healthCheckerStub{name: "workers"},
+			},
+			wantStatus: entity.HealthStatusDown,
+			wantChecks: []entity.HealthStatus{entity.HealthStatusDown, entity.HealthStatusUp},
+		},
+		{
+			name: "a check outlasts the timeout",
+			checks: []port.HealthChecker{
+				healthCheckerStub{name: "postgres", delay: time.Second},
+			},
+			wantStatus: entity.HealthStatusDown,
+			wantChecks: []entity.HealthStatus{entity.HealthStatusDown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := NewHealthService(tt.checks...)
+			health.timeout = 10 * time.Millisecond
+
+			report := health.Ready(context.Background())
+
+			if report.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", report.Status, tt.wantStatus)
+			}
+
+			if len(report.Checks) != len(tt.wantChecks) {
+				t.Fatalf("got %d checks, want %d", len(report.Checks), len(tt.wantChecks))
+			}
+
+			for i, check := range report.Checks {
+				if check.Name != tt.checks[i].Name() {
+					t.Errorf("Checks[%d].Name = %q, want %q", i, check.Name, tt.checks[i].Name())
+				}
+
+				if check.Status != tt.wantChecks[i] {
+					t.Errorf("Checks[%d].Status = %q, want %q", i, check.Status, tt.wantChecks[i])
+				}
+
+				if (check.Status == entity.HealthStatusDown) != (check.Error != "") {
+					t.Errorf("Checks[%d].Error = %q with status %q", i, check.Error, check.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+const idempotencyCleanupWorker = "idempotency_cleanup"
+
+// IdempotencyService lets a retried request get the response of the first
+// request made with the same idempotency key instead of being served again.
+type IdempotencyService struct {
+	repo        port.IdempotencyRepo
+	window      time.Duration
+	lockTimeout time.Duration
+	maxBodySize int64
+	logger      *zap.Logger
+}
+
+// NewIdempotencyService keeps responses for window. A request that is not
+// completed within lockTimeout is considered dead and its key may be reused.
+// Requests with a body larger than maxBodySize bytes are rejected.
+func NewIdempotencyService(
+	repo port.IdempotencyRepo,
+	window, lockTimeout time.Duration,
+	maxBodySize int64,
+	logger *zap.Logger,
+) *IdempotencyService {
+	return &IdempotencyService{
+		repo:        repo,
+		window:      window,
+		lockTimeout: lockTimeout,
+		maxBodySize: maxBodySize,
+		logger:      logger.Named("idempotency"),
+	}
+}
+
+// MaxBodySize is the largest request body in bytes a key can be taken for.
+func (r *IdempotencyService) MaxBodySize() int64 {
+	return r.maxBodySize
+}
+
+// Begin claims key for a request with requestHash. It returns the stored response
+// of a completed request, or nil when the caller must serve the request and then
+// call Complete or Release.
+func (r *IdempotencyService) Begin(ctx context.Context, scope, key, requestHash string) (_ *entity.IdempotentResponse, err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Begin")
+	defer func() { tracing.End(span, err) }()
+
+	existing, err := r.repo.Acquire(ctx, &entity.IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+	}, r.lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		return nil, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+
+	if existing.Response == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	r.log(ctx).Info("idempotent request replayed", zap.String("scope", scope), zap.String("key", key))
+
+	return existing.Response, nil
+}
+
+// Complete stores the response of a request started with Begin.
+func (r *IdempotencyService) Complete(ctx context.Context, scope, key string, response *entity.IdempotentResponse) (err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Complete")
+	defer func() { tracing.End(span, err) }()
+
+	return r.repo.Complete(ctx, scope, key, response, r.window)
+}
+
+// Release frees key after a request that must not be replayed, so the client can retry it.
+func (r *IdempotencyService) Release(ctx context.Context, scope, key string) (err error) {
+	ctx, span := tracer.Start(ctx, "IdempotencyService.Release")
+	defer func() { tracing.End(span, err) }()
+
+	return r.repo.Delete(ctx, scope, key)
+}
+
+// RunCleanup deletes expired keys every interval until ctx is done.
+func (r *IdempotencyService) RunCleanup(ctx context.Context, monitor *WorkerMonitor, interval time.Duration) {
+	monitor.Register(idempotencyCleanupWorker, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := r.repo.DeleteExpired(ctx)
+		if err != nil {
+			r.logger.Error("idempotency cleanup failed", zap.Error(err))
+		} else if deleted > 0 {
+			r.logger.Info("expired idempotency keys deleted", zap.Int64("count", deleted))
+		}
+
+		monitor.Beat(idempotencyCleanupWorker, err)
+	}
+}
+
+func (r *IdempotencyService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/tracing"
+)
+
+// Invite invites an organization or a user to an invitation-only tender.
+func (r *TenderService) Invite(
+	ctx context.Context,
+	tenderID entity.TenderID,
+	invitation *entity.Invitation,
+	userName string,
+) (_ *entity.Invitation, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.Invite")
+	defer func() { tracing.End(span, err) }()
+
+	tender, userID, err := r.authorizeOwner(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tender.InviteOnly {
+		return nil, fmt.Errorf("%w: the tender is not invitation-only", ErrWrongInputFormat)
+	}
+
+	if tender.Status == entity.TenderStatusClosed {
+		return nil, fmt.Errorf("%w: the tender is closed", ErrWrongInputFormat)
+	}
+
+	switch invitation.InviteeType {
+	case entity.BidAuthorOrganization:
+		if !r.organizationRepo.Exists(ctx, entity.OrganizationID(invitation.InviteeID)) {
+			return nil, ErrUserNotExists
+		}
+	case entity.BidAuthorUser:
+		if !r.userRepo.Exists(ctx, entity.UserID(invitation.InviteeID)) {
+			return nil, ErrUserNotExists
+		}
+	default:
+		return nil, fmt.Errorf("%w: inviteeType must be %s or %s", ErrWrongInputFormat, entity.BidAuthorOrganization, entity.BidAuthorUser)
+	}
+
+	invitation.ID = entity.InvitationID(uuid.NewString())
+	invitation.TenderID = tenderID
+	invitation.Status = entity.InvitationStatusPending
+	invitation.CreatedAt = time.Now()
+	invitation.RespondedAt = nil
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err := r.invitationRepo.Create(ctx, invitation)
+		if err != nil {
+			return fmt.Errorf("create invitation: %w", err)
+		}
+
+		if !created {
+			return ErrAlreadyInvited
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityInvitation, string(invitation.ID), entity.AuditActionCreate, tender.OrganizationID, userID, nil, invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log(ctx).Info("invitation created",
+		zap.String("tenderId", string(tenderID)),
+		zap.String("invitationId", string(invitation.ID)),
+	)
+
+	return invitation, nil
+}
+
+// ListInvitations returns the invitations to the tender with the responses of the invitees.
+func (r *TenderService) ListInvitations(ctx context.Context, tenderID entity.TenderID, userName string) (_ []*entity.Invitation, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.ListInvitations")
+	defer func() { tracing.End(span, err) }()
+
+	if _, _, err := r.authorizeOwner(ctx, tenderID, userName); err != nil {
+		return nil, err
+	}
+
+	return r.invitationRepo.ListByTender(ctx, tenderID)
+}
+
+// RevokeInvitation withdraws an invitation, the invitee no longer sees the
+// tender but keeps the bids already made.
+func (r *TenderService) RevokeInvitation(
+	ctx context.Context,
+	tenderID entity.TenderID,
+	invitationID entity.InvitationID,
+	userName string,
+) (err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.RevokeInvitation")
+	defer func() { tracing.End(span, err) }()
+
+	tender, userID, err := r.authorizeOwner(ctx, tenderID, userName)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := r.invitationRepo.Read(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+
+	if invitation == nil || invitation.TenderID != tenderID {
+		return ErrInvitationNotFound
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.invitationRepo.Delete(ctx, invitationID); err != nil {
+			return fmt.Errorf("delete invitation: %w", err)
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityInvitation, string(invitationID), entity.AuditActionRevoke, tender.OrganizationID, userID, invitation, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.log(ctx).Info("invitation revoked",
+		zap.String("tenderId", string(tenderID)),
+		zap.String("invitationId", string(invitationID)),
+	)
+
+	return nil
+}
+
+// MyInvitations returns the invitations addressed to the user or to the organizations they are responsible for.
+func (r *TenderService) MyInvitations(ctx context.Context, userName string) (_ []*entity.Invitation, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.MyInvitations")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	viewer, err := r.viewer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.invitationRepo.ListFor(ctx, viewer)
+}
+
+// RespondInvitation accepts or declines an invitation on behalf of the
+// invitee. The response can be changed until the tender is closed.
+func (r *TenderService) RespondInvitation(
+	ctx context.Context,
+	invitationID entity.InvitationID,
+	response entity.InvitationStatus,
+	userName string,
+) (_ *entity.Invitation, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.RespondInvitation")
+	defer func() { tracing.End(span, err) }()
+
+	if response != entity.InvitationStatusAccepted && response != entity.InvitationStatusDeclined {
+		return nil, fmt.Errorf("%w: response must be %s or %s",
+			ErrWrongInputFormat, entity.InvitationStatusAccepted, entity.InvitationStatusDeclined)
+	}
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	invitation, err := r.invitationRepo.Read(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	viewer, err := r.viewer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isInvitee(invitation, viewer) {
+		return nil, ErrInvitationNotFound
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, invitation.TenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender == nil {
+		return nil, ErrTenderNotFound
+	}
+
+	if tender.Status == entity.TenderStatusClosed {
+		return nil, fmt.Errorf("%w: the tender is closed", ErrWrongInputFormat)
+	}
+
+	respondedAt := time.Now()
+
+	before := *invitation
+	invitation.Status = response
+	invitation.RespondedAt = &respondedAt
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.invitationRepo.UpdateStatus(ctx, invitationID, response, respondedAt); err != nil {
+			return fmt.Errorf("update invitation: %w", err)
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityInvitation, string(invitationID), entity.AuditActionRespond, tender.OrganizationID, userID, &before, invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log(ctx).Info("invitation answered",
+		zap.String("tenderId", string(invitation.TenderID)),
+		zap.String("invitationId", string(invitationID)),
+		zap.String("status", string(response)),
+	)
+
+	return invitation, nil
+}
+
+// requireVisible hides an invitation-only tender from everyone but the
+// responsible users of its organization and invitees who did not decline.
+// A hidden tender is reported as not found so that its existence does not leak.
+func (r *TenderService) requireVisible(ctx context.Context, tender *entity.Tender, userID entity.UserID) error {
+	if !tender.InviteOnly {
+		return nil
+	}
+
+	viewer, err := r.viewer(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(viewer.Organizations, tender.OrganizationID) {
+		return nil
+	}
+
+	invited, err := r.invitationRepo.Invited(ctx, tender.ID, viewer)
+	if err != nil {
+		return err
+	}
+
+	if !invited {
+		return ErrTenderNotFound
+	}
+
+	return nil
+}
+
+// requireInvitedAuthor rejects a bid on an invitation-only tender whose author
+// holds no invitation. A user bidding in person may also rely on an invitation
+// of an organization they are responsible for.
+func (r *TenderService) requireInvitedAuthor(ctx context.Context, tender *entity.Tender, bid *entity.Bid) error {
+	if !tender.InviteOnly {
+		return nil
+	}
+
+	viewer := &entity.Viewer{}
+
+	switch bid.AuthorType {
+	case entity.BidAuthorOrganization:
+		viewer.Organizations = []entity.OrganizationID{entity.OrganizationID(bid.AuthorID)}
+	case entity.BidAuthorUser:
+		var err error
+		if viewer, err = r.viewer(ctx, entity.UserID(bid.AuthorID)); err != nil {
+			return err
+		}
+	}
+
+	invited, err := r.invitationRepo.Invited(ctx, tender.ID, viewer)
+	if err != nil {
+		return err
+	}
+
+	if !invited {
+		return ErrNotInvited
+	}
+
+	return nil
+}
+
+func (r *TenderService) viewer(ctx context.Context, userID entity.UserID) (*entity.Viewer, error) {
+	organizations, err := r.organizationRepo.ReadResponsibleUserOrganization(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Viewer{UserID: userID, Organizations: organizations}, nil
+}
+
+func isInvitee(invitation *entity.Invitation, viewer *entity.Viewer) bool {
+	switch invitation.InviteeType {
+	case entity.BidAuthorUser:
+		return entity.UserID(invitation.InviteeID) == viewer.UserID
+	case entity.BidAuthorOrganization:
+		return slices.Contains(viewer.Organizations, entity.OrganizationID(invitation.InviteeID))
+	default:
+		return false
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+type invitationRepoStub struct {
+	port.InvitationRepo
+	invitations map[entity.InvitationID]*entity.Invitation
+}
+
+func (r *invitationRepoStub) Create(_ context.Context, invitation *entity.Invitation) (bool, error) {
+	for _, existing := range r.invitations {
+		if existing.TenderID == invitation.TenderID && existing.InviteeType == invitation.InviteeType && existing.InviteeID == invitation.InviteeID {
+			return false, nil
+		}
+	}
+
+	created := *invitation
+	r.invitations[invitation.ID] = &created
+
+	return true, nil
+}
+
+func (r *invitationRepoStub) Read(_ context.Context, invitationID entity.InvitationID) (*entity.Invitation, error) {
+	invitation, ok := r.invitations[invitationID]
+	if !ok {
+		return nil, nil
+	}
+
+	read := *invitation
+
+	return &read, nil
+}
+
+func (r *invitationRepoStub) Invited(_ context.Context, tenderID entity.TenderID, viewer *entity.Viewer) (bool, error) {
+	for _, invitation := range r.invitations {
+		if invitation.TenderID == tenderID && invitation.Status != entity.InvitationStatusDeclined && isInvitee(invitation, viewer) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *invitationRepoStub) UpdateStatus(_ context.Context, invitationID entity.InvitationID, status entity.InvitationStatus, respondedAt time.Time) error {
+	r.invitations[invitationID].Status = status
+	r.invitations[invitationID].RespondedAt = &respondedAt
+
+	return nil
+}
+
+// withInvitation makes the fixture tender invitation-only and invites the
+// supplier organization to it as invitation i1 in status.
+func (f *fixture) withInvitation(status entity.InvitationStatus) {
+	f.tenders.tenders[testTenderID].InviteOnly = true
+	f.invitations.invitations["i1"] = &entity.Invitation{
+		ID:          "i1",
+		TenderID:    testTenderID,
+		InviteeType: entity.BidAuthorOrganization,
+		InviteeID:   entity.BidAuthorId(testSupplierOrgID),
+		Status:      status,
+	}
+}
+
+func TestInvite(t *testing.T) {
+	tests := []struct {
+		name        string
+		userName    string
+		inviteOnly  bool
+		inviteeType entity.BidAuthorType
+		inviteeID   entity.BidAuthorId
+		wantErr     error
+	}{
+		{
+			name:        "a user",
+			userName:    "owner",
+			inviteOnly:  true,
+			inviteeType: entity.BidAuthorUser,
+			inviteeID:   entity.BidAuthorId(testAuthorID),
+		},
+		{
+			name:        "an organization invited before",
+			userName:    "owner",
+			inviteOnly:  true,
+			inviteeType: entity.BidAuthorOrganization,
+			inviteeID:   entity.BidAuthorId(testSupplierOrgID),
+			wantErr:     ErrAlreadyInvited,
+		},
+		{
+			name:        "an unknown user",
+			userName:    "owner",
+			inviteOnly:  true,
+			inviteeType: entity.BidAuthorUser,
+			inviteeID:   "7e6d5c4b-3a2f-4e1d-9c0b-8a7f6e5d4c3b",
+			wantErr:     ErrUserNotExists,
+		},
+		{
+			name:        "by a user outside the tender organization",
+			userName:    "supplier",
+			inviteOnly:  true,
+			inviteeType: entity.BidAuthorUser,
+			inviteeID:   entity.BidAuthorId(testAuthorID),
+			wantErr:     ErrNotEnoughRights,
+		},
+		{
+			name:        "to a public tender",
+			userName:    "owner",
+			inviteeType: entity.BidAuthorUser,
+			inviteeID:   entity.BidAuthorId(testAuthorID),
+			wantErr:     ErrWrongInputFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.withInvitation(entity.InvitationStatusPending)
+			f.tenders.tenders[testTenderID].InviteOnly = tt.inviteOnly
+
+			invitation, err := f.tenderService.Invite(context.Background(), testTenderID, &entity.Invitation{
+				InviteeType: tt.inviteeType,
+				InviteeID:   tt.inviteeID,
+			}, tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Invite() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantStored := 1
+			if tt.wantErr == nil {
+				wantStored = 2
+
+				if invitation.Status != entity.InvitationStatusPending || invitation.TenderID != testTenderID {
+					t.Errorf("invitation = %+v, want a pending invitation to the tender", invitation)
+				}
+			}
+
+			if len(f.invitations.invitations) != wantStored {
+				t.Errorf("stored invitations = %d, want %d", len(f.invitations.invitations), wantStored)
+			}
+		})
+	}
+}
+
+func TestRespondInvitation(t *testing.T) {
+	tests := []struct {
+		name       string
+		userName   string
+		response   entity.InvitationStatus
+		closed     bool
+		wantErr    error
+		wantStatus entity.InvitationStatus
+	}{
+		{name: "accept by the invitee", userName: "supplier", response: entity.InvitationStatusAccepted, wantStatus: entity.InvitationStatusAccepted},
+		{name: "decline by the invitee", userName: "supplier", response: entity.InvitationStatusDeclined, wantStatus: entity.InvitationStatusDeclined},
+		{
+			name:       "by the tender owner",
+			userName:   "owner",
+			response:   entity.InvitationStatusAccepted,
+			wantErr:    ErrInvitationNotFound,
+			wantStatus: entity.InvitationStatusPending,
+		},
+		{
+			name:       "by another user",
+			userName:   "stranger",
+			response:   entity.InvitationStatusAccepted,
+			wantErr:    ErrInvitationNotFound,
+			wantStatus: entity.InvitationStatusPending,
+		},
+		{
+			name:       "back to pending",
+			userName:   "supplier",
+			response:   entity.InvitationStatusPending,
+			wantErr:    ErrWrongInputFormat,
+			wantStatus: entity.InvitationStatusPending,
+		},
+		{
+			name:       "to a closed tender",
+			userName:   "supplier",
+			response:   entity.InvitationStatusAccepted,
+			closed:     true,
+			wantErr:    ErrWrongInputFormat,
+			wantStatus: entity.InvitationStatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.withInvitation(entity.InvitationStatusPending)
+
+			if tt.closed {
+				f.tenders.tenders[testTenderID].Status = entity.TenderStatusClosed
+			}
+
+			_, err := f.tenderService.RespondInvitation(context.Background(), "i1", tt.response, tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RespondInvitation() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if status := f.invitations.invitations["i1"].Status; status != tt.wantStatus {
+				t.Errorf("invitation status = %s, want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestBidCreateRequiresInvitation(t *testing.T) {
+	tests := []struct {
+		name       string
+		invitation entity.InvitationStatus
+		authorType entity.BidAuthorType
+		authorID   entity.BidAuthorId
+		wantErr    error
+	}{
+		{
+			name:       "invited organization",
+			invitation: entity.InvitationStatusPending,
+			authorType: entity.BidAuthorOrganization,
+			authorID:   entity.BidAuthorId(testSupplierOrgID),
+		},
+		{
+			name:       "user responsible for the invited organization",
+			invitation: entity.InvitationStatusAccepted,
+			authorType: entity.BidAuthorUser,
+			authorID:   entity.BidAuthorId(testSupplierID),
+		},
+		{
+			name:       "organization that declined",
+			invitation: entity.InvitationStatusDeclined,
+			authorType: entity.BidAuthorOrganization,
+			authorID:   entity.BidAuthorId(testSupplierOrgID),
+			wantErr:    ErrNotInvited,
+		},
+		{
+			name:       "user who was not invited",
+			invitation: entity.InvitationStatusAccepted,
+			authorType: entity.BidAuthorUser,
+			authorID:   entity.BidAuthorId(testAuthorID),
+			wantErr:    ErrNotInvited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.withInvitation(tt.invitation)
+
+			err := f.bidService.Create(context.Background(), &entity.Bid{
+				Name:       "offer",
+				TenderID:   testTenderID,
+				AuthorType: tt.authorType,
+				AuthorID:   tt.authorID,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
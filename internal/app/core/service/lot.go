@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/tracing"
+)
+
+const maxLotNameLength = 100
+
+// CreateLot adds a lot to the tender, lots are only added before it is published.
+func (r *TenderService) CreateLot(ctx context.Context, tenderID entity.TenderID, lot *entity.Lot, userName string) (err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.CreateLot")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateLot(lot); err != nil {
+		return err
+	}
+
+	if err := r.catalog.requireServiceType(ctx, lot.ServiceType); err != nil {
+		return err
+	}
+
+	tender, userID, err := r.authorizeOwner(ctx, tenderID, userName)
+	if err != nil {
+		return err
+	}
+
+	if tender.Status != entity.TenderStatusCreated {
+		return fmt.Errorf("%w: lots can only be added before the tender is published", ErrWrongInputFormat)
+	}
+
+	lot.ID = entity.LotID(uuid.NewString())
+	lot.TenderID = tenderID
+	lot.Status = entity.LotStatusOpen
+	lot.AwardedBidID = ""
+	lot.CreatedAt = time.Now()
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.lotRepo.Create(ctx, lot); err != nil {
+			return fmt.Errorf("create lot: %w", err)
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityLot, string(lot.ID), entity.AuditActionCreate, tender.OrganizationID, userID, nil, lot)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.log(ctx).Info("lot created",
+		zap.String("tenderId", string(tenderID)),
+		zap.String("lotId", string(lot.ID)),
+	)
+
+	return nil
+}
+
+// ListLots returns the lots of the tender, they are public once it is published
+// to everyone who sees the tender.
+func (r *TenderService) ListLots(ctx context.Context, tenderID entity.TenderID, userName string) (_ []*entity.Lot, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.ListLots")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender == nil {
+		return nil, ErrTenderNotFound
+	}
+
+	if tender.Status == entity.TenderStatusCreated {
+		if _, _, err := r.authorizeOwner(ctx, tenderID, userName); err != nil {
+			return nil, err
+		}
+	} else if err := r.requireVisible(ctx, tender, userID); err != nil {
+		return nil, err
+	}
+
+	return r.lotRepo.List(ctx, tenderID)
+}
+
+// CancelLot withdraws an open lot, closing the tender if it was the last one open.
+func (r *TenderService) CancelLot(ctx context.Context, tenderID entity.TenderID, lotID entity.LotID, userName string) (_ *entity.Lot, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.CancelLot")
+	defer func() { tracing.End(span, err) }()
+
+	tender, userID, err := r.authorizeOwner(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	lot, err := r.settleLot(ctx, tender, lotID, entity.LotStatusCanceled, "", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return lot, nil
+}
+
+// lot returns the lot if it belongs to the tender.
+func (r *TenderService) lot(ctx context.Context, tenderID entity.TenderID, lotID entity.LotID) (*entity.Lot, error) {
+	lot, err := r.lotRepo.Read(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+
+	if lot == nil || lot.TenderID != tenderID {
+		return nil, ErrLotNotFound
+	}
+
+	return lot, nil
+}
+
+func (r *TenderService) hasLots(ctx context.Context, tenderID entity.TenderID) (bool, error) {
+	lots, err := r.lotRepo.List(ctx, tenderID)
+	if err != nil {
+		return false, err
+	}
+
+	return len(lots) > 0, nil
+}
+
+// settleLot awards or cancels an open lot and closes the tender once no lot is
+// open. The lots of the tender stay locked meanwhile, so of two lots settled
+// at the same time the later one sees the other and closes the tender.
+func (r *TenderService) settleLot(
+	ctx context.Context,
+	tender *entity.Tender,
+	lotID entity.LotID,
+	status entity.LotStatus,
+	bidID entity.BidId,
+	actorID entity.UserID,
+) (lot *entity.Lot, err error) {
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		lots, err := r.lotRepo.Lock(ctx, tender.ID)
+		if err != nil {
+			return err
+		}
+
+		i := slices.IndexFunc(lots, func(lot *entity.Lot) bool { return lot.ID == lotID })
+		if i < 0 {
+			return ErrLotNotFound
+		}
+
+		lot = lots[i]
+
+		if lot.Status != entity.LotStatusOpen {
+			return fmt.Errorf("%w: lot is %s", ErrLotSettled, lot.Status)
+		}
+
+		if err := r.lotRepo.UpdateStatus(ctx, lotID, status, bidID); err != nil {
+			return err
+		}
+
+		before := *lot
+		lot.Status = status
+		lot.AwardedBidID = bidID
+
+		if err := r.audit.record(ctx, entity.AuditEntityLot, string(lotID), entity.AuditActionStatusChange, tender.OrganizationID, actorID, &before, lot); err != nil {
+			return err
+		}
+
+		open := slices.ContainsFunc(lots, func(lot *entity.Lot) bool { return lot.Status == entity.LotStatusOpen })
+		if !open && tender.Status != entity.TenderStatusClosed {
+			return r.changeStatus(ctx, tender, entity.TenderStatusClosed, actorID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log(ctx).Info("lot settled",
+		zap.String("tenderId", string(tender.ID)),
+		zap.String("lotId", string(lotID)),
+		zap.String("status", string(status)),
+	)
+
+	return lot, nil
+}
+
+// authorizeOwner returns the tender and the user if the user is responsible for its organization.
+func (r *TenderService) authorizeOwner(ctx context.Context, tenderID entity.TenderID, userName string) (*entity.Tender, entity.UserID, error) {
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, "", ErrUserNotExists
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if tender == nil {
+		return nil, "", ErrTenderNotFound
+	}
+
+	users, err := r.organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{tender.OrganizationID})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !slices.Contains(users, userID) {
+		return nil, "", ErrNotEnoughRights
+	}
+
+	return tender, userID, nil
+}
+
+func validateLot(lot *entity.Lot) error {
+	if lot.Name == "" || len(lot.Name) > maxLotNameLength {
+		return fmt.Errorf("%w: lot name must be 1 to %d characters", ErrWrongInputFormat, maxLotNameLength)
+	}
+
+	if lot.Quantity <= 0 {
+		return fmt.Errorf("%w: quantity must be positive", ErrWrongInputFormat)
+	}
+
+	if !lot.Budget.Currency.Valid() || lot.Budget.Amount <= 0 {
+		return fmt.Errorf("%w: budget must be a positive amount in an ISO 4217 currency", ErrWrongInputFormat)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"avito2024/internal/app/core/entity"
+)
+
+// withLots splits the fixture tender into lots l1 and l2, with l2 in status.
+func (f *fixture) withLots(status entity.LotStatus) {
+	budget := entity.Money{Amount: 100000, Currency: "RUB"}
+	f.lots.lots["l1"] = &entity.Lot{ID: "l1", TenderID: testTenderID, Name: "foundation", Budget: budget, Status: entity.LotStatusOpen}
+	f.lots.lots["l2"] = &entity.Lot{ID: "l2", TenderID: testTenderID, Name: "electrical", Budget: budget, Status: status}
+}
+
+func TestSubmitDecisionAwardsLot(t *testing.T) {
+	tests := []struct {
+		name       string
+		lotID      entity.LotID
+		other      entity.LotStatus
+		wantErr    error
+		wantLot    entity.LotStatus
+		wantTender entity.TenderStatus
+		wantClosed bool
+	}{
+		{
+			name:       "while another lot is open",
+			lotID:      "l1",
+			other:      entity.LotStatusOpen,
+			wantLot:    entity.LotStatusAwarded,
+			wantTender: entity.TenderStatusPublished,
+		},
+		{
+			name:       "the last open lot",
+			lotID:      "l1",
+			other:      entity.LotStatusCanceled,
+			wantLot:    entity.LotStatusAwarded,
+			wantTender: entity.TenderStatusClosed,
+			wantClosed: true,
+		},
+		{
+			name:       "a settled lot",
+			lotID:      "l2",
+			other:      entity.LotStatusAwarded,
+			wantErr:    ErrLotSettled,
+			wantTender: entity.TenderStatusPublished,
+		},
+		{
+			name:       "a bid without a lot",
+			other:      entity.LotStatusOpen,
+			wantErr:    ErrWrongInputFormat,
+			wantLot:    entity.LotStatusOpen,
+			wantTender: entity.TenderStatusPublished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.withLots(tt.other)
+			f.bids.bids["b1"] = &entity.Bid{
+				ID:         "b1",
+				TenderID:   testTenderID,
+				LotID:      tt.lotID,
+				AuthorType: entity.BidAuthorUser,
+				AuthorID:   entity.BidAuthorId(testAuthorID),
+			}
+
+			_, err := f.bidService.SubmitDecision(context.Background(), "b1", string(entity.BidReviewApproved), "owner")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SubmitDecision() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantLot != "" {
+				if lot := f.lots.lots["l1"]; lot.Status != tt.wantLot {
+					t.Errorf("lot status = %s, want %s", lot.Status, tt.wantLot)
+				}
+			}
+
+			if tt.wantErr == nil && f.lots.lots["l1"].AwardedBidID != "b1" {
+				t.Errorf("AwardedBidID = %q, want b1", f.lots.lots["l1"].AwardedBidID)
+			}
+
+			if status := f.tenders.tenders[testTenderID].Status; status != tt.wantTender {
+				t.Errorf("tender status = %s, want %s", status, tt.wantTender)
+			}
+
+			closed := false
+			for _, event := range f.events.events {
+				closed = closed || event.Type == entity.EventTenderClosed
+			}
+
+			if closed != tt.wantClosed {
+				t.Errorf("tender.closed published = %v, want %v", closed, tt.wantClosed)
+			}
+		})
+	}
+}
+
+func TestCancelLot(t *testing.T) {
+	tests := []struct {
+		name       string
+		userName   string
+		other      entity.LotStatus
+		wantErr    error
+		wantLot    entity.LotStatus
+		wantTender entity.TenderStatus
+	}{
+		{
+			name:       "by the owner",
+			userName:   "owner",
+			other:      entity.LotStatusOpen,
+			wantLot:    entity.LotStatusCanceled,
+			wantTender: entity.TenderStatusPublished,
+		},
+		{
+			name:       "the last open lot",
+			userName:   "owner",
+			other:      entity.LotStatusAwarded,
+			wantLot:    entity.LotStatusCanceled,
+			wantTender: entity.TenderStatusClosed,
+		},
+		{
+			name:       "by a user outside the tender organization",
+			userName:   "supplier",
+			other:      entity.LotStatusOpen,
+			wantErr:    ErrNotEnoughRights,
+			wantLot:    entity.LotStatusOpen,
+			wantTender: entity.TenderStatusPublished,
+		},
+		{
+			name:       "by an unknown user",
+			userName:   "nobody",
+			other:      entity.LotStatusOpen,
+			wantErr:    ErrUserNotExists,
+			wantLot:    entity.LotStatusOpen,
+			wantTender: entity.TenderStatusPublished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.withLots(tt.other)
+
+			_, err := f.tenderService.CancelLot(context.Background(), testTenderID, "l1", tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CancelLot() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if lot := f.lots.lots["l1"]; lot.Status != tt.wantLot {
+				t.Errorf("lot status = %s, want %s", lot.Status, tt.wantLot)
+			}
+
+			if status := f.tenders.tenders[testTenderID].Status; status != tt.wantTender {
+				t.Errorf("tender status = %s, want %s", status, tt.wantTender)
+			}
+		})
+	}
+}
+
+func TestBidCreateChecksLot(t *testing.T) {
+	tests := []struct {
+		name    string
+		lotID   entity.LotID
+		wantErr error
+	}{
+		{name: "an open lot", lotID: "l1"},
+		{name: "a settled lot", lotID: "l2", wantErr: ErrLotSettled},
+		{name: "no lot on a tender with lots", wantErr: ErrWrongInputFormat},
+		{name: "a lot of another tender", lotID: "l3", wantErr: ErrLotNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.withLots(entity.LotStatusAwarded)
+			f.lots.lots["l3"] = &entity.Lot{ID: "l3", TenderID: "another", Status: entity.LotStatusOpen}
+
+			err := f.bidService.Create(context.Background(), &entity.Bid{
+				Name:       "offer",
+				TenderID:   testTenderID,
+				LotID:      tt.lotID,
+				AuthorType: entity.BidAuthorUser,
+				AuthorID:   entity.BidAuthorId(testAuthorID),
+				Offer:      offer(),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
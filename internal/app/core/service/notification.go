@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+var errNotificationQueueFull = errors.New("notification queue is full")
+
+// NotificationService tells users about the events that concern them. Events
+// are queued by Handle and delivered by Run over every notifier the
+// preferences of the recipient allow. The user who caused an event is not notified.
+type NotificationService struct {
+	notificationRepo port.NotificationRepo
+	notifiers        []port.Notifier
+	tenderRepo       port.TenderRepo
+	bidRepo          port.BidRepo
+	questionRepo     port.QuestionRepo
+	userRepo         port.UserRepo
+	organizationRepo port.OrganizationRepo
+	queue            chan *entity.Event
+	logger           *zap.Logger
+}
+
+func NewNotificationService(
+	notificationRepo port.NotificationRepo,
+	notifiers []port.Notifier,
+	tenderRepo port.TenderRepo,
+	bidRepo port.BidRepo,
+	questionRepo port.QuestionRepo,
+	userRepo port.UserRepo,
+	orgRepo port.OrganizationRepo,
+	queueSize int,
+	logger *zap.Logger,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		notifiers:        notifiers,
+		tenderRepo:       tenderRepo,
+		bidRepo:          bidRepo,
+		questionRepo:     questionRepo,
+		userRepo:         userRepo,
+		organizationRepo: orgRepo,
+		queue:            make(chan *entity.Event, queueSize),
+		logger:           logger.Named("notification"),
+	}
+}
+
+// Handle queues the event for delivery without waiting for it, subscribe it to the event bus.
+func (r *NotificationService) Handle(_ context.Context, event *entity.Event) error {
+	select {
+	case r.queue <- event:
+		return nil
+	default:
+		return errNotificationQueueFull
+	}
+}
+
+// Run delivers the queued events until ctx is done.
+func (r *NotificationService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-r.queue:
+			if err := r.dispatch(ctx, event); err != nil {
+				r.logger.Error("notify failed",
+					zap.String("event", string(event.Type)),
+					zap.String("tenderId", string(event.TenderID)),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// Inbox returns the in-app notifications of the user, the newest first.
+func (r *NotificationService) Inbox(
+	ctx context.Context,
+	userName string,
+	unreadOnly bool,
+	limitOffset *entity.RequestLimitOffset,
+) (_ []*entity.Notification, err error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.Inbox")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	return r.notificationRepo.List(ctx, userID, unreadOnly, limitOffset)
+}
+
+func (r *NotificationService) MarkRead(ctx context.Context, notificationID entity.NotificationID, userName string) (err error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.MarkRead")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return ErrUserNotExists
+	}
+
+	found, err := r.notificationRepo.MarkRead(ctx, userID, notificationID, time.Now())
+	if err != nil {
+		return fmt.Errorf("mark notification read: %w", err)
+	}
+
+	if !found {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (r *NotificationService) Preferences(ctx context.Context, userName string) (_ *entity.NotificationPreferences, err error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.Preferences")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	preferences, err := r.notificationRepo.ReadPreferences(ctx, []entity.UserID{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if prefs, ok := preferences[userID]; ok {
+		return prefs, nil
+	}
+
+	return &entity.NotificationPreferences{}, nil
+}
+
+// SetPreferences replaces the preferences of the user.
+func (r *NotificationService) SetPreferences(
+	ctx context.Context,
+	preferences *entity.NotificationPreferences,
+	userName string,
+) (_ *entity.NotificationPreferences, err error) {
+	ctx, span := tracer.Start(ctx, "NotificationService.SetPreferences")
+	defer func() { tracing.End(span, err) }()
+
+	if err := r.validatePreferences(preferences); err != nil {
+		return nil, err
+	}
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	if err := r.notificationRepo.SavePreferences(ctx, userID, preferences); err != nil {
+		return nil, fmt.Errorf("save notification preferences: %w", err)
+	}
+
+	r.log(ctx).Info("notification preferences saved", zap.String("userId", string(userID)))
+
+	return preferences, nil
+}
+
+func (r *NotificationService) validatePreferences(preferences *entity.NotificationPreferences) error {
+	if preferences.Email != "" {
+		address, err := mail.ParseAddress(preferences.Email)
+		if err != nil || address.Name != "" || len(preferences.Email) > 254 {
+			return fmt.Errorf("%w: email must be a plain email address", ErrWrongInputFormat)
+		}
+	}
+
+	for channel, events := range preferences.Disabled {
+		if !slices.ContainsFunc(r.notifiers, func(notifier port.Notifier) bool { return notifier.Channel() == channel }) {
+			return fmt.Errorf("%w: unknown notification channel %q", ErrWrongInputFormat, channel)
+		}
+
+		for _, event := range events {
+			if !slices.Contains(entity.EventTypes, event) {
+				return fmt.Errorf("%w: unknown event %q", ErrWrongInputFormat, event)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *NotificationService) dispatch(ctx context.Context, event *entity.Event) error {
+	tender, err := r.tenderRepo.Read(ctx, event.TenderID)
+	if err != nil {
+		return err
+	}
+
+	if tender == nil {
+		return nil
+	}
+
+	notification, recipients, err := r.compose(ctx, event, tender)
+	if err != nil {
+		return err
+	}
+
+	recipients = slices.DeleteFunc(recipients, func(userID entity.UserID) bool { return userID == event.ActorID })
+	slices.Sort(recipients)
+	recipients = slices.Compact(recipients)
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	preferences, err := r.notificationRepo.ReadPreferences(ctx, recipients)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range recipients {
+		n := *notification
+		n.ID = entity.NotificationID(uuid.NewString())
+		n.UserID = userID
+
+		for _, notifier := range r.notifiers {
+			if !preferences[userID].Allows(notifier.Channel(), event.Type) {
+				continue
+			}
+
+			if err := notifier.Notify(ctx, &n, preferences[userID]); err != nil {
+				r.logger.Error("notification not delivered",
+					zap.String("channel", string(notifier.Channel())),
+					zap.String("userId", string(userID)),
+					zap.String("event", string(event.Type)),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// compose writes the notification about the event and finds who receives it.
+func (r *NotificationService) compose(
+	ctx context.Context,
+	event *entity.Event,
+	tender *entity.Tender,
+) (*entity.Notification, []entity.UserID, error) {
+	notification := &entity.Notification{
+		Event:      event.Type,
+		TenderID:   tender.ID,
+		BidID:      event.BidID,
+		QuestionID: event.QuestionID,
+		CreatedAt:  event.OccurredAt,
+	}
+
+	switch event.Type {
+	case entity.EventTenderEdited:
+		notification.Subject = fmt.Sprintf("Tender %q was edited", tender.Name)
+		notification.Text = fmt.Sprintf("The tender %q you have bid on was edited, check whether your bid still fits its terms.", tender.Name)
+
+		bids, err := r.bidRepo.ReadTenderBids(ctx, tender.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		recipients, err := r.bidAuthors(ctx, bids)
+
+		return notification, recipients, err
+	case entity.EventTenderPublished, entity.EventTenderClosed:
+		change := "published"
+		if event.Type == entity.EventTenderClosed {
+			change = "closed"
+		}
+
+		notification.Subject = fmt.Sprintf("Tender %q was %s", tender.Name, change)
+		notification.Text = fmt.Sprintf("The tender %q you have bid on was %s.", tender.Name, change)
+
+		bids, err := r.bidRepo.ReadTenderBids(ctx, tender.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		recipients, err := r.bidAuthors(ctx, bids)
+
+		return notification, recipients, err
+	case entity.EventBidCreated:
+		notification.Subject = fmt.Sprintf("New bid on tender %q", tender.Name)
+		notification.Text = fmt.Sprintf("A new bid was made on the tender %q.", tender.Name)
+
+		// The bids of a sealed tender stay unknown to its organization until the deadline.
+		if tender.BidsSealed(event.OccurredAt) {
+			notification.BidID = ""
+		}
+
+		recipients, err := r.organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{tender.OrganizationID})
+
+		return notification, recipients, err
+	case entity.EventBidApproved, entity.EventBidRejected:
+		bid, err := r.bidRepo.ReadBidByID(ctx, event.BidID)
+		if err != nil || bid == nil {
+			return nil, nil, err
+		}
+
+		decision := "approved"
+		if event.Type == entity.EventBidRejected {
+			decision = "rejected"
+		}
+
+		notification.Subject = fmt.Sprintf("Bid %q was %s", bid.Name, decision)
+		notification.Text = fmt.Sprintf("Your bid %q on the tender %q was %s.", bid.Name, tender.Name, decision)
+
+		recipients, err := r.bidAuthors(ctx, []*entity.Bid{bid})
+
+		return notification, recipients, err
+	case entity.EventQuestionAsked:
+		notification.Subject = fmt.Sprintf("New question on tender %q", tender.Name)
+		notification.Text = fmt.Sprintf("A bidder asked a question about the tender %q.", tender.Name)
+
+		recipients, err := r.organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{tender.OrganizationID})
+
+		return notification, recipients, err
+	case entity.EventQuestionAnswered:
+		question, err := r.questionRepo.Read(ctx, event.QuestionID)
+		if err != nil || question == nil {
+			return nil, nil, err
+		}
+
+		notification.Subject = fmt.Sprintf("Question on tender %q answered", tender.Name)
+		notification.Text = fmt.Sprintf("Q: %s\nA: %s", question.Text, question.Answer)
+
+		recipients := []entity.UserID{question.AuthorID}
+
+		if question.Public {
+			bids, err := r.bidRepo.ReadTenderBids(ctx, tender.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			bidders, err := r.bidAuthors(ctx, bids)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			recipients = append(recipients, bidders...)
+		}
+
+		return notification, recipients, nil
+	default:
+		return notification, nil, nil
+	}
+}
+
+// bidAuthors returns the users who made the bids, directly or for their organization.
+func (r *NotificationService) bidAuthors(ctx context.Context, bids []*entity.Bid) ([]entity.UserID, error) {
+	var (
+		users         []entity.UserID
+		organizations []entity.OrganizationID
+	)
+
+	for _, bid := range bids {
+		switch bid.AuthorType {
+		case entity.BidAuthorUser:
+			users = append(users, entity.UserID(bid.AuthorID))
+		case entity.BidAuthorOrganization:
+			organizations = append(organizations, entity.OrganizationID(bid.AuthorID))
+		}
+	}
+
+	if len(organizations) == 0 {
+		return users, nil
+	}
+
+	responsible, err := r.organizationRepo.FindResponsibleUsers(ctx, organizations)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(users, responsible...), nil
+}
+
+func (r *NotificationService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
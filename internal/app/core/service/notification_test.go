@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+)
+
+func TestComposeTenderStatusEvents(t *testing.T) {
+	const (
+		ownerID        = entity.UserID("6f1c3a52-0a3e-4c1f-9d4b-1b2e3c4d5e6f")
+		authorID       = entity.UserID("0e9d8c7b-6a5f-4e3d-8c2b-1a0f9e8d7c6b")
+		supplierID     = entity.UserID("5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d")
+		organizationID = entity.OrganizationID("9b8a7c6d-5e4f-4321-9fed-cba987654321")
+		supplierOrgID  = entity.OrganizationID("1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d")
+		tenderID       = entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a")
+		otherTenderID  = entity.TenderID("4d3c2b1a-0f9e-4d8c-7b6a-5f4e3d2c1b0a")
+	)
+
+	notifications := NewNotificationService(
+		nil,
+		nil,
+		nil,
+		&bidRepoStub{bids: map[entity.BidId]*entity.Bid{
+			"b1": {ID: "b1", TenderID: tenderID, AuthorType: entity.BidAuthorUser, AuthorID: entity.BidAuthorId(authorID)},
+			"b2": {ID: "b2", TenderID: tenderID, AuthorType: entity.BidAuthorOrganization, AuthorID: entity.BidAuthorId(supplierOrgID)},
+			"b3": {ID: "b3", TenderID: otherTenderID, AuthorType: entity.BidAuthorUser, AuthorID: entity.BidAuthorId(ownerID)},
+		}},
+		nil,
+		userRepoStub{},
+		organizationRepoStub{responsible: map[entity.UserID][]entity.OrganizationID{
+			ownerID:    {organizationID},
+			supplierID: {supplierOrgID},
+		}},
+		1,
+		zap.NewNop(),
+	)
+
+	tender := &entity.Tender{ID: tenderID, Name: "roads", OrganizationID: organizationID}
+
+	tests := []struct {
+		event    entity.EventType
+		wantText string
+	}{
+		{event: entity.EventTenderPublished, wantText: `The tender "roads" you have bid on was published.`},
+		{event: entity.EventTenderClosed, wantText: `The tender "roads" you have bid on was closed.`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.event), func(t *testing.T) {
+			event := &entity.Event{Type: tt.event, TenderID: tenderID, ActorID: ownerID, OccurredAt: time.Now()}
+
+			notification, recipients, err := notifications.compose(context.Background(), event, tender)
+			if err != nil {
+				t.Fatalf("compose() error = %v", err)
+			}
+
+			if notification.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", notification.Text, tt.wantText)
+			}
+
+			slices.Sort(recipients)
+			if want := []entity.UserID{authorID, supplierID}; !slices.Equal(recipients, want) {
+				t.Errorf("recipients = %v, want the bidders %v", recipients, want)
+			}
+		})
+	}
+}
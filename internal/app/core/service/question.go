@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+const maxQuestionLength = 2000
+
+// QuestionService keeps the clarification threads of tenders. Bidders ask,
+// the tender organization answers, and every change is published as an event.
+type QuestionService struct {
+	tenderService    *TenderService
+	questionRepo     port.QuestionRepo
+	tenderRepo       port.TenderRepo
+	userRepo         port.UserRepo
+	organizationRepo port.OrganizationRepo
+	events           port.EventPublisher
+	logger           *zap.Logger
+}
+
+func NewQuestionService(
+	questionRepo port.QuestionRepo,
+	tenderService *TenderService,
+	tenderRepo port.TenderRepo,
+	userRepo port.UserRepo,
+	orgRepo port.OrganizationRepo,
+	events port.EventPublisher,
+	logger *zap.Logger,
+) *QuestionService {
+	return &QuestionService{
+		tenderService:    tenderService,
+		questionRepo:     questionRepo,
+		tenderRepo:       tenderRepo,
+		userRepo:         userRepo,
+		organizationRepo: orgRepo,
+		events:           events,
+		logger:           logger.Named("question"),
+	}
+}
+
+// Ask adds a question to a published tender while it accepts bids. The
+// tender organization cannot ask its own tender.
+func (r *QuestionService) Ask(ctx context.Context, tenderID entity.TenderID, text, userName string) (_ *entity.Question, err error) {
+	ctx, span := tracer.Start(ctx, "QuestionService.Ask")
+	defer func() { tracing.End(span, err) }()
+
+	if text == "" || utf8.RuneCountInString(text) > maxQuestionLength {
+		return nil, fmt.Errorf("%w: text must be 1 to %d characters", ErrWrongInputFormat, maxQuestionLength)
+	}
+
+	tender, userID, owner, err := r.authorize(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner {
+		return nil, ErrNotEnoughRights
+	}
+
+	now := time.Now()
+
+	if tender.Status != entity.TenderStatusPublished || tender.BiddingClosed(now) {
+		return nil, ErrBiddingClosed
+	}
+
+	question := &entity.Question{
+		ID:        entity.QuestionID(uuid.NewString()),
+		TenderID:  tenderID,
+		AuthorID:  userID,
+		Text:      text,
+		CreatedAt: now,
+	}
+
+	if err := r.questionRepo.Create(ctx, question); err != nil {
+		return nil, fmt.Errorf("create question: %w", err)
+	}
+
+	r.log(ctx).Info("question asked",
+		zap.String("tenderId", string(tenderID)),
+		zap.String("questionId", string(question.ID)),
+	)
+
+	r.events.Publish(ctx, &entity.Event{
+		Type:       entity.EventQuestionAsked,
+		TenderID:   tenderID,
+		QuestionID: question.ID,
+		ActorID:    userID,
+		OccurredAt: now,
+	})
+
+	return question, nil
+}
+
+// Answer answers the question on behalf of the tender organization. An answer
+// can be corrected or made public later by answering again.
+func (r *QuestionService) Answer(
+	ctx context.Context,
+	tenderID entity.TenderID,
+	questionID entity.QuestionID,
+	answer *entity.QuestionAnswer,
+	userName string,
+) (_ *entity.Question, err error) {
+	ctx, span := tracer.Start(ctx, "QuestionService.Answer")
+	defer func() { tracing.End(span, err) }()
+
+	if answer.Answer == "" || utf8.RuneCountInString(answer.Answer) > maxQuestionLength {
+		return nil, fmt.Errorf("%w: answer must be 1 to %d characters", ErrWrongInputFormat, maxQuestionLength)
+	}
+
+	_, userID, owner, err := r.authorize(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !owner {
+		return nil, ErrNotEnoughRights
+	}
+
+	question, err := r.questionRepo.Read(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if question == nil || question.TenderID != tenderID {
+		return nil, ErrQuestionNotFound
+	}
+
+	now := time.Now()
+
+	if err := r.questionRepo.Answer(ctx, questionID, answer.Answer, answer.Public, now); err != nil {
+		return nil, fmt.Errorf("answer question: %w", err)
+	}
+
+	question.Answer = answer.Answer
+	question.Public = answer.Public
+	question.AnsweredAt = &now
+
+	r.log(ctx).Info("question answered",
+		zap.String("tenderId", string(tenderID)),
+		zap.String("questionId", string(questionID)),
+		zap.Bool("public", answer.Public),
+	)
+
+	r.events.Publish(ctx, &entity.Event{
+		Type:       entity.EventQuestionAnswered,
+		TenderID:   tenderID,
+		QuestionID: questionID,
+		ActorID:    userID,
+		OccurredAt: now,
+	})
+
+	return question, nil
+}
+
+// List returns the questions on the tender. The tender organization sees all
+// of them, anyone else sees their own questions and the public answers, with
+// the askers of the latter left out.
+func (r *QuestionService) List(ctx context.Context, tenderID entity.TenderID, userName string) (_ []*entity.Question, err error) {
+	ctx, span := tracer.Start(ctx, "QuestionService.List")
+	defer func() { tracing.End(span, err) }()
+
+	_, userID, owner, err := r.authorize(ctx, tenderID, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner {
+		return r.questionRepo.List(ctx, tenderID)
+	}
+
+	questions, err := r.questionRepo.ListFor(ctx, tenderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, question := range questions {
+		if question.AuthorID != userID {
+			question.AuthorID = ""
+		}
+	}
+
+	return questions, nil
+}
+
+// authorize returns the tender if the user can see it and whether the user is
+// responsible for its organization.
+func (r *QuestionService) authorize(
+	ctx context.Context,
+	tenderID entity.TenderID,
+	userName string,
+) (*entity.Tender, entity.UserID, bool, error) {
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, "", false, ErrUserNotExists
+	}
+
+	tender, err := r.tenderRepo.Read(ctx, tenderID)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	if tender == nil {
+		return nil, "", false, ErrTenderNotFound
+	}
+
+	users, err := r.organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{tender.OrganizationID})
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	if slices.Contains(users, userID) {
+		return tender, userID, true, nil
+	}
+
+	// Drafts are only visible to their organization.
+	if tender.Status == entity.TenderStatusCreated {
+		return nil, "", false, ErrTenderNotFound
+	}
+
+	if err := r.tenderService.requireVisible(ctx, tender, userID); err != nil {
+		return nil, "", false, err
+	}
+
+	return tender, userID, false, nil
+}
+
+func (r *QuestionService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+type questionRepoStub struct {
+	port.QuestionRepo
+	questions map[entity.QuestionID]*entity.Question
+}
+
+func (r *questionRepoStub) Create(_ context.Context, question *entity.Question) error {
+	created := *question
+	r.questions[question.ID] = &created
+
+	return nil
+}
+
+func (r *questionRepoStub) Read(_ context.Context, questionID entity.QuestionID) (*entity.Question, error) {
+	question, ok := r.questions[questionID]
+	if !ok {
+		return nil, nil
+	}
+
+	read := *question
+
+	return &read, nil
+}
+
+func (r *questionRepoStub) List(ctx context.Context, tenderID entity.TenderID) ([]*entity.Question, error) {
+	return r.ListFor(ctx, tenderID, "")
+}
+
+func (r *questionRepoStub) ListFor(_ context.Context, tenderID entity.TenderID, userID entity.UserID) ([]*entity.Question, error) {
+	var questions []*entity.Question
+
+	for _, question := range r.questions {
+		if question.TenderID != tenderID {
+			continue
+		}
+
+		if userID == "" || question.AuthorID == userID || question.Public && question.AnsweredAt != nil {
+			read := *question
+			questions = append(questions, &read)
+		}
+	}
+
+	return questions, nil
+}
+
+func (r *questionRepoStub) Answer(_ context.Context, questionID entity.QuestionID, answer string, public bool, answeredAt time.Time) error {
+	question := r.questions[questionID]
+	question.Answer = answer
+	question.Public = public
+	question.AnsweredAt = &answeredAt
+
+	return nil
+}
+
+// newQuestionService returns the questions on the fixture tender: q1 asked by
+// author and answered publicly, q2 asked by supplier and answered privately,
+// and q3 asked by author and not answered yet.
+func newQuestionService(f *fixture) (*QuestionService, *questionRepoStub) {
+	answeredAt := time.Now()
+	repo := &questionRepoStub{questions: map[entity.QuestionID]*entity.Question{
+		"q1": {ID: "q1", TenderID: testTenderID, AuthorID: testAuthorID, Text: "deadline?", Answer: "May", Public: true, AnsweredAt: &answeredAt},
+		"q2": {ID: "q2", TenderID: testTenderID, AuthorID: testSupplierID, Text: "our discount?", Answer: "no", AnsweredAt: &answeredAt},
+		"q3": {ID: "q3", TenderID: testTenderID, AuthorID: testAuthorID, Text: "budget?"},
+	}}
+	users := userRepoStub{"owner": testOwnerID, "author": testAuthorID, "supplier": testSupplierID, "stranger": testStrangerID}
+	organizations := organizationRepoStub{responsible: map[entity.UserID][]entity.OrganizationID{
+		testOwnerID:    {testOrganizationID},
+		testSupplierID: {testSupplierOrgID},
+	}}
+
+	return NewQuestionService(repo, f.tenderService, f.tenders, users, organizations, f.events, zap.NewNop()), repo
+}
+
+func TestAsk(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		text     string
+		change   func(tender *entity.Tender)
+		wantErr  error
+	}{
+		{name: "by a bidder", userName: "supplier", text: "delivery address?"},
+		{name: "by the tender owner", userName: "owner", text: "delivery address?", wantErr: ErrNotEnoughRights},
+		{name: "without text", userName: "supplier", wantErr: ErrWrongInputFormat},
+		{
+			name:     "on a draft",
+			userName: "supplier",
+			text:     "delivery address?",
+			change:   func(tender *entity.Tender) { tender.Status = entity.TenderStatusCreated },
+			wantErr:  ErrTenderNotFound,
+		},
+		{
+			name:     "on a closed tender",
+			userName: "supplier",
+			text:     "delivery address?",
+			change:   func(tender *entity.Tender) { tender.Status = entity.TenderStatusClosed },
+			wantErr:  ErrBiddingClosed,
+		},
+		{
+			name:     "after the deadline",
+			userName: "supplier",
+			text:     "delivery address?",
+			change: func(tender *entity.Tender) {
+				deadline := time.Now().Add(-time.Minute)
+				tender.BidDeadline = &deadline
+			},
+			wantErr: ErrBiddingClosed,
+		},
+		{
+			name:     "on an invitation-only tender without an invitation",
+			userName: "stranger",
+			text:     "delivery address?",
+			change:   func(tender *entity.Tender) { tender.InviteOnly = true },
+			wantErr:  ErrTenderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			questions, repo := newQuestionService(f)
+
+			if tt.change != nil {
+				tt.change(f.tenders.tenders[testTenderID])
+			}
+
+			question, err := questions.Ask(context.Background(), testTenderID, tt.text, tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Ask() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if len(repo.questions) != 3 || len(f.events.events) != 0 {
+					t.Errorf("a rejected question was stored or published")
+				}
+
+				return
+			}
+
+			if question.AuthorID != testSupplierID || repo.questions[question.ID] == nil {
+				t.Errorf("question = %+v, want it stored with the asker", question)
+			}
+
+			if len(f.events.events) != 1 || f.events.events[0].Type != entity.EventQuestionAsked {
+				t.Errorf("events = %v, want one %s", f.events.events, entity.EventQuestionAsked)
+			}
+		})
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	tests := []struct {
+		name       string
+		userName   string
+		tenderID   entity.TenderID
+		questionID entity.QuestionID
+		wantErr    error
+	}{
+		{name: "by the tender owner", userName: "owner", tenderID: testTenderID, questionID: "q3"},
+		{name: "by the asker", userName: "author", tenderID: testTenderID, questionID: "q3", wantErr: ErrNotEnoughRights},
+		{name: "an unknown question", userName: "owner", tenderID: testTenderID, questionID: "q4", wantErr: ErrQuestionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			questions, repo := newQuestionService(f)
+
+			_, err := questions.Answer(context.Background(), tt.tenderID, tt.questionID, &entity.QuestionAnswer{Answer: "June", Public: true}, tt.userName)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Answer() error = %v, want %v", err, tt.wantErr)
+			}
+
+			answered := repo.questions["q3"].AnsweredAt != nil
+			if answered != (tt.wantErr == nil) {
+				t.Errorf("q3 answered = %v, want %v", answered, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestListQuestions(t *testing.T) {
+	tests := []struct {
+		userName string
+		// want maps the listed questions to the askers shown.
+		want map[entity.QuestionID]entity.UserID
+	}{
+		{userName: "owner", want: map[entity.QuestionID]entity.UserID{"q1": testAuthorID, "q2": testSupplierID, "q3": testAuthorID}},
+		{userName: "author", want: map[entity.QuestionID]entity.UserID{"q1": testAuthorID, "q3": testAuthorID}},
+		{userName: "supplier", want: map[entity.QuestionID]entity.UserID{"q1": "", "q2": testSupplierID}},
+		{userName: "stranger", want: map[entity.QuestionID]entity.UserID{"q1": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userName, func(t *testing.T) {
+			f := newFixture()
+			questions, _ := newQuestionService(f)
+
+			list, err := questions.List(context.Background(), testTenderID, tt.userName)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			got := make(map[entity.QuestionID]entity.UserID, len(list))
+			for _, question := range list {
+				got[question.ID] = question.AuthorID
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", got, tt.want)
+			}
+
+			for id, authorID := range tt.want {
+				if got[id] != authorID {
+					t.Errorf("List() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
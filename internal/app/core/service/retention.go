@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+const retentionWorker = "retention"
+
+// RetentionService purges tenders and bids once they have been deleted for
+// longer than the retention period and moves old closed tenders to the archive.
+type RetentionService struct {
+	tenderRepo    port.TenderRepo
+	bidRepo       port.BidRepo
+	blobs         port.BlobStore
+	deletedFor    time.Duration
+	archiveMonths int
+	batchSize     int
+	logger        *zap.Logger
+}
+
+// NewRetentionService purges what was deleted more than deletedFor ago and
+// archives closed tenders created more than archiveMonths ago, batchSize
+// tenders at a time. An archiveMonths of zero disables archival.
+func NewRetentionService(
+	tenderRepo port.TenderRepo,
+	bidRepo port.BidRepo,
+	blobs port.BlobStore,
+	deletedFor time.Duration,
+	archiveMonths int,
+	batchSize int,
+	logger *zap.Logger,
+) *RetentionService {
+	return &RetentionService{
+		tenderRepo:    tenderRepo,
+		bidRepo:       bidRepo,
+		blobs:         blobs,
+		deletedFor:    deletedFor,
+		archiveMonths: archiveMonths,
+		batchSize:     batchSize,
+		logger:        logger.Named("retention"),
+	}
+}
+
+// Run purges and archives every interval until ctx is done.
+func (r *RetentionService) Run(ctx context.Context, monitor *WorkerMonitor, interval time.Duration) {
+	monitor.Register(retentionWorker, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		monitor.Beat(retentionWorker, r.run(ctx, time.Now()))
+	}
+}
+
+func (r *RetentionService) run(ctx context.Context, now time.Time) error {
+	deletedBefore := now.Add(-r.deletedFor)
+
+	tenders, tenderAttachments, err := r.tenderRepo.Purge(ctx, deletedBefore)
+	if err != nil {
+		r.logger.Error("purge deleted tenders failed", zap.Error(err))
+		return err
+	}
+
+	r.deleteBlobs(ctx, tenderAttachments)
+
+	bids, bidAttachments, err := r.bidRepo.Purge(ctx, deletedBefore)
+	if err != nil {
+		r.logger.Error("purge deleted bids failed", zap.Error(err))
+		return err
+	}
+
+	r.deleteBlobs(ctx, bidAttachments)
+
+	if tenders > 0 || bids > 0 {
+		r.logger.Info("deleted tenders and bids purged", zap.Int64("tenders", tenders), zap.Int64("bids", bids))
+	}
+
+	if r.archiveMonths == 0 {
+		return nil
+	}
+
+	// Batches keep each statement short, the next tick picks up whatever is left.
+	archived, err := r.tenderRepo.Archive(ctx, now.AddDate(0, -r.archiveMonths, 0), r.batchSize)
+	if err != nil {
+		r.logger.Error("archive closed tenders failed", zap.Error(err))
+		return err
+	}
+
+	if len(archived) > 0 {
+		r.logger.Info("closed tenders archived", zap.Int("count", len(archived)))
+	}
+
+	return nil
+}
+
+// deleteBlobs removes the contents of purged attachments. Purge has committed
+// by then, so a failure leaves an unreferenced blob rather than a row without content.
+func (r *RetentionService) deleteBlobs(ctx context.Context, attachments []*entity.Attachment) {
+	for _, attachment := range attachments {
+		if err := r.blobs.Delete(ctx, attachment.BlobKey()); err != nil {
+			r.logger.Error("failed to delete attachment content",
+				zap.String("attachmentId", string(attachment.ID)),
+				zap.Error(err),
+			)
+		}
+	}
+}
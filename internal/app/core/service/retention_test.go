@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+type retentionTenderRepoStub struct {
+	port.TenderRepo
+	attachments []*entity.Attachment
+}
+
+func (r *retentionTenderRepoStub) Purge(context.Context, time.Time) (int64, []*entity.Attachment, error) {
+	return 1, r.attachments, nil
+}
+
+type retentionBidRepoStub struct {
+	port.BidRepo
+	attachments []*entity.Attachment
+}
+
+func (r *retentionBidRepoStub) Purge(context.Context, time.Time) (int64, []*entity.Attachment, error) {
+	return int64(len(r.attachments)), r.attachments, nil
+}
+
+type blobStoreStub struct {
+	deleted []string
+}
+
+func (r *blobStoreStub) Put(context.Context, string, io.Reader) error {
+	return nil
+}
+
+func (r *blobStoreStub) Get(context.Context, string) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func (r *blobStoreStub) Delete(_ context.Context, key string) error {
+	r.deleted = append(r.deleted, key)
+	return nil
+}
+
+func TestRetentionDeletesPurgedBlobs(t *testing.T) {
+	blobs := &blobStoreStub{}
+	retention := NewRetentionService(
+		&retentionTenderRepoStub{attachments: []*entity.Attachment{
+			{ID: "a1", TenderID: "t1"},
+			{ID: "a2", TenderID: "t1", BidID: "b1"},
+		}},
+		&retentionBidRepoStub{attachments: []*entity.Attachment{
+			{ID: "a3", TenderID: "t2", BidID: "b2"},
+		}},
+		blobs,
+		time.Hour,
+		0,
+		100,
+		zap.NewNop(),
+	)
+
+	if err := retention.run(context.Background(), time.Now()); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	want := []string{"tenders/t1/a1", "bids/b1/a2", "bids/b2/a3"}
+	if !slices.Equal(blobs.deleted, want) {
+		t.Errorf("deleted blobs = %v, want %v", blobs.deleted, want)
+	}
+}
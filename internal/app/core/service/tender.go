@@ -2,35 +2,192 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
 
 	"avito2024/internal/app/core/entity"
 	"avito2024/internal/app/core/port"
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
 )
 
 type TenderService struct {
 	userRepo         port.UserRepo
 	organizationRepo port.OrganizationRepo
 	tenderRepo       port.TenderRepo
+	lotRepo          port.LotRepo
+	invitationRepo   port.InvitationRepo
+	auctionRepo      port.AuctionRepo
+	catalog          *CatalogService
+	audit            *AuditService
+	transactor       port.Transactor
+	metrics          port.Metrics
+	events           port.EventPublisher
+	logger           *zap.Logger
 }
 
-func NewTenderService(repo port.TenderRepo, userRepo port.UserRepo, orgRepo port.OrganizationRepo) *TenderService {
+func NewTenderService(
+	repo port.TenderRepo,
+	lotRepo port.LotRepo,
+	invitationRepo port.InvitationRepo,
+	auctionRepo port.AuctionRepo,
+	catalog *CatalogService,
+	audit *AuditService,
+	userRepo port.UserRepo,
+	orgRepo port.OrganizationRepo,
+	transactor port.Transactor,
+	metrics port.Metrics,
+	events port.EventPublisher,
+	logger *zap.Logger,
+) *TenderService {
 	return &TenderService{
 		tenderRepo:       repo,
+		lotRepo:          lotRepo,
+		invitationRepo:   invitationRepo,
+		auctionRepo:      auctionRepo,
+		catalog:          catalog,
+		audit:            audit,
 		userRepo:         userRepo,
 		organizationRepo: orgRepo,
+		transactor:       transactor,
+		metrics:          metrics,
+		events:           events,
+		logger:           logger.Named("tender"),
 	}
 }
 
-func (r *TenderService) Create(ctx context.Context, tender *entity.Tender, username string) error {
+func (r *TenderService) Create(ctx context.Context, tender *entity.Tender, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err := r.create(ctx, tender, username); err != nil {
+		return err
+	}
+
+	r.created(ctx, tender)
+
+	return nil
+}
+
+// Import creates every row with the rules of Create and reports the outcome of each.
+// An atomic import runs in one transaction and is rolled back if any row fails.
+func (r *TenderService) Import(ctx context.Context, rows []*entity.RequestTender, atomic bool) (_ *entity.TenderImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.Import")
+	defer func() { tracing.End(span, err) }()
+
+	report := &entity.TenderImportReport{
+		Atomic: atomic,
+		Rows:   make([]*entity.TenderImportRow, 0, len(rows)),
+	}
+
+	if !atomic {
+		for i, row := range rows {
+			report.Rows = append(report.Rows, r.importRow(ctx, i+1, row, false))
+		}
+
+		report.Count()
+		report.Committed = report.Created > 0
+		r.imported(ctx, rows, report)
+
+		return report, nil
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, row := range rows {
+			report.Rows = append(report.Rows, r.importRow(ctx, i+1, row, true))
+		}
+
+		report.Count()
+		if report.Failed > 0 {
+			return errImportRolledBack
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRolledBack) {
+		return nil, fmt.Errorf("import tenders: %w", err)
+	}
+
+	if err != nil {
+		// Nothing was created, IDs of the rows that succeeded point nowhere.
+		for _, row := range report.Rows {
+			row.TenderID = ""
+		}
+		report.Created = 0
+
+		return report, nil
+	}
+
+	report.Committed = true
+	r.imported(ctx, rows, report)
+
+	return report, nil
+}
+
+// importRow creates a single row. Inside a transaction the row gets a savepoint,
+// a failed statement would abort the transaction for the rows after it otherwise.
+func (r *TenderService) importRow(ctx context.Context, n int, row *entity.RequestTender, inTransaction bool) *entity.TenderImportRow {
+	create := func(ctx context.Context) error {
+		return r.create(ctx, &row.Tender, row.Username)
+	}
+
+	var err error
+	if inTransaction {
+		err = r.transactor.WithinTransaction(ctx, create)
+	} else {
+		err = create(ctx)
+	}
+
+	if err != nil {
+		return &entity.TenderImportRow{Row: n, Error: err.Error()}
+	}
+
+	return &entity.TenderImportRow{Row: n, TenderID: row.ID}
+}
+
+// imported records the tenders that made it to the database.
+func (r *TenderService) imported(ctx context.Context, rows []*entity.RequestTender, report *entity.TenderImportReport) {
+	for i, row := range report.Rows {
+		if row.Error == "" {
+			r.created(ctx, &rows[i].Tender)
+		}
+	}
+
+	r.log(ctx).Info("tenders imported",
+		zap.Bool("atomic", report.Atomic),
+		zap.Int("created", report.Created),
+		zap.Int("failed", report.Failed),
+	)
+}
+
+func (r *TenderService) create(ctx context.Context, tender *entity.Tender, username string) error {
+	if tender.Name == "" || tender.OrganizationID == "" {
+		return fmt.Errorf("%w: name and organizationId are required", ErrWrongInputFormat)
+	}
+
+	if err := r.catalog.requireServiceType(ctx, tender.ServiceType); err != nil {
+		return err
+	}
+
+	if tender.Budget != nil {
+		if err := validateBudget(tender.Budget); err != nil {
+			return err
+		}
+	}
+
 	tender.ID = entity.TenderID(uuid.NewString())
 	tender.Status = entity.TenderStatus(Created)
 	tender.CreatedAt = time.Now()
 
+	if tender.BidDeadline != nil && !tender.BidDeadline.After(tender.CreatedAt) {
+		return fmt.Errorf("%w: bidDeadline must be in the future", ErrWrongInputFormat)
+	}
+
 	user, err := r.userRepo.FindUserId(ctx, username)
 	if err != nil {
 		return err
@@ -40,23 +197,83 @@ func (r *TenderService) Create(ctx context.Context, tender *entity.Tender, usern
 		return ErrUserNotExists
 	}
 
-	// TODO check if organization exists
+	// The schema rejects an organization that does not exist.
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.tenderRepo.Create(ctx, tender); err != nil {
+			return fmt.Errorf("create tender: %w", err)
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityTender, string(tender.ID), entity.AuditActionCreate, tender.OrganizationID, user, nil, tender)
+	})
+}
+
+func (r *TenderService) created(ctx context.Context, tender *entity.Tender) {
+	r.metrics.TenderCreated()
+	r.log(ctx).Info("tender created", zap.String("tenderId", string(tender.ID)))
+}
+
+// ExportMy calls fn for every tender of the organizations the user is responsible for.
+func (r *TenderService) ExportMy(ctx context.Context, userName string, fn func(*entity.Tender) error) (err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.ExportMy")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil {
+		return err
+	}
+
+	if userID == "" {
+		return ErrUserNotExists
+	}
 
-	if err := r.tenderRepo.Create(ctx, tender); err != nil {
-		return fmt.Errorf("create tender: %w", err)
+	organizations, err := r.organizationRepo.FindOrganizationsByResponsibleUserID(ctx, userID)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if len(organizations) == 0 {
+		return ErrNotEnoughRights
+	}
+
+	return r.tenderRepo.StreamMy(ctx, organizations, fn)
 }
 
-func (r *TenderService) List(ctx context.Context, tenderType []entity.TenderServiceType, limitOffset *entity.RequestLimitOffset) ([]*entity.Tender, error) {
-	if len(tenderType) == 0 {
-		tenderType = append(tenderType, entity.TenderServiceTypeConstruction)
-		tenderType = append(tenderType, entity.TenderServiceTypeDelivery)
-		tenderType = append(tenderType, entity.TenderServiceTypeManufacture)
+// List returns the published tenders of the service types. Invitation-only
+// tenders are listed only for a user who owns them or is invited, none are
+// listed without a user. Hidden budgets are shown to their owners only.
+func (r *TenderService) List(
+	ctx context.Context,
+	tenderType []entity.TenderServiceType,
+	userName string,
+	options *entity.TenderListOptions,
+	limitOffset *entity.RequestLimitOffset,
+) (_ []*entity.Tender, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.List")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateListOptions(options); err != nil {
+		return nil, err
+	}
+
+	var viewer *entity.Viewer
+	if userName != "" {
+		userID, err := r.userRepo.FindUserId(ctx, userName)
+		if err != nil || userID == "" {
+			return nil, ErrUserNotExists
+		}
+
+		if viewer, err = r.viewer(ctx, userID); err != nil {
+			return nil, err
+		}
 	}
 
-	tenders, err := r.tenderRepo.List(ctx, tenderType, limitOffset)
+	// Filtering by a type includes its subtypes, no filter means every type.
+	tenderType, err = r.catalog.expand(ctx, tenderType)
+	if err != nil {
+		return nil, err
+	}
+
+	tenders, err := r.tenderRepo.List(ctx, tenderType, viewer, options, limitOffset)
 	if err != nil {
 		return nil, fmt.Errorf("list tender: %w", err)
 	}
@@ -65,10 +282,24 @@ func (r *TenderService) List(ctx context.Context, tenderType []entity.TenderServ
 		return nil, ErrTenderNotFound
 	}
 
+	redactBudgets(tenders, viewer)
+
 	return tenders, nil
 }
 
-func (r *TenderService) ListMy(ctx context.Context, userName string, limitOffset *entity.RequestLimitOffset) ([]*entity.Tender, error) {
+func (r *TenderService) ListMy(
+	ctx context.Context,
+	userName string,
+	options *entity.TenderListOptions,
+	limitOffset *entity.RequestLimitOffset,
+) (_ []*entity.Tender, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.ListMy")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateListOptions(options); err != nil {
+		return nil, err
+	}
+
 	userID, err := r.userRepo.FindUserId(ctx, userName)
 	if err != nil {
 		return nil, ErrUserNotExists
@@ -79,7 +310,7 @@ func (r *TenderService) ListMy(ctx context.Context, userName string, limitOffset
 		return nil, ErrNotEnoughRights
 	}
 
-	tenders, err := r.tenderRepo.ListMy(ctx, organization, limitOffset)
+	tenders, err := r.tenderRepo.ListMy(ctx, organization, options, limitOffset)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +326,10 @@ func (r *TenderService) GetUserRights(ctx context.Context, tenderID entity.Tende
 
 }
 
-func (r *TenderService) GetStatus(ctx context.Context, tenderID entity.TenderID, userName string) (entity.TenderStatus, error) {
+func (r *TenderService) GetStatus(ctx context.Context, tenderID entity.TenderID, userName string) (_ entity.TenderStatus, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.GetStatus")
+	defer func() { tracing.End(span, err) }()
+
 	userID, err := r.userRepo.FindUserId(ctx, userName)
 	if err != nil {
 		return "", ErrUserNotExists
@@ -128,7 +362,10 @@ func (r *TenderService) GetStatus(ctx context.Context, tenderID entity.TenderID,
 	return tender.Status, nil
 }
 
-func (r *TenderService) SetStatus(ctx context.Context, tenderID entity.TenderID, userName string, status entity.TenderStatus) (*entity.Tender, error) {
+func (r *TenderService) SetStatus(ctx context.Context, tenderID entity.TenderID, userName string, status entity.TenderStatus) (_ *entity.Tender, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.SetStatus")
+	defer func() { tracing.End(span, err) }()
+
 	userID, err := r.userRepo.FindUserId(ctx, userName)
 	if err != nil {
 		return nil, ErrUserNotExists
@@ -154,18 +391,84 @@ func (r *TenderService) SetStatus(ctx context.Context, tenderID entity.TenderID,
 		return nil, ErrNotEnoughRights
 	}
 
-	tender.Status = status
-
-	if err := r.tenderRepo.UpdateStatus(ctx, tenderID, status); err != nil {
+	if err := r.changeStatus(ctx, tender, status, userID); err != nil {
 		return nil, err
 	}
 
 	return tender, nil
 }
 
-func (r *TenderService) Edit(ctx context.Context, tenderID entity.TenderID, update *entity.TenderUpdate, userName string) (*entity.Tender, error) {
-	if entity.NewTenderServiceType(update.ServiceType) == entity.TenderServiceTypeUndefined {
-		return nil, ErrWrongInputFormat
+// tenderStatusEvents are the events published when a tender moves to a status.
+var tenderStatusEvents = map[entity.TenderStatus]entity.EventType{
+	entity.TenderStatusPublished: entity.EventTenderPublished,
+	entity.TenderStatusClosed:    entity.EventTenderClosed,
+}
+
+// changeStatus moves the tender to status, it is the one place tender status transitions are made.
+// The actor is empty when the service makes the transition on its own.
+func (r *TenderService) changeStatus(ctx context.Context, tender *entity.Tender, status entity.TenderStatus, actorID entity.UserID) error {
+	before := *tender
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Edit and AuctionService.Create each check for the other, but may have passed the check concurrently.
+		if status == entity.TenderStatusPublished && tender.Sealed {
+			if err := r.requireNoAuction(ctx, tender.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := r.tenderRepo.UpdateStatus(ctx, tender.ID, status); err != nil {
+			return err
+		}
+
+		after := before
+		after.Status = status
+
+		return r.audit.record(ctx, entity.AuditEntityTender, string(tender.ID), entity.AuditActionStatusChange, tender.OrganizationID, actorID, &before, &after)
+	})
+	if err != nil {
+		return err
+	}
+
+	tender.Status = status
+
+	r.metrics.TenderStatusChanged(status)
+	r.log(ctx).Info("tender status changed",
+		zap.String("tenderId", string(tender.ID)),
+		zap.String("status", string(status)),
+	)
+
+	if eventType, ok := tenderStatusEvents[status]; ok {
+		event := &entity.Event{
+			Type:       eventType,
+			TenderID:   tender.ID,
+			ActorID:    actorID,
+			OccurredAt: time.Now(),
+		}
+
+		// Awards and auctions change the status within their own transaction.
+		r.transactor.AfterCommit(ctx, func(ctx context.Context) {
+			r.events.Publish(ctx, event)
+		})
+	}
+
+	return nil
+}
+
+func (r *TenderService) Edit(ctx context.Context, tenderID entity.TenderID, update *entity.TenderUpdate, userName string) (_ *entity.Tender, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.Edit")
+	defer func() { tracing.End(span, err) }()
+
+	if update.ServiceType != "" {
+		if err := r.catalog.requireServiceType(ctx, entity.TenderServiceType(update.ServiceType)); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Budget != nil {
+		if err := validateBudget(update.Budget); err != nil {
+			return nil, err
+		}
 	}
 
 	userID, err := r.userRepo.FindUserId(ctx, userName)
@@ -195,9 +498,145 @@ func (r *TenderService) Edit(ctx context.Context, tenderID entity.TenderID, upda
 		return nil, ErrNotEnoughRights
 	}
 
-	if err := r.tenderRepo.Update(ctx, tenderID, update); err != nil {
+	// Bidders rely on the sealing, the deadline and the audience they saw when the tender was published.
+	if update.Sealed != nil || update.BidDeadline != nil || update.InviteOnly != nil {
+		if tender.Status != entity.TenderStatusCreated {
+			return nil, fmt.Errorf("%w: sealed, bidDeadline and inviteOnly can only change before the tender is published", ErrWrongInputFormat)
+		}
+
+		if update.BidDeadline != nil && !update.BidDeadline.After(time.Now()) {
+			return nil, fmt.Errorf("%w: bidDeadline must be in the future", ErrWrongInputFormat)
+		}
+	}
+
+	if update.Sealed != nil && *update.Sealed {
+		if err := r.requireNoAuction(ctx, tenderID); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *tender
+	tender.Apply(update)
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.tenderRepo.Update(ctx, tenderID, update); err != nil {
+			return err
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityTender, string(tenderID), entity.AuditActionEdit, tender.OrganizationID, userID, &before, tender)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log(ctx).Info("tender edited", zap.String("tenderId", string(tenderID)))
+
+	r.events.Publish(ctx, &entity.Event{
+		Type:       entity.EventTenderEdited,
+		TenderID:   tenderID,
+		ActorID:    userID,
+		OccurredAt: time.Now(),
+	})
+
+	return tender, nil
+}
+
+// Delete soft deletes the tender. It disappears from reads and listings, and
+// its owners may restore it until the retention period ends and it is purged.
+func (r *TenderService) Delete(ctx context.Context, tenderID entity.TenderID, userName string) (err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	tender, userID, err := r.authorizeOwner(ctx, tenderID, userName)
+	if err != nil {
+		return err
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := r.tenderRepo.Delete(ctx, tenderID, time.Now())
+		if err != nil {
+			return fmt.Errorf("delete tender: %w", err)
+		}
+
+		// Deleted concurrently.
+		if !deleted {
+			return ErrTenderNotFound
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityTender, string(tenderID), entity.AuditActionDelete, tender.OrganizationID, userID, tender, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.log(ctx).Info("tender deleted", zap.String("tenderId", string(tenderID)))
+
+	return nil
+}
+
+// Restore undoes Delete for a tender that has not been purged yet.
+func (r *TenderService) Restore(ctx context.Context, tenderID entity.TenderID, userName string) (_ *entity.Tender, err error) {
+	ctx, span := tracer.Start(ctx, "TenderService.Restore")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := r.userRepo.FindUserId(ctx, userName)
+	if err != nil || userID == "" {
+		return nil, ErrUserNotExists
+	}
+
+	tender, err := r.tenderRepo.ReadDeleted(ctx, tenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tender == nil {
+		return nil, ErrTenderNotFound
+	}
+
+	users, err := r.organizationRepo.FindResponsibleUsers(ctx, []entity.OrganizationID{tender.OrganizationID})
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(users, userID) {
+		return nil, ErrNotEnoughRights
+	}
+
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		restored, err := r.tenderRepo.Restore(ctx, tenderID)
+		if err != nil {
+			return fmt.Errorf("restore tender: %w", err)
+		}
+
+		if !restored {
+			return ErrTenderNotFound
+		}
+
+		return r.audit.record(ctx, entity.AuditEntityTender, string(tenderID), entity.AuditActionRestore, tender.OrganizationID, userID, nil, tender)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return tender.Apply(update), nil
+	r.log(ctx).Info("tender restored", zap.String("tenderId", string(tenderID)))
+
+	return tender, nil
+}
+
+// requireNoAuction rejects sealing a tender that is auctioned, its best price is public.
+func (r *TenderService) requireNoAuction(ctx context.Context, tenderID entity.TenderID) error {
+	auction, err := r.auctionRepo.Read(ctx, tenderID)
+	if err != nil {
+		return err
+	}
+
+	if auction != nil {
+		return fmt.Errorf("%w: an auctioned tender cannot be sealed", ErrWrongInputFormat)
+	}
+
+	return nil
+}
+
+func (r *TenderService) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
 }
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+)
+
+type txKey struct{}
+
+type txStub struct {
+	afterCommit []func(context.Context)
+}
+
+// transactorStub runs hooks after a committed transaction the way the postgres
+// Transactor does. Stub repos do not take part in it, a rollback undoes nothing.
+type transactorStub struct{}
+
+func (r transactorStub) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.InTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	tx := &txStub{}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	for _, hook := range tx.afterCommit {
+		hook(ctx)
+	}
+
+	return nil
+}
+
+func (r transactorStub) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if tx, ok := ctx.Value(txKey{}).(*txStub); ok {
+		tx.afterCommit = append(tx.afterCommit, fn)
+		return
+	}
+
+	fn(ctx)
+}
+
+func (r transactorStub) InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txStub)
+	return ok
+}
+
+type tenderRepoStub struct {
+	port.TenderRepo
+	tenders map[entity.TenderID]*entity.Tender
+}
+
+func (r *tenderRepoStub) Read(_ context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
+	tender, ok := r.tenders[tenderID]
+	if !ok {
+		return nil, nil
+	}
+
+	read := *tender
+
+	return &read, nil
+}
+
+func (r *tenderRepoStub) Lock(ctx context.Context, tenderID entity.TenderID) (*entity.Tender, error) {
+	return r.Read(ctx, tenderID)
+}
+
+func (r *tenderRepoStub) UpdateStatus(_ context.Context, tenderID entity.TenderID, status entity.TenderStatus) error {
+	r.tenders[tenderID].Status = status
+	return nil
+}
+
+type metricsStub struct{}
+
+func (metricsStub) TenderCreated()                          {}
+func (metricsStub) TenderStatusChanged(entity.TenderStatus) {}
+func (metricsStub) BidCreated()                             {}
+func (metricsStub) BidDecision(entity.BidReviewDecision)    {}
+
+type eventPublisherStub struct {
+	events []*entity.Event
+}
+
+func (r *eventPublisherStub) Publish(_ context.Context, event *entity.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestChangeStatusPublishesEvent(t *testing.T) {
+	const (
+		tenderID = entity.TenderID("3c2b1a09-8f7e-4d6c-9b5a-4f3e2d1c0b9a")
+		ownerID  = entity.UserID("6f1c3a52-0a3e-4c1f-9d4b-1b2e3c4d5e6f")
+	)
+
+	errRollback := errors.New("rollback")
+
+	tests := []struct {
+		name   string
+		from   entity.TenderStatus
+		to     entity.TenderStatus
+		outer  error
+		inside bool
+		want   entity.EventType
+	}{
+		{name: "publish", from: entity.TenderStatusCreated, to: entity.TenderStatusPublished, want: entity.EventTenderPublished},
+		{name: "close", from: entity.TenderStatusPublished, to: entity.TenderStatusClosed, want: entity.EventTenderClosed},
+		{name: "back to created", from: entity.TenderStatusPublished, to: entity.TenderStatusCreated},
+		{
+			name:   "close within a committed transaction",
+			from:   entity.TenderStatusPublished,
+			to:     entity.TenderStatusClosed,
+			inside: true,
+			want:   entity.EventTenderClosed,
+		},
+		{
+			name:   "close within a rolled back transaction",
+			from:   entity.TenderStatusPublished,
+			to:     entity.TenderStatusClosed,
+			inside: true,
+			outer:  errRollback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tender := &entity.Tender{ID: tenderID, Status: tt.from}
+			events := &eventPublisherStub{}
+			tenders := NewTenderService(
+				&tenderRepoStub{tenders: map[entity.TenderID]*entity.Tender{tenderID: tender}},
+				nil,
+				nil,
+				nil,
+				nil,
+				NewAuditService(&auditRepoStub{}, userRepoStub{}, organizationRepoStub{}, nil, zap.NewNop()),
+				userRepoStub{},
+				organizationRepoStub{},
+				transactorStub{},
+				metricsStub{},
+				events,
+				zap.NewNop(),
+			)
+
+			var err error
+			if tt.inside {
+				err = transactorStub{}.WithinTransaction(ctx, func(ctx context.Context) error {
+					if err := tenders.changeStatus(ctx, &entity.Tender{ID: tenderID, Status: tt.from}, tt.to, ownerID); err != nil {
+						return err
+					}
+
+					if len(events.events) != 0 {
+						t.Errorf("an event is published before the commit")
+					}
+
+					return tt.outer
+				})
+			} else {
+				err = tenders.changeStatus(ctx, &entity.Tender{ID: tenderID, Status: tt.from}, tt.to, ownerID)
+			}
+
+			if !errors.Is(err, tt.outer) {
+				t.Fatalf("changeStatus() error = %v, want %v", err, tt.outer)
+			}
+
+			if tt.want == "" {
+				if len(events.events) != 0 {
+					t.Errorf("published %d events, want none", len(events.events))
+				}
+
+				return
+			}
+
+			if len(events.events) != 1 {
+				t.Fatalf("published %d events, want 1", len(events.events))
+			}
+
+			if got := events.events[0]; got.Type != tt.want || got.TenderID != tenderID || got.ActorID != ownerID {
+				t.Errorf("event = %+v, want %s of %s by %s", got, tt.want, tenderID, ownerID)
+			}
+		})
+	}
+}
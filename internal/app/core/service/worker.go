@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleFactor is how many missed intervals make a worker unhealthy.
+const staleFactor = 3
+
+type workerState struct {
+	interval time.Duration
+	lastBeat time.Time
+	lastErr  error
+}
+
+// WorkerMonitor tracks heartbeats of background workers and reports
+// them as a single readiness check.
+type WorkerMonitor struct {
+	mu      sync.Mutex
+	workers map[string]*workerState
+}
+
+func NewWorkerMonitor() *WorkerMonitor {
+	return &WorkerMonitor{
+		workers: make(map[string]*workerState),
+	}
+}
+
+func (r *WorkerMonitor) Register(name string, interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.workers[name] = &workerState{
+		interval: interval,
+		lastBeat: time.Now(),
+	}
+}
+
+// Beat records a finished iteration of the worker, err is the iteration result.
+func (r *WorkerMonitor) Beat(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.workers[name]
+	if !ok {
+		return
+	}
+
+	state.lastBeat = time.Now()
+	state.lastErr = err
+}
+
+func (r *WorkerMonitor) Name() string {
+	return "workers"
+}
+
+func (r *WorkerMonitor) Check(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var failed []string
+
+	now := time.Now()
+
+	for name, state := range r.workers {
+		switch {
+		case now.Sub(state.lastBeat) > staleFactor*state.interval:
+			failed = append(failed, fmt.Sprintf("%s: no heartbeat since %s", name, state.lastBeat.Format(time.RFC3339)))
+		case state.lastErr != nil:
+			failed = append(failed, fmt.Sprintf("%s: %s", name, state.lastErr))
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	sort.Strings(failed)
+
+	return fmt.Errorf("%w: %s", ErrWorkerUnhealthy, strings.Join(failed, "; "))
+}
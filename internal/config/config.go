@@ -1,19 +1,278 @@
 package config
 
+import (
+	"time"
+
+	"avito2024/internal/ratelimit"
+	"avito2024/internal/tracing"
+)
+
+const serviceName = "avito2024"
+
 type Config struct {
-	Host             string
-	ConnectionString string
-	IsTest           bool
+	HTTP        HTTP        `yaml:"http" toml:"http"`
+	Postgres    Postgres    `yaml:"postgres" toml:"postgres"`
+	Cache       Cache       `yaml:"cache" toml:"cache"`
+	RateLimit   RateLimit   `yaml:"rateLimit" toml:"rateLimit"`
+	Idempotency Idempotency `yaml:"idempotency" toml:"idempotency"`
+	Attachments Attachments `yaml:"attachments" toml:"attachments"`
+	Auctions    Auctions    `yaml:"auctions" toml:"auctions"`
+	Notify      Notify      `yaml:"notify" toml:"notify"`
+	Retention   Retention   `yaml:"retention" toml:"retention"`
+	Log         Log         `yaml:"log" toml:"log"`
+	Tracing     Tracing     `yaml:"tracing" toml:"tracing"`
+	Features    Features    `yaml:"features" toml:"features"`
+	Workers     Workers     `yaml:"workers" toml:"workers"`
+	Admin       Admin       `yaml:"admin" toml:"admin"`
+
+	// IsTest makes the service create the employee and organization tables,
+	// which are owned by another system in production.
+	IsTest bool `yaml:"isTest" toml:"isTest" env:"IS_TEST_ENV"`
+}
+
+type HTTP struct {
+	Address           string   `yaml:"address" toml:"address" env:"SERVER_ADDRESS"`
+	ReadTimeout       Duration `yaml:"readTimeout" toml:"readTimeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout Duration `yaml:"readHeaderTimeout" toml:"readHeaderTimeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      Duration `yaml:"writeTimeout" toml:"writeTimeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       Duration `yaml:"idleTimeout" toml:"idleTimeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout   Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout" env:"HTTP_SHUTDOWN_TIMEOUT"`
+	// TrustedProxies may set X-Forwarded-For, the client IP of anyone else is the remote address.
+	TrustedProxies []string `yaml:"trustedProxies" toml:"trustedProxies" env:"HTTP_TRUSTED_PROXIES"`
 }
 
-func New(
-	host string,
-	connStr string,
-	isTest bool,
-) *Config {
+type Postgres struct {
+	ConnectionString Secret `yaml:"connectionString" toml:"connectionString" env:"POSTGRES_CONN"`
+	MaxConns         int    `yaml:"maxConns" toml:"maxConns" env:"POSTGRES_MAX_CONNS"`
+	// MinConns connections are kept open even when idle.
+	MinConns        int      `yaml:"minConns" toml:"minConns" env:"POSTGRES_MIN_CONNS"`
+	ConnMaxLifetime Duration `yaml:"connMaxLifetime" toml:"connMaxLifetime" env:"POSTGRES_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime Duration `yaml:"connMaxIdleTime" toml:"connMaxIdleTime" env:"POSTGRES_CONN_MAX_IDLE_TIME"`
+	// QueryTimeout bounds every single statement on top of the request deadline.
+	QueryTimeout Duration `yaml:"queryTimeout" toml:"queryTimeout" env:"POSTGRES_QUERY_TIMEOUT"`
+	// StreamTimeout bounds statements whose rows are streamed to the client, such as exports,
+	// and the writing of their response, which may outlast http.writeTimeout.
+	StreamTimeout Duration `yaml:"streamTimeout" toml:"streamTimeout" env:"POSTGRES_STREAM_TIMEOUT"`
+	// StatementCacheCapacity is the number of prepared statements cached per connection.
+	StatementCacheCapacity int      `yaml:"statementCacheCapacity" toml:"statementCacheCapacity" env:"POSTGRES_STATEMENT_CACHE_CAPACITY"`
+	ConnectAttempts        int      `yaml:"connectAttempts" toml:"connectAttempts" env:"POSTGRES_CONNECT_ATTEMPTS"`
+	ConnectBackoff         Duration `yaml:"connectBackoff" toml:"connectBackoff" env:"POSTGRES_CONNECT_BACKOFF"`
+	// PoolStatsInterval is how often pool saturation is checked and logged.
+	PoolStatsInterval Duration `yaml:"poolStatsInterval" toml:"poolStatsInterval" env:"POSTGRES_POOL_STATS_INTERVAL"`
+}
+
+type Cache struct {
+	// Enabled puts an in-process LRU in front of user, organization and tender reads.
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"CACHE_ENABLED"`
+	// Size is the maximum number of cached entries shared by all repos.
+	Size            int      `yaml:"size" toml:"size" env:"CACHE_SIZE"`
+	UserTTL         Duration `yaml:"userTTL" toml:"userTTL" env:"CACHE_USER_TTL"`
+	OrganizationTTL Duration `yaml:"organizationTTL" toml:"organizationTTL" env:"CACHE_ORGANIZATION_TTL"`
+	TenderTTL       Duration `yaml:"tenderTTL" toml:"tenderTTL" env:"CACHE_TENDER_TTL"`
+	// TenderListTTL bounds how long a listing page is served, writes through the service drop it earlier.
+	TenderListTTL Duration `yaml:"tenderListTTL" toml:"tenderListTTL" env:"CACHE_TENDER_LIST_TTL"`
+	// ServiceTypeTTL bounds how long catalog changes made on another replica take to show up.
+	ServiceTypeTTL Duration `yaml:"serviceTypeTTL" toml:"serviceTypeTTL" env:"CACHE_SERVICE_TYPE_TTL"`
+}
+
+// RateLimit sets per group limits, each applies separately to every client IP and username.
+type RateLimit struct {
+	Enabled      bool `yaml:"enabled" toml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	TendersRead  Rate `yaml:"tendersRead" toml:"tendersRead" env:"RATE_LIMIT_TENDERS_READ"`
+	TendersWrite Rate `yaml:"tendersWrite" toml:"tendersWrite" env:"RATE_LIMIT_TENDERS_WRITE"`
+	BidsRead     Rate `yaml:"bidsRead" toml:"bidsRead" env:"RATE_LIMIT_BIDS_READ"`
+	BidsWrite    Rate `yaml:"bidsWrite" toml:"bidsWrite" env:"RATE_LIMIT_BIDS_WRITE"`
+}
+
+type Idempotency struct {
+	// Window is how long a response is replayed for a repeated Idempotency-Key.
+	Window Duration `yaml:"window" toml:"window" env:"IDEMPOTENCY_WINDOW"`
+	// LockTimeout frees the key of a request that never completed, keep it above http.writeTimeout.
+	LockTimeout     Duration `yaml:"lockTimeout" toml:"lockTimeout" env:"IDEMPOTENCY_LOCK_TIMEOUT"`
+	CleanupInterval Duration `yaml:"cleanupInterval" toml:"cleanupInterval" env:"IDEMPOTENCY_CLEANUP_INTERVAL"`
+	// MaxBodyMB caps the body of a request carrying an Idempotency-Key, which is
+	// buffered to be hashed. Tender import takes up to 10 MB.
+	MaxBodyMB int `yaml:"maxBodyMB" toml:"maxBodyMB" env:"IDEMPOTENCY_MAX_BODY_MB"`
+}
+
+type Attachments struct {
+	// Dir is where the local blob store keeps attachment contents.
+	Dir       string `yaml:"dir" toml:"dir" env:"ATTACHMENTS_DIR"`
+	MaxSizeMB int    `yaml:"maxSizeMB" toml:"maxSizeMB" env:"ATTACHMENTS_MAX_SIZE_MB"`
+	// AllowedTypes are checked against the type detected from the content, not the one sent by the client.
+	AllowedTypes []string `yaml:"allowedTypes" toml:"allowedTypes" env:"ATTACHMENTS_ALLOWED_TYPES"`
+}
+
+type Log struct {
+	// Level is one of debug, info, warn, error.
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	// Format is json or console.
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
+
+type Tracing struct {
+	ServiceName string  `yaml:"serviceName" toml:"serviceName" env:"TRACING_SERVICE_NAME"`
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"TRACING_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" toml:"insecure" env:"TRACING_INSECURE"`
+	SampleRatio float64 `yaml:"sampleRatio" toml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+type Features struct {
+	// Metrics exposes /metrics and records HTTP request metrics.
+	Metrics bool `yaml:"metrics" toml:"metrics" env:"FEATURE_METRICS"`
+}
+
+type Auctions struct {
+	// CloseInterval is how often ended auctions are closed by the workers.
+	CloseInterval Duration `yaml:"closeInterval" toml:"closeInterval" env:"AUCTIONS_CLOSE_INTERVAL"`
+}
+
+type Notify struct {
+	// QueueSize is how many events may wait for delivery, events beyond it are dropped.
+	QueueSize int  `yaml:"queueSize" toml:"queueSize" env:"NOTIFY_QUEUE_SIZE"`
+	SMTP      SMTP `yaml:"smtp" toml:"smtp"`
+}
+
+type SMTP struct {
+	// Enabled mails notifications to users who have set an email address.
+	Enabled  bool     `yaml:"enabled" toml:"enabled" env:"SMTP_ENABLED"`
+	Addr     string   `yaml:"addr" toml:"addr" env:"SMTP_ADDR"`
+	Username string   `yaml:"username" toml:"username" env:"SMTP_USERNAME"`
+	Password Secret   `yaml:"password" toml:"password" env:"SMTP_PASSWORD"`
+	From     string   `yaml:"from" toml:"from" env:"SMTP_FROM"`
+	Timeout  Duration `yaml:"timeout" toml:"timeout" env:"SMTP_TIMEOUT"`
+}
+
+type Retention struct {
+	// DeletedFor is how long deleted tenders and bids can be restored before they are purged.
+	DeletedFor Duration `yaml:"deletedFor" toml:"deletedFor" env:"RETENTION_DELETED_FOR"`
+	// ArchiveAfterMonths moves closed tenders this old to the archive, 0 never archives them.
+	ArchiveAfterMonths int `yaml:"archiveAfterMonths" toml:"archiveAfterMonths" env:"RETENTION_ARCHIVE_AFTER_MONTHS"`
+	// BatchSize is how many tenders are archived at once.
+	BatchSize int `yaml:"batchSize" toml:"batchSize" env:"RETENTION_BATCH_SIZE"`
+	// Interval is how often the workers purge and archive.
+	Interval Duration `yaml:"interval" toml:"interval" env:"RETENTION_INTERVAL"`
+}
+
+type Admin struct {
+	// Usernames may manage the service type catalog and read the whole audit log.
+	Usernames []string `yaml:"usernames" toml:"usernames" env:"ADMIN_USERNAMES"`
+}
+
+type Workers struct {
+	// Enabled runs background workers on this replica.
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"WORKERS_ENABLED"`
+}
+
+// Default returns the configuration used for every value not set in the file or environment.
+func Default() *Config {
 	return &Config{
-		Host:             host,
-		ConnectionString: connStr,
-		IsTest:           isTest,
+		HTTP: HTTP{
+			Address:           "0.0.0.0:8080",
+			ReadTimeout:       Duration(10 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(30 * time.Second),
+			IdleTimeout:       Duration(2 * time.Minute),
+			ShutdownTimeout:   Duration(15 * time.Second),
+		},
+		Postgres: Postgres{
+			MaxConns:               20,
+			MinConns:               2,
+			ConnMaxLifetime:        Duration(30 * time.Minute),
+			ConnMaxIdleTime:        Duration(5 * time.Minute),
+			QueryTimeout:           Duration(5 * time.Second),
+			StreamTimeout:          Duration(2 * time.Minute),
+			StatementCacheCapacity: 512,
+			ConnectAttempts:        10,
+			ConnectBackoff:         Duration(500 * time.Millisecond),
+			PoolStatsInterval:      Duration(30 * time.Second),
+		},
+		Cache: Cache{
+			Enabled:         true,
+			Size:            10000,
+			UserTTL:         Duration(5 * time.Minute),
+			OrganizationTTL: Duration(5 * time.Minute),
+			TenderTTL:       Duration(time.Minute),
+			TenderListTTL:   Duration(10 * time.Second),
+			ServiceTypeTTL:  Duration(time.Minute),
+		},
+		RateLimit: RateLimit{
+			Enabled:      true,
+			TendersRead:  Rate{Burst: 100, Period: 10 * time.Second},
+			TendersWrite: Rate{Burst: 20, Period: time.Minute},
+			BidsRead:     Rate{Burst: 100, Period: 10 * time.Second},
+			BidsWrite:    Rate{Burst: 20, Period: time.Minute},
+		},
+		Idempotency: Idempotency{
+			Window:          Duration(24 * time.Hour),
+			LockTimeout:     Duration(time.Minute),
+			CleanupInterval: Duration(10 * time.Minute),
+			MaxBodyMB:       10,
+		},
+		Attachments: Attachments{
+			Dir:       "data/attachments",
+			MaxSizeMB: 25,
+			AllowedTypes: []string{
+				"application/pdf",
+				"application/zip",
+				"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+				"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+				"application/msword",
+				"application/vnd.ms-excel",
+				"image/png",
+				"image/jpeg",
+				"text/plain",
+				"text/csv",
+			},
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: Tracing{
+			ServiceName: serviceName,
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+		},
+		Features: Features{
+			Metrics: true,
+		},
+		Auctions: Auctions{
+			CloseInterval: Duration(5 * time.Second),
+		},
+		Notify: Notify{
+			QueueSize: 1000,
+			SMTP: SMTP{
+				Timeout: Duration(10 * time.Second),
+			},
+		},
+		Retention: Retention{
+			DeletedFor:         Duration(30 * 24 * time.Hour),
+			ArchiveAfterMonths: 12,
+			BatchSize:          500,
+			Interval:           Duration(time.Hour),
+		},
+		Workers: Workers{
+			Enabled: true,
+		},
+	}
+}
+
+func (r *Tracing) Config() *tracing.Config {
+	return &tracing.Config{
+		ServiceName: r.ServiceName,
+		Exporter:    r.Exporter,
+		Endpoint:    r.Endpoint,
+		Insecure:    r.Insecure,
+		SampleRatio: r.SampleRatio,
 	}
 }
+
+func (r *RateLimit) Tenders() ratelimit.Policy {
+	return ratelimit.Policy{Read: r.TendersRead.Limit(), Write: r.TendersWrite.Limit()}
+}
+
+func (r *RateLimit) Bids() ratelimit.Policy {
+	return ratelimit.Policy{Read: r.BidsRead.Limit(), Write: r.BidsWrite.Limit()}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secretValue = "postgres://app:s3cr3t-pa55@db:5432/app"
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "yaml file over defaults",
+			file: "config.yaml",
+			content: `
+postgres:
+  connectionString: ` + secretValue + `
+  maxConns: 5
+cache:
+  tenderTTL: 2m
+`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Postgres.MaxConns != 5 {
+					t.Errorf("MaxConns = %d, want 5", cfg.Postgres.MaxConns)
+				}
+
+				if cfg.Cache.TenderTTL.Std() != 2*time.Minute {
+					t.Errorf("TenderTTL = %s, want 2m", cfg.Cache.TenderTTL)
+				}
+
+				if cfg.Postgres.MinConns != Default().Postgres.MinConns {
+					t.Errorf("MinConns = %d, want the default %d", cfg.Postgres.MinConns, Default().Postgres.MinConns)
+				}
+			},
+		},
+		{
+			name: "env over the file",
+			file: "config.toml",
+			content: `
+[postgres]
+connectionString = "postgres://file"
+maxConns = 5
+
+[log]
+level = "debug"
+`,
+			env: map[string]string{
+				"POSTGRES_CONN":        secretValue,
+				"POSTGRES_MAX_CONNS":   "7",
+				"ADMIN_USERNAMES":      "alice, bob,",
+				"TRACING_SAMPLE_RATIO": "0.5",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Postgres.ConnectionString.Value() != secretValue {
+					t.Errorf("ConnectionString = %q, want the env value", cfg.Postgres.ConnectionString.Value())
+				}
+
+				if cfg.Postgres.MaxConns != 7 {
+					t.Errorf("MaxConns = %d, want 7", cfg.Postgres.MaxConns)
+				}
+
+				if cfg.Log.Level != "debug" {
+					t.Errorf("Log.Level = %q, want the file value debug", cfg.Log.Level)
+				}
+
+				if got := strings.Join(cfg.Admin.Usernames, ","); got != "alice,bob" {
+					t.Errorf("Admin.Usernames = %q, want alice,bob", got)
+				}
+
+				if cfg.Tracing.SampleRatio != 0.5 {
+					t.Errorf("SampleRatio = %v, want 0.5", cfg.Tracing.SampleRatio)
+				}
+			},
+		},
+		{
+			name:    "unknown yaml field",
+			file:    "config.yaml",
+			content: "postgres:\n  connectionString: x\n  maxConnections: 5\n",
+			wantErr: "maxConnections",
+		},
+		{
+			name:    "unknown toml field",
+			file:    "config.toml",
+			content: "[postgres]\nconnectionString = \"x\"\nmaxConnections = 5\n",
+			wantErr: "maxConnections",
+		},
+		{
+			name:    "unsupported format",
+			file:    "config.json",
+			content: "{}",
+			wantErr: "unsupported format",
+		},
+		{
+			name:    "malformed env value",
+			env:     map[string]string{"POSTGRES_CONN": secretValue, "POSTGRES_MAX_CONNS": "many"},
+			wantErr: "env POSTGRES_MAX_CONNS",
+		},
+		{
+			name:    "invalid values fail validation",
+			env:     map[string]string{"POSTGRES_CONN": secretValue, "LOG_LEVEL": "loud"},
+			wantErr: "log.level",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envConfigFile, "")
+
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			var path string
+			if tt.file != "" {
+				path = writeFile(t, tt.file, tt.content)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Postgres.ConnectionString = secretValue
+	cfg.Notify.SMTP.Password = "smtp-pa55word"
+
+	jsonData, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := map[string]string{
+		"Dump": cfg.Dump(),
+		"%v":   fmt.Sprintf("%v", cfg),
+		"%+v":  fmt.Sprintf("%+v", cfg),
+		"%#v":  fmt.Sprintf("%#v", cfg),
+		"%s":   fmt.Sprintf("%s", cfg.Postgres.ConnectionString),
+		"json": string(jsonData),
+	}
+
+	for name, output := range outputs {
+		for _, secret := range []string{secretValue, "s3cr3t-pa55", "smtp-pa55word"} {
+			if strings.Contains(output, secret) {
+				t.Errorf("%s output contains the secret %q: %s", name, secret, output)
+			}
+		}
+
+		if !strings.Contains(output, redacted) {
+			t.Errorf("%s output does not show the secret as %s", name, redacted)
+		}
+	}
+
+	if cfg.Postgres.ConnectionString.Value() != secretValue {
+		t.Errorf("Value() = %q, want the secret", cfg.Postgres.ConnectionString.Value())
+	}
+
+	var empty Secret
+	if empty.String() != "" {
+		t.Errorf("an empty secret renders as %q, want an empty string", empty.String())
+	}
+}
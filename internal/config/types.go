@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"avito2024/internal/ratelimit"
+)
+
+const redacted = "[REDACTED]"
+
+// Duration is a time.Duration written as "1m30s" in files and environment variables.
+type Duration time.Duration
+
+func (r Duration) Std() time.Duration {
+	return time.Duration(r)
+}
+
+func (r *Duration) UnmarshalText(text []byte) error {
+	d, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = Duration(d)
+
+	return nil
+}
+
+func (r Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(r).String()), nil
+}
+
+func (r Duration) String() string {
+	return time.Duration(r).String()
+}
+
+// Secret holds a value that must never appear in logs or config dumps.
+// Use Value to read it.
+type Secret string
+
+func (r Secret) Value() string {
+	return string(r)
+}
+
+func (r Secret) String() string {
+	if r == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+func (r Secret) GoString() string {
+	return r.String()
+}
+
+func (r Secret) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+func (r *Secret) UnmarshalText(text []byte) error {
+	*r = Secret(text)
+	return nil
+}
+
+// Rate is a request limit written as "20/1m": a burst of 20 requests that
+// refills over a minute. "0" or an empty value disables the limit.
+type Rate ratelimit.Limit
+
+func (r Rate) Limit() ratelimit.Limit {
+	return ratelimit.Limit(r)
+}
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	value := string(text)
+	if value == "" || value == "0" {
+		*r = Rate{}
+		return nil
+	}
+
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return fmt.Errorf("rate %q: must be <requests>/<period>", value)
+	}
+
+	burst, err := strconv.Atoi(count)
+	if err != nil {
+		return fmt.Errorf("rate %q: %w", value, err)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return fmt.Errorf("rate %q: %w", value, err)
+	}
+
+	if burst <= 0 || d <= 0 {
+		return fmt.Errorf("rate %q: requests and period must be positive", value)
+	}
+
+	*r = Rate{Burst: burst, Period: d}
+
+	return nil
+}
+
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r Rate) String() string {
+	if r.Limit().Unlimited() {
+		return "0"
+	}
+
+	return strconv.Itoa(r.Burst) + "/" + r.Period.String()
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+
+	"go.uber.org/zap/zapcore"
+
+	"avito2024/internal/tracing"
+)
+
+// Validate reports every invalid value at once so a broken deployment is fixed in one go.
+func (r *Config) Validate() error {
+	var errs []error
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, _, err := net.SplitHostPort(r.HTTP.Address)
+	check(err == nil, "http.address %q: must be host:port", r.HTTP.Address)
+	check(r.HTTP.ReadTimeout >= 0, "http.readTimeout must not be negative")
+	check(r.HTTP.ReadHeaderTimeout >= 0, "http.readHeaderTimeout must not be negative")
+	check(r.HTTP.WriteTimeout >= 0, "http.writeTimeout must not be negative")
+	check(r.HTTP.IdleTimeout >= 0, "http.idleTimeout must not be negative")
+	check(r.HTTP.ShutdownTimeout > 0, "http.shutdownTimeout must be positive")
+	for _, proxy := range r.HTTP.TrustedProxies {
+		check(validProxy(proxy), "http.trustedProxies %q: must be an IP or CIDR", proxy)
+	}
+
+	check(r.Postgres.ConnectionString != "", "postgres.connectionString is required")
+	check(r.Postgres.MaxConns > 0, "postgres.maxConns must be positive")
+	check(r.Postgres.MinConns >= 0 && r.Postgres.MinConns <= r.Postgres.MaxConns,
+		"postgres.minConns must be within [0, postgres.maxConns]")
+	check(r.Postgres.StatementCacheCapacity >= 0, "postgres.statementCacheCapacity must not be negative")
+	check(r.Postgres.ConnMaxLifetime >= 0, "postgres.connMaxLifetime must not be negative")
+	check(r.Postgres.ConnMaxIdleTime >= 0, "postgres.connMaxIdleTime must not be negative")
+	check(r.Postgres.QueryTimeout > 0, "postgres.queryTimeout must be positive")
+	check(r.Postgres.StreamTimeout > 0, "postgres.streamTimeout must be positive")
+	check(r.Postgres.PoolStatsInterval > 0, "postgres.poolStatsInterval must be positive")
+	check(r.Postgres.ConnectAttempts > 0, "postgres.connectAttempts must be positive")
+	check(r.Postgres.ConnectBackoff > 0, "postgres.connectBackoff must be positive")
+
+	if r.Cache.Enabled {
+		check(r.Cache.Size > 0, "cache.size must be positive")
+		check(r.Cache.UserTTL > 0, "cache.userTTL must be positive")
+		check(r.Cache.OrganizationTTL > 0, "cache.organizationTTL must be positive")
+		check(r.Cache.TenderTTL > 0, "cache.tenderTTL must be positive")
+		check(r.Cache.TenderListTTL > 0, "cache.tenderListTTL must be positive")
+		check(r.Cache.ServiceTypeTTL > 0, "cache.serviceTypeTTL must be positive")
+	}
+
+	check(r.Idempotency.Window > 0, "idempotency.window must be positive")
+	check(r.Idempotency.LockTimeout > 0, "idempotency.lockTimeout must be positive")
+	check(r.Idempotency.CleanupInterval > 0, "idempotency.cleanupInterval must be positive")
+	check(r.Idempotency.MaxBodyMB > 0, "idempotency.maxBodyMB must be positive")
+
+	check(r.Attachments.Dir != "", "attachments.dir is required")
+	check(r.Attachments.MaxSizeMB > 0, "attachments.maxSizeMB must be positive")
+	check(len(r.Attachments.AllowedTypes) > 0, "attachments.allowedTypes must not be empty")
+
+	check(r.Auctions.CloseInterval > 0, "auctions.closeInterval must be positive")
+
+	check(r.Notify.QueueSize > 0, "notify.queueSize must be positive")
+	if r.Notify.SMTP.Enabled {
+		_, _, err := net.SplitHostPort(r.Notify.SMTP.Addr)
+		check(err == nil, "notify.smtp.addr %q: must be host:port", r.Notify.SMTP.Addr)
+		_, err = mail.ParseAddress(r.Notify.SMTP.From)
+		check(err == nil, "notify.smtp.from %q: must be an email address", r.Notify.SMTP.From)
+		check(r.Notify.SMTP.Timeout > 0, "notify.smtp.timeout must be positive")
+	}
+
+	check(r.Retention.DeletedFor > 0, "retention.deletedFor must be positive")
+	check(r.Retention.ArchiveAfterMonths >= 0, "retention.archiveAfterMonths must not be negative")
+	check(r.Retention.BatchSize > 0, "retention.batchSize must be positive")
+	check(r.Retention.Interval > 0, "retention.interval must be positive")
+
+	for _, username := range r.Admin.Usernames {
+		check(username != "", "admin.usernames must not contain empty names")
+	}
+
+	_, err = zapcore.ParseLevel(r.Log.Level)
+	check(err == nil, "log.level %q: must be debug, info, warn or error", r.Log.Level)
+	check(r.Log.Format == "json" || r.Log.Format == "console", "log.format %q: must be json or console", r.Log.Format)
+
+	switch r.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		check(false, "tracing.exporter %q: must be none, stdout or otlp", r.Tracing.Exporter)
+	}
+	check(r.Tracing.ServiceName != "", "tracing.serviceName is required")
+	check(r.Tracing.SampleRatio >= 0 && r.Tracing.SampleRatio <= 1, "tracing.sampleRatio must be within [0, 1]")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func validProxy(proxy string) bool {
+	if net.ParseIP(proxy) != nil {
+		return true
+	}
+
+	_, _, err := net.ParseCIDR(proxy)
+
+	return err == nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(cfg *Config)
+		wantErrs []string
+	}{
+		{
+			name:   "defaults with a connection string",
+			change: func(*Config) {},
+		},
+		{
+			name:     "missing connection string",
+			change:   func(cfg *Config) { cfg.Postgres.ConnectionString = "" },
+			wantErrs: []string{"postgres.connectionString is required"},
+		},
+		{
+			name:     "address without a port",
+			change:   func(cfg *Config) { cfg.HTTP.Address = "localhost" },
+			wantErrs: []string{"http.address"},
+		},
+		{
+			name:     "trusted proxy that is not an IP",
+			change:   func(cfg *Config) { cfg.HTTP.TrustedProxies = []string{"10.0.0.0/8", "proxy.local"} },
+			wantErrs: []string{`http.trustedProxies "proxy.local"`},
+		},
+		{
+			name: "min conns over max conns",
+			change: func(cfg *Config) {
+				cfg.Postgres.MaxConns = 2
+				cfg.Postgres.MinConns = 3
+			},
+			wantErrs: []string{"postgres.minConns"},
+		},
+		{
+			name:     "disabled cache is not checked",
+			change:   func(cfg *Config) { cfg.Cache = Cache{Enabled: false} },
+			wantErrs: nil,
+		},
+		{
+			name:     "enabled cache without a size",
+			change:   func(cfg *Config) { cfg.Cache.Size = 0 },
+			wantErrs: []string{"cache.size"},
+		},
+		{
+			name: "enabled smtp with a bad sender",
+			change: func(cfg *Config) {
+				cfg.Notify.SMTP.Enabled = true
+				cfg.Notify.SMTP.Addr = "smtp.example.com:587"
+				cfg.Notify.SMTP.From = "not an address"
+			},
+			wantErrs: []string{"notify.smtp.from"},
+		},
+		{
+			name:     "empty admin name",
+			change:   func(cfg *Config) { cfg.Admin.Usernames = []string{"alice", ""} },
+			wantErrs: []string{"admin.usernames"},
+		},
+		{
+			name:     "unknown exporter",
+			change:   func(cfg *Config) { cfg.Tracing.Exporter = "jaeger" },
+			wantErrs: []string{"tracing.exporter"},
+		},
+		{
+			name: "every error is reported at once",
+			change: func(cfg *Config) {
+				cfg.HTTP.ShutdownTimeout = 0
+				cfg.Idempotency.Window = Duration(-time.Second)
+				cfg.Log.Format = "xml"
+				cfg.Tracing.SampleRatio = 2
+			},
+			wantErrs: []string{
+				"http.shutdownTimeout",
+				"idempotency.window",
+				"log.format",
+				"tracing.sampleRatio",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Postgres.ConnectionString = secretValue
+			tt.change(cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %v", tt.wantErrs)
+			}
+
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
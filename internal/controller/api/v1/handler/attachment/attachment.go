@@ -0,0 +1,149 @@
+package attachment
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+// multipartOverhead leaves room for the multipart framing on top of the file size limit.
+const multipartOverhead = 1 << 20
+
+// upload streams the "file" part of a multipart form to the blob store.
+// The optional sha256 query param is the hex checksum the content must match.
+func (r *attachmentRouter) upload(ctx *gin.Context) {
+	owner := r.owner(ctx)
+	userName := ctx.Query("username")
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, r.attachmentService.MaxSize()+multipartOverhead)
+
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: "multipart/form-data with a file part is expected"})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := r.attachmentService.Upload(ctx, owner, userName, &entity.AttachmentUpload{
+			Name:   part.FileName(),
+			SHA256: ctx.Query("sha256"),
+			Body:   part,
+		})
+		part.Close()
+
+		if err != nil {
+			r.abortWithError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, attachment)
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: "file part is missing"})
+}
+
+func (r *attachmentRouter) list(ctx *gin.Context) {
+	owner := r.owner(ctx)
+	userName := ctx.Query("username")
+
+	var version int32
+	if value := ctx.Query("version"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: "version must be a positive integer"})
+			return
+		}
+
+		version = int32(parsed)
+	}
+
+	attachments, err := r.attachmentService.List(ctx, owner, userName, version)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attachments)
+}
+
+func (r *attachmentRouter) download(ctx *gin.Context) {
+	owner := r.owner(ctx)
+	attachmentID := entity.AttachmentID(ctx.Param("attachmentId"))
+	userName := ctx.Query("username")
+
+	attachment, body, err := r.attachmentService.Download(ctx, owner, attachmentID, userName)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+	defer body.Close()
+
+	ctx.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}),
+		"ETag":                `"` + attachment.SHA256 + `"`,
+	})
+}
+
+func (r *attachmentRouter) remove(ctx *gin.Context) {
+	owner := r.owner(ctx)
+	attachmentID := entity.AttachmentID(ctx.Param("attachmentId"))
+	userName := ctx.Query("username")
+
+	attachment, err := r.attachmentService.Delete(ctx, owner, attachmentID, userName)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attachment)
+}
+
+func (r *attachmentRouter) abortWithError(ctx *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights), errors.Is(err, service.ErrBidsSealed):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrTenderNotFound), errors.Is(err, service.ErrBidNotFound), errors.Is(err, service.ErrAttachmentNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrAttachmentTooLarge), errors.As(err, &maxBytesErr):
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+		ctx.AbortWithStatusJSON(http.StatusUnsupportedMediaType, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrBiddingClosed):
+		ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrChecksumMismatch):
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("attachment request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
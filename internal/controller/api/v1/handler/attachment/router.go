@@ -0,0 +1,58 @@
+package attachment
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+	"avito2024/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OwnerFunc reads whose attachments a request is about from its path.
+type OwnerFunc func(ctx *gin.Context) entity.AttachmentOwner
+
+// TenderOwner is the owner for routes below /tenders/:<param>.
+func TenderOwner(param string) OwnerFunc {
+	return func(ctx *gin.Context) entity.AttachmentOwner {
+		return entity.AttachmentOwner{TenderID: entity.TenderID(ctx.Param(param))}
+	}
+}
+
+// BidOwner is the owner for routes below /bids/:<param>, the service resolves the tender of the bid.
+func BidOwner(param string) OwnerFunc {
+	return func(ctx *gin.Context) entity.AttachmentOwner {
+		return entity.AttachmentOwner{BidID: entity.BidId(ctx.Param(param))}
+	}
+}
+
+type attachmentRouter struct {
+	attachmentService *service.AttachmentService
+	owner             OwnerFunc
+	logger            *zap.Logger
+}
+
+type serviceProvider interface {
+	AttachmentService() *service.AttachmentService
+	Logger() *zap.Logger
+}
+
+// AttachToGroup serves the attachments of the owner read by owner from the group path.
+func AttachToGroup(sp serviceProvider, group *gin.RouterGroup, owner OwnerFunc) {
+	ar := &attachmentRouter{
+		attachmentService: sp.AttachmentService(),
+		owner:             owner,
+		logger:            sp.Logger().Named("attachment"),
+	}
+
+	group.POST("", ar.upload)
+	group.GET("", ar.list)
+	group.GET("/:attachmentId", ar.download)
+	group.DELETE("/:attachmentId", ar.remove)
+}
+
+func (r *attachmentRouter) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *auditRouter) list(ctx *gin.Context) {
+	userName := ctx.Query("username")
+
+	limitOffset := entity.ParseRequestLimitOffset(ctx.Query("limit"), ctx.Query("offset"))
+	if limitOffset == nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{
+			Reason: "cannot parse limit or offset",
+		})
+		return
+	}
+
+	filter := &entity.AuditFilter{
+		EntityType:     entity.AuditEntityType(ctx.Query("entityType")),
+		EntityID:       ctx.Query("entityId"),
+		Action:         entity.AuditAction(ctx.Query("action")),
+		ActorID:        entity.UserID(ctx.Query("actorId")),
+		OrganizationID: entity.OrganizationID(ctx.Query("organizationId")),
+	}
+
+	var ok bool
+
+	if filter.From, ok = r.timeQuery(ctx, "from"); !ok {
+		return
+	}
+
+	if filter.To, ok = r.timeQuery(ctx, "to"); !ok {
+		return
+	}
+
+	entries, err := r.auditService.List(ctx, filter, limitOffset, userName)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// timeQuery parses an optional RFC 3339 query parameter, it aborts the request on a malformed one.
+func (r *auditRouter) timeQuery(ctx *gin.Context, name string) (*time.Time, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{
+			Reason: name + " must be an RFC 3339 time",
+		})
+		return nil, false
+	}
+
+	return &t, true
+}
+
+func (r *auditRouter) abortWithError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("audit request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/service"
+	"avito2024/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type auditRouter struct {
+	auditService *service.AuditService
+	logger       *zap.Logger
+}
+
+type serviceProvider interface {
+	AuditService() *service.AuditService
+	Logger() *zap.Logger
+}
+
+func AttachToGroup(sp serviceProvider, group *gin.RouterGroup) {
+	ar := &auditRouter{
+		auditService: sp.AuditService(),
+		logger:       sp.Logger().Named("audit"),
+	}
+
+	group.GET("", ar.list)
+}
+
+func (r *auditRouter) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -15,7 +15,7 @@ func (r *bidRouter) create(ctx *gin.Context) {
 	var bid entity.Bid
 
 	if err := ctx.Bind(&bid); err != nil {
-		r.logger.Error("bind failed", zap.Error(err))
+		r.log(ctx).Error("bind failed", zap.Error(err))
 		ctx.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
@@ -38,6 +38,30 @@ func (r *bidRouter) create(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, service.ErrLotNotFound) {
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				entity.ResponseError{Reason: service.ErrLotNotFound.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrNotInvited) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				entity.ResponseError{Reason: service.ErrNotInvited.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrBiddingClosed) || errors.Is(err, service.ErrLotSettled) {
+			ctx.AbortWithStatusJSON(
+				http.StatusConflict,
+				entity.ResponseError{Reason: err.Error()},
+			)
+			return
+		}
+
 		if errors.Is(err, service.ErrWrongInputFormat) {
 			ctx.AbortWithStatusJSON(
 				http.StatusBadRequest,
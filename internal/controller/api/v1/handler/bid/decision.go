@@ -0,0 +1,63 @@
+package bid
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *bidRouter) submitDecision(ctx *gin.Context) {
+	bidID := ctx.Param("id")
+
+	userName := ctx.Query("username")
+	decision := ctx.Query("decision")
+
+	bid, err := r.bidService.SubmitDecision(ctx, entity.BidId(bidID), decision, userName)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotExists) {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				entity.ResponseError{Reason: service.ErrUserNotExists.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrNotEnoughRights) || errors.Is(err, service.ErrBidsSealed) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				entity.ResponseError{Reason: err.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrBidNotFound) || errors.Is(err, service.ErrTenderOrBidNotFound) ||
+			errors.Is(err, service.ErrLotNotFound) {
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				entity.ResponseError{Reason: err.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrLotSettled) || errors.Is(err, service.ErrTenderNotPublished) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+
+		if errors.Is(err, service.ErrWrongInputFormat) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+
+		r.log(ctx).Error("submit decision failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bid)
+}
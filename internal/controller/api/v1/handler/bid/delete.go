@@ -0,0 +1,55 @@
+package bid
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *bidRouter) delete(ctx *gin.Context) {
+	bidID := ctx.Param("id")
+
+	userName := ctx.Query("username")
+
+	if err := r.bidService.Delete(ctx, entity.BidId(bidID), userName); err != nil {
+		r.deleteError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (r *bidRouter) restore(ctx *gin.Context) {
+	bidID := ctx.Param("id")
+
+	userName := ctx.Query("username")
+
+	bid, err := r.bidService.Restore(ctx, entity.BidId(bidID), userName)
+	if err != nil {
+		r.deleteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bid)
+}
+
+func (r *bidRouter) deleteError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrBidNotFound), errors.Is(err, service.ErrTenderNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrBiddingClosed):
+		ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("delete request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
@@ -0,0 +1,73 @@
+package bid
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *bidRouter) edit(ctx *gin.Context) {
+	bidID := ctx.Param("id")
+
+	userName := ctx.Query("username")
+
+	var update entity.BidUpdate
+
+	if err := ctx.Bind(&update); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	bid, err := r.bidService.Edit(ctx, entity.BidId(bidID), &update, userName)
+	if err != nil {
+		r.log(ctx).Error("edit bid failed", zap.Error(err))
+
+		if errors.Is(err, service.ErrUserNotExists) {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				entity.ResponseError{Reason: service.ErrUserNotExists.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrNotEnoughRights) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrBidNotFound) || errors.Is(err, service.ErrTenderNotFound) {
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				entity.ResponseError{Reason: err.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrBiddingClosed) {
+			ctx.AbortWithStatusJSON(
+				http.StatusConflict,
+				entity.ResponseError{Reason: service.ErrBiddingClosed.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrWrongInputFormat) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bid)
+}
@@ -3,6 +3,7 @@ package bid
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -15,7 +16,17 @@ func (r *bidRouter) list(ctx *gin.Context) {
 
 	userName := ctx.Query("username")
 
-	bids, err := r.bidService.ListTenderBids(ctx, entity.TenderID(tenderID), userName)
+	var (
+		bids []*entity.Bid
+		err  error
+	)
+
+	if lotID := ctx.Query("lotId"); lotID != "" {
+		bids, err = r.bidService.ListLotBids(ctx, entity.TenderID(tenderID), entity.LotID(lotID), userName)
+	} else {
+		bids, err = r.bidService.ListTenderBids(ctx, entity.TenderID(tenderID), userName)
+	}
+
 	if err != nil {
 		if errors.Is(err, service.ErrUserNotExists) {
 			ctx.AbortWithStatusJSON(
@@ -45,9 +56,34 @@ func (r *bidRouter) list(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, bids)
 }
 
-// func (r *bidRouter) listMy(ctx *gin.Context) {
+func (r *bidRouter) listMy(ctx *gin.Context) {
+	userName := ctx.Query("username")
 
-// }
+	includeArchived := false
+	if value := ctx.Query("includeArchived"); value != "" {
+		var err error
+		if includeArchived, err = strconv.ParseBool(value); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: "includeArchived must be true or false"})
+			return
+		}
+	}
+
+	bids, err := r.bidService.ListBidsMy(ctx, userName, includeArchived)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotExists) {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				entity.ResponseError{Reason: service.ErrUserNotExists.Error()},
+			)
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bids)
+}
 
 func (r *bidRouter) status(ctx *gin.Context) {
 	bidID := ctx.Param("id")
@@ -1,32 +1,50 @@
 package bid
 
 import (
+	"context"
+
 	"avito2024/internal/app/core/service"
+	"avito2024/internal/controller/api/v1/handler/attachment"
+	"avito2024/internal/controller/api/v1/middleware"
+	"avito2024/internal/logger"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type bidRouter struct {
-	bidService *service.BidService
-	logger     *zap.Logger
+	bidService        *service.BidService
+	evaluationService *service.EvaluationService
+	logger            *zap.Logger
 }
 
 type serviceProvider interface {
 	BidService() *service.BidService
+	AttachmentService() *service.AttachmentService
+	IdempotencyService() *service.IdempotencyService
+	EvaluationService() *service.EvaluationService
 	Logger() *zap.Logger
 }
 
 func AttachToGroup(sp serviceProvider, group *gin.RouterGroup) {
 	br := &bidRouter{
-		bidService: sp.BidService(),
-		logger:     sp.Logger().Named("bid"),
+		bidService:        sp.BidService(),
+		evaluationService: sp.EvaluationService(),
+		logger:            sp.Logger().Named("bid"),
 	}
 
-	group.POST("/new", br.create)
-	// group.GET("/my", br.read)
+	group.POST("/new", middleware.Idempotency(sp.IdempotencyService(), br.logger), br.create)
+	group.GET("/my", br.listMy)
 	group.GET("/:id/list", br.list)
 	group.GET("/:id/status", br.status)
+	group.PATCH("/:id/edit", br.edit)
+	group.PUT("/:id/submit_decision", br.submitDecision)
+	group.PUT("/:id/scores", br.score)
+	group.DELETE("/:id", br.delete)
+	group.PUT("/:id/restore", br.restore)
+	attachment.AttachToGroup(sp, group.Group("/:id/attachments"), attachment.BidOwner("id"))
+}
 
-	//group.PUT("/:id", br.update)
+func (r *bidRouter) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
 }
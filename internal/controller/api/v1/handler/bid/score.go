@@ -0,0 +1,64 @@
+package bid
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *bidRouter) score(ctx *gin.Context) {
+	bidID := ctx.Param("id")
+
+	userName := ctx.Query("username")
+
+	var inputs []entity.BidScoreInput
+
+	if err := ctx.Bind(&inputs); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	scores, err := r.evaluationService.Score(ctx, entity.BidId(bidID), inputs, userName)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotExists) {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				entity.ResponseError{Reason: service.ErrUserNotExists.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrNotEnoughRights) || errors.Is(err, service.ErrBidsSealed) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				entity.ResponseError{Reason: err.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrBidNotFound) || errors.Is(err, service.ErrTenderOrBidNotFound) {
+			ctx.AbortWithStatusJSON(
+				http.StatusNotFound,
+				entity.ResponseError{Reason: err.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrWrongInputFormat) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+
+		r.log(ctx).Error("score bid failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scores)
+}
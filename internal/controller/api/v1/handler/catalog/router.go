@@ -0,0 +1,36 @@
+package catalog
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/service"
+	"avito2024/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type catalogRouter struct {
+	catalogService *service.CatalogService
+	logger         *zap.Logger
+}
+
+type serviceProvider interface {
+	CatalogService() *service.CatalogService
+	Logger() *zap.Logger
+}
+
+func AttachToGroup(sp serviceProvider, group *gin.RouterGroup) {
+	cr := &catalogRouter{
+		catalogService: sp.CatalogService(),
+		logger:         sp.Logger().Named("catalog"),
+	}
+
+	group.GET("", cr.list)
+	group.POST("", cr.create)
+	group.PATCH("/:name", cr.update)
+}
+
+func (r *catalogRouter) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
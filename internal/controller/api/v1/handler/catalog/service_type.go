@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *catalogRouter) list(ctx *gin.Context) {
+	serviceTypes, err := r.catalogService.ListServiceTypes(ctx)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, serviceTypes)
+}
+
+func (r *catalogRouter) create(ctx *gin.Context) {
+	userName := ctx.Query("username")
+
+	var serviceType entity.ServiceType
+
+	if err := ctx.Bind(&serviceType); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	if err := r.catalogService.CreateServiceType(ctx, &serviceType, userName); err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, serviceType)
+}
+
+func (r *catalogRouter) update(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	userName := ctx.Query("username")
+
+	var update entity.ServiceTypeUpdate
+
+	if err := ctx.Bind(&update); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	serviceType, err := r.catalogService.UpdateServiceType(ctx, entity.TenderServiceType(name), &update, userName)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, serviceType)
+}
+
+func (r *catalogRouter) abortWithError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrServiceTypeNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrServiceTypeExists):
+		ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("catalog request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
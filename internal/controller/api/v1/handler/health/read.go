@@ -0,0 +1,26 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+)
+
+func (r *healthRouter) live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, r.healthService.Live())
+}
+
+func (r *healthRouter) ready(ctx *gin.Context) {
+	report := r.healthService.Ready(ctx)
+
+	if report.Status != entity.HealthStatusUp {
+		r.log(ctx).Warn("not ready", zap.Any("checks", report.Checks))
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,35 @@
+package health
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/service"
+	"avito2024/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type healthRouter struct {
+	healthService *service.HealthService
+	logger        *zap.Logger
+}
+
+type serviceProvider interface {
+	HealthService() *service.HealthService
+	Logger() *zap.Logger
+}
+
+func AttachToGroup(sp serviceProvider, group *gin.RouterGroup) {
+	hr := &healthRouter{
+		healthService: sp.HealthService(),
+		logger:        sp.Logger().Named("health"),
+	}
+
+	group.GET("/live", hr.live)
+	group.GET("/ready", hr.ready)
+}
+
+func (r *healthRouter) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *notificationRouter) inbox(ctx *gin.Context) {
+	userName := ctx.Query("username")
+	unreadOnly := ctx.Query("unread") == "true"
+
+	limitOffset := entity.ParseRequestLimitOffset(ctx.Query("limit"), ctx.Query("offset"))
+	if limitOffset == nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{
+			Reason: "cannot parse limit or offset",
+		})
+		return
+	}
+
+	notifications, err := r.notificationService.Inbox(ctx, userName, unreadOnly, limitOffset)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notifications)
+}
+
+func (r *notificationRouter) markRead(ctx *gin.Context) {
+	notificationID := ctx.Param("notificationId")
+
+	userName := ctx.Query("username")
+
+	if err := r.notificationService.MarkRead(ctx, entity.NotificationID(notificationID), userName); err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (r *notificationRouter) preferences(ctx *gin.Context) {
+	userName := ctx.Query("username")
+
+	preferences, err := r.notificationService.Preferences(ctx, userName)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferences)
+}
+
+func (r *notificationRouter) setPreferences(ctx *gin.Context) {
+	userName := ctx.Query("username")
+
+	var preferences entity.NotificationPreferences
+
+	if err := ctx.Bind(&preferences); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	saved, err := r.notificationService.SetPreferences(ctx, &preferences, userName)
+	if err != nil {
+		r.abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
+
+func (r *notificationRouter) abortWithError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotificationNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("notification request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+
+	"avito2024/internal/app/core/service"
+	"avito2024/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type notificationRouter struct {
+	notificationService *service.NotificationService
+	logger              *zap.Logger
+}
+
+type serviceProvider interface {
+	NotificationService() *service.NotificationService
+	Logger() *zap.Logger
+}
+
+func AttachToGroup(sp serviceProvider, group *gin.RouterGroup) {
+	nr := &notificationRouter{
+		notificationService: sp.NotificationService(),
+		logger:              sp.Logger().Named("notification"),
+	}
+
+	group.GET("", nr.inbox)
+	group.PUT("/:notificationId/read", nr.markRead)
+	group.GET("/preferences", nr.preferences)
+	group.PUT("/preferences", nr.setPreferences)
+}
+
+func (r *notificationRouter) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,89 @@
+package tender
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *tenderRouter) createAuction(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	var auction entity.Auction
+
+	if err := ctx.Bind(&auction); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	created, err := r.auctionService.Create(ctx, entity.TenderID(tenderID), &auction, userName)
+	if err != nil {
+		r.auctionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (r *tenderRouter) auction(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	auction, err := r.auctionService.Get(ctx, entity.TenderID(tenderID), userName)
+	if err != nil {
+		r.auctionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, auction)
+}
+
+func (r *tenderRouter) placeAuctionOffer(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	var input entity.AuctionOfferInput
+
+	if err := ctx.Bind(&input); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	offer, err := r.auctionService.PlaceOffer(ctx, entity.TenderID(tenderID), &input, userName)
+	if err != nil {
+		r.auctionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, offer)
+}
+
+func (r *tenderRouter) auctionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrTenderNotFound), errors.Is(err, service.ErrTenderOrBidNotFound),
+		errors.Is(err, service.ErrBidNotFound), errors.Is(err, service.ErrAuctionNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrAuctionNotRunning), errors.Is(err, service.ErrOfferTooHigh):
+		ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("auction request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
@@ -15,7 +15,7 @@ func (r *tenderRouter) create(ctx *gin.Context) {
 	var tender entity.RequestTender
 
 	if err := ctx.Bind(&tender); err != nil {
-		r.logger.Error("bind failed", zap.Error(err))
+		r.log(ctx).Error("bind failed", zap.Error(err))
 		ctx.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
@@ -30,6 +30,14 @@ func (r *tenderRouter) create(ctx *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, service.ErrWrongInputFormat) {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				entity.ResponseError{Reason: err.Error()},
+			)
+			return
+		}
+
 		if errors.Is(err, service.ErrNotEnoughRights) {
 			ctx.AbortWithStatusJSON(
 				http.StatusForbidden,
@@ -38,7 +46,7 @@ func (r *tenderRouter) create(ctx *gin.Context) {
 			return
 		}
 
-		r.logger.Error("failed to create tender", zap.Any("reqBody", tender), zap.Error(err))
+		r.log(ctx).Error("failed to create tender", zap.Any("reqBody", tender), zap.Error(err))
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{
 			Reason: err.Error(),
 		})
@@ -0,0 +1,53 @@
+package tender
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *tenderRouter) delete(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	if err := r.tenderService.Delete(ctx, entity.TenderID(tenderID), userName); err != nil {
+		r.deleteError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (r *tenderRouter) restore(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	tender, err := r.tenderService.Restore(ctx, entity.TenderID(tenderID), userName)
+	if err != nil {
+		r.deleteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tender)
+}
+
+func (r *tenderRouter) deleteError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrTenderNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("delete request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
@@ -19,14 +19,14 @@ func (r *tenderRouter) edit(ctx *gin.Context) {
 	var update entity.TenderUpdate
 
 	if err := ctx.Bind(&update); err != nil {
-		r.logger.Error("bind failed", zap.Error(err))
+		r.log(ctx).Error("bind failed", zap.Error(err))
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
 		return
 	}
 
 	updatedTender, err := r.tenderService.Edit(ctx, entity.TenderID(tenderID), &update, userName)
 	if err != nil {
-		r.logger.Error("update tender failed", zap.Error(err))
+		r.log(ctx).Error("update tender failed", zap.Error(err))
 
 		if errors.Is(err, service.ErrUserNotExists) {
 			ctx.AbortWithStatusJSON(
@@ -53,7 +53,8 @@ func (r *tenderRouter) edit(ctx *gin.Context) {
 		}
 
 		if errors.Is(err, service.ErrWrongInputFormat) {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
 		}
 
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
@@ -0,0 +1,78 @@
+package tender
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *tenderRouter) setCriteria(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	var criteria []*entity.EvaluationCriterion
+
+	if err := ctx.Bind(&criteria); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	criteria, err := r.evaluationService.SetCriteria(ctx, entity.TenderID(tenderID), criteria, userName)
+	if err != nil {
+		r.evaluationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, criteria)
+}
+
+func (r *tenderRouter) criteria(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	criteria, err := r.evaluationService.Criteria(ctx, entity.TenderID(tenderID), userName)
+	if err != nil {
+		r.evaluationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, criteria)
+}
+
+func (r *tenderRouter) compareBids(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	comparison, err := r.evaluationService.Compare(ctx, entity.TenderID(tenderID), userName)
+	if err != nil {
+		r.evaluationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comparison)
+}
+
+func (r *tenderRouter) evaluationError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrTenderNotFound), errors.Is(err, service.ErrTenderOrBidNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("evaluation request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
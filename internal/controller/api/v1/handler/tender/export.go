@@ -0,0 +1,175 @@
+package tender
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+// exportFlushRows is how many rows are buffered before they are sent to the client.
+const exportFlushRows = 100
+
+var exportColumns = []string{"id", "name", "description", "serviceType", "status", "organizationId", "version", "createdAt"}
+
+// tenderWriter writes tenders of an export in one format.
+type tenderWriter interface {
+	start() error
+	write(*entity.Tender) error
+	flush() error
+}
+
+func (r *tenderRouter) export(ctx *gin.Context) {
+	userName := ctx.Query("username")
+	if userName == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{
+			Reason: "username query param is empty",
+		})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", formatCSV)
+
+	var (
+		writer      tenderWriter
+		contentType string
+	)
+
+	switch format {
+	case formatCSV:
+		writer, contentType = &csvTenderWriter{csv: csv.NewWriter(ctx.Writer)}, "text/csv; charset=utf-8"
+	case formatJSONL:
+		writer, contentType = &jsonlTenderWriter{encoder: json.NewEncoder(ctx.Writer)}, "application/jsonl"
+	default:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: "format must be csv or jsonl"})
+		return
+	}
+
+	// The server write timeout would cut a long export off, it gets as long as its statement instead.
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Now().Add(r.exportTimeout)); err != nil {
+		r.log(ctx).Warn("export write deadline not extended", zap.Error(err))
+	}
+
+	// Headers are sent with the first row, until then an error still gets a JSON response.
+	started := false
+	start := func() error {
+		started = true
+
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Disposition", `attachment; filename="tenders.`+format+`"`)
+		ctx.Status(http.StatusOK)
+
+		return writer.start()
+	}
+
+	rows := 0
+
+	err := r.tenderService.ExportMy(ctx, userName, func(tender *entity.Tender) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.write(tender); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := writer.flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+
+	if err != nil {
+		if started {
+			// The status is already sent, the client sees a truncated file.
+			r.log(ctx).Error("tender export interrupted", zap.Int("rows", rows), zap.Error(err))
+			ctx.Abort()
+			return
+		}
+
+		if errors.Is(err, service.ErrUserNotExists) {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				entity.ResponseError{Reason: service.ErrUserNotExists.Error()},
+			)
+			return
+		}
+
+		if errors.Is(err, service.ErrNotEnoughRights) {
+			ctx.AbortWithStatusJSON(
+				http.StatusForbidden,
+				entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()},
+			)
+			return
+		}
+
+		r.log(ctx).Error("failed to export tenders", zap.String("username", userName), zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+		return
+	}
+
+	if err := writer.flush(); err != nil {
+		r.log(ctx).Error("tender export interrupted", zap.Int("rows", rows), zap.Error(err))
+	}
+}
+
+type csvTenderWriter struct {
+	csv *csv.Writer
+}
+
+func (r *csvTenderWriter) start() error {
+	return r.csv.Write(exportColumns)
+}
+
+func (r *csvTenderWriter) write(tender *entity.Tender) error {
+	return r.csv.Write([]string{
+		string(tender.ID),
+		string(tender.Name),
+		string(tender.Description),
+		string(tender.ServiceType),
+		string(tender.Status),
+		string(tender.OrganizationID),
+		strconv.Itoa(int(tender.Version)),
+		tender.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+func (r *csvTenderWriter) flush() error {
+	r.csv.Flush()
+	return r.csv.Error()
+}
+
+type jsonlTenderWriter struct {
+	encoder *json.Encoder
+}
+
+func (r *jsonlTenderWriter) start() error {
+	return nil
+}
+
+// write relies on Encode terminating every value with a newline.
+func (r *jsonlTenderWriter) write(tender *entity.Tender) error {
+	return r.encoder.Encode(tender)
+}
+
+func (r *jsonlTenderWriter) flush() error {
+	return nil
+}
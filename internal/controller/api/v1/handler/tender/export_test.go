@@ -0,0 +1,183 @@
+package tender
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+)
+
+func exportedTenders(n int) []*entity.Tender {
+	createdAt := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tenders := make([]*entity.Tender, 0, n)
+	for i := range n {
+		tenders = append(tenders, &entity.Tender{
+			ID:             entity.TenderID(fmt.Sprintf("00000000-0000-4000-8000-%012d", i)),
+			Name:           entity.TenderName(fmt.Sprintf("tender %d", i)),
+			Description:    "roads, bridges",
+			ServiceType:    "Construction",
+			Status:         entity.TenderStatusPublished,
+			OrganizationID: testOrganizationID,
+			Version:        1,
+			CreatedAt:      createdAt,
+		})
+	}
+
+	return tenders
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		tenders    int
+		streamErr  error
+		wantStatus int
+		wantType   string
+		// check parses the exported file.
+		check func(t *testing.T, body string)
+	}{
+		{
+			name:       "csv",
+			query:      "?username=alice",
+			tenders:    exportFlushRows + 1,
+			wantStatus: http.StatusOK,
+			wantType:   "text/csv; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(records) != exportFlushRows+2 {
+					t.Fatalf("records = %d, want a header and %d rows", len(records), exportFlushRows+1)
+				}
+
+				if !slices.Equal(records[0], exportColumns) {
+					t.Errorf("header = %v, want %v", records[0], exportColumns)
+				}
+
+				want := []string{
+					"00000000-0000-4000-8000-000000000000",
+					"tender 0",
+					"roads, bridges",
+					"Construction",
+					"Published",
+					string(testOrganizationID),
+					"1",
+					"2024-09-01T12:00:00Z",
+				}
+				if !slices.Equal(records[1], want) {
+					t.Errorf("first row = %v, want %v", records[1], want)
+				}
+			},
+		},
+		{
+			name:       "jsonl",
+			query:      "?username=alice&format=jsonl",
+			tenders:    3,
+			wantStatus: http.StatusOK,
+			wantType:   "application/jsonl",
+			check: func(t *testing.T, body string) {
+				scanner := bufio.NewScanner(strings.NewReader(body))
+
+				var ids []entity.TenderID
+				for scanner.Scan() {
+					var tender entity.Tender
+					if err := json.Unmarshal(scanner.Bytes(), &tender); err != nil {
+						t.Fatalf("line %d: %v", len(ids)+1, err)
+					}
+
+					ids = append(ids, tender.ID)
+				}
+
+				want := []entity.TenderID{
+					"00000000-0000-4000-8000-000000000000",
+					"00000000-0000-4000-8000-000000000001",
+					"00000000-0000-4000-8000-000000000002",
+				}
+				if !slices.Equal(ids, want) {
+					t.Errorf("exported ids = %v, want %v", ids, want)
+				}
+			},
+		},
+		{
+			name:       "no tenders still gets a header",
+			query:      "?username=alice",
+			wantStatus: http.StatusOK,
+			wantType:   "text/csv; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				if want := strings.Join(exportColumns, ",") + "\n"; body != want {
+					t.Errorf("body = %q, want %q", body, want)
+				}
+			},
+		},
+		{
+			name:       "failure before the first row",
+			query:      "?username=alice",
+			streamErr:  errors.New("connection reset"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "application/json; charset=utf-8",
+		},
+		{
+			name:       "failure after the first row truncates the file",
+			query:      "?username=alice",
+			tenders:    2,
+			streamErr:  errors.New("connection reset"),
+			wantStatus: http.StatusOK,
+			wantType:   "text/csv; charset=utf-8",
+		},
+		{
+			name:       "missing username",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown user",
+			query:      "?username=mallory",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "user without an organization",
+			query:      "?username=bob",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "unknown format",
+			query:      "?username=alice&format=xlsx",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &tenderRepoStub{tenders: exportedTenders(tt.tenders), streamErr: tt.streamErr}
+			router := newTestRouter(repo)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/my/export"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if tt.wantType != "" && rec.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.wantType)
+			}
+
+			if tt.check != nil {
+				tt.check(t, rec.Body.String())
+			}
+		})
+	}
+}
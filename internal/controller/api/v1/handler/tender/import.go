@@ -0,0 +1,195 @@
+package tender
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/app/core/entity"
+)
+
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+
+	maxImportBytes = 10 << 20
+	maxImportRows  = 1000
+)
+
+// importColumns are the CSV columns of an import, named like the JSON fields of entity.RequestTender.
+var importColumns = []string{"name", "description", "serviceType", "organizationId", "creatorUsername"}
+
+func (r *tenderRouter) importTenders(ctx *gin.Context) {
+	format := ctx.Query("format")
+	if format == "" {
+		format = formatFromContentType(ctx.ContentType())
+	}
+
+	atomic := false
+	if value := ctx.Query("atomic"); value != "" {
+		var err error
+		if atomic, err = strconv.ParseBool(value); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: "atomic must be true or false"})
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes)
+
+	var (
+		rows []*entity.RequestTender
+		err  error
+	)
+
+	switch format {
+	case formatCSV:
+		rows, err = parseCSV(body)
+	case formatJSONL:
+		rows, err = parseJSONL(body)
+	default:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{
+			Reason: "format must be csv or jsonl, pass it as a query param or Content-Type",
+		})
+		return
+	}
+
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+		return
+	}
+
+	report, err := r.tenderService.Import(ctx, rows, atomic)
+	if err != nil {
+		r.log(ctx).Error("failed to import tenders", zap.Int("rows", len(rows)), zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+		return
+	}
+
+	if report.Atomic && !report.Committed {
+		ctx.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "text/csv":
+		return formatCSV
+	case "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return formatJSONL
+	default:
+		return ""
+	}
+}
+
+func parseCSV(body io.Reader) ([]*entity.RequestTender, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv header is missing")
+		}
+
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		if !slices.Contains(importColumns, column) {
+			return nil, fmt.Errorf("unknown csv column %q, expected %v", column, importColumns)
+		}
+
+		index[column] = i
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := index[column]; ok {
+			return record[i]
+		}
+
+		return ""
+	}
+
+	var rows []*entity.RequestTender
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("at most %d rows can be imported at once", maxImportRows)
+		}
+
+		rows = append(rows, &entity.RequestTender{
+			Tender: entity.Tender{
+				Name:           entity.TenderName(field(record, "name")),
+				Description:    entity.TenderDescription(field(record, "description")),
+				ServiceType:    entity.TenderServiceType(field(record, "serviceType")),
+				OrganizationID: entity.OrganizationID(field(record, "organizationId")),
+			},
+			Username: field(record, "creatorUsername"),
+		})
+	}
+
+	return rows, nil
+}
+
+func parseJSONL(body io.Reader) ([]*entity.RequestTender, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportBytes)
+
+	var rows []*entity.RequestTender
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("at most %d rows can be imported at once", maxImportRows)
+		}
+
+		var row entity.RequestTender
+		if err := json.Unmarshal(data, &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rows = append(rows, &row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
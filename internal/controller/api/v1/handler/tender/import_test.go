@@ -0,0 +1,211 @@
+package tender
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"avito2024/internal/app/core/entity"
+)
+
+func csvRows(n int) string {
+	var b strings.Builder
+
+	b.WriteString("name,serviceType,organizationId,creatorUsername\n")
+	for i := range n {
+		fmt.Fprintf(&b, "tender %d,Construction,%s,alice\n", i, testOrganizationID)
+	}
+
+	return b.String()
+}
+
+func TestImportTenders(t *testing.T) {
+	validJSONL := fmt.Sprintf(
+		`{"name":"a","serviceType":"Construction","organizationId":%q,"creatorUsername":"alice"}`,
+		testOrganizationID,
+	)
+	// The second row fails in the repo, the third in the service.
+	mixedCSV := "name,serviceType,organizationId,creatorUsername\n" +
+		"a,Construction," + string(testOrganizationID) + ",alice\n" +
+		"b,Construction," + string(testMissingOrganizationID) + ",alice\n" +
+		"c,Delivery," + string(testOrganizationID) + ",alice\n"
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		wantStatus  int
+		wantReason  string
+		// wantReport is checked when set, wantStored always.
+		wantReport *entity.TenderImportReport
+		wantStored int
+	}{
+		{
+			name:        "csv",
+			contentType: "text/csv",
+			body:        csvRows(2),
+			wantStatus:  http.StatusOK,
+			wantReport:  &entity.TenderImportReport{Committed: true, Created: 2},
+			wantStored:  2,
+		},
+		{
+			name:        "jsonl with blank lines",
+			contentType: "application/x-ndjson; charset=utf-8",
+			body:        validJSONL + "\n\n" + validJSONL + "\n",
+			wantStatus:  http.StatusOK,
+			wantReport:  &entity.TenderImportReport{Committed: true, Created: 2},
+			wantStored:  2,
+		},
+		{
+			name:       "partial success",
+			query:      "?format=csv",
+			body:       mixedCSV,
+			wantStatus: http.StatusOK,
+			wantReport: &entity.TenderImportReport{Committed: true, Created: 1, Failed: 2},
+			wantStored: 1,
+		},
+		{
+			name:       "atomic rollback",
+			query:      "?format=csv&atomic=true",
+			body:       mixedCSV,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantReport: &entity.TenderImportReport{Atomic: true, Created: 0, Failed: 2},
+			wantStored: 0,
+		},
+		{
+			name:       "atomic commit",
+			query:      "?format=csv&atomic=true",
+			body:       csvRows(3),
+			wantStatus: http.StatusOK,
+			wantReport: &entity.TenderImportReport{Atomic: true, Committed: true, Created: 3},
+			wantStored: 3,
+		},
+		{
+			name:       "malformed csv row",
+			query:      "?format=csv",
+			body:       "name,serviceType\na,Construction\nb,Construction,extra\n",
+			wantStatus: http.StatusBadRequest,
+			wantReason: "wrong number of fields",
+		},
+		{
+			name:       "unknown csv column",
+			query:      "?format=csv",
+			body:       "name,budget\na,100\n",
+			wantStatus: http.StatusBadRequest,
+			wantReason: `unknown csv column "budget"`,
+		},
+		{
+			name:       "empty csv",
+			query:      "?format=csv",
+			wantStatus: http.StatusBadRequest,
+			wantReason: "csv header is missing",
+		},
+		{
+			name:       "malformed jsonl row",
+			query:      "?format=jsonl",
+			body:       validJSONL + "\n{\"name\":\n",
+			wantStatus: http.StatusBadRequest,
+			wantReason: "line 2",
+		},
+		{
+			name:       "rows at the limit",
+			query:      "?format=csv",
+			body:       csvRows(maxImportRows),
+			wantStatus: http.StatusOK,
+			wantStored: maxImportRows,
+		},
+		{
+			name:       "too many csv rows",
+			query:      "?format=csv",
+			body:       csvRows(maxImportRows + 1),
+			wantStatus: http.StatusBadRequest,
+			wantReason: "at most 1000 rows",
+		},
+		{
+			name:       "too many jsonl rows",
+			query:      "?format=jsonl",
+			body:       strings.Repeat(validJSONL+"\n", maxImportRows+1),
+			wantStatus: http.StatusBadRequest,
+			wantReason: "at most 1000 rows",
+		},
+		{
+			name:       "body over the limit",
+			query:      "?format=csv",
+			body:       "name,description\na," + strings.Repeat("x", maxImportBytes) + "\n",
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "unknown format",
+			query:      "?format=xml",
+			body:       "<tenders/>",
+			wantStatus: http.StatusBadRequest,
+			wantReason: "format must be csv or jsonl",
+		},
+		{
+			name:       "malformed atomic",
+			query:      "?format=csv&atomic=yes",
+			body:       csvRows(1),
+			wantStatus: http.StatusBadRequest,
+			wantReason: "atomic must be true or false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &tenderRepoStub{}
+			router := newTestRouter(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/import"+tt.query, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %.200s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if len(repo.tenders) != tt.wantStored {
+				t.Errorf("stored tenders = %d, want %d", len(repo.tenders), tt.wantStored)
+			}
+
+			if tt.wantReason != "" {
+				var resp entity.ResponseError
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+
+				if !strings.Contains(resp.Reason, tt.wantReason) {
+					t.Errorf("reason = %q, want it to contain %q", resp.Reason, tt.wantReason)
+				}
+			}
+
+			if tt.wantReport == nil {
+				return
+			}
+
+			var report entity.TenderImportReport
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Atomic != tt.wantReport.Atomic || report.Committed != tt.wantReport.Committed ||
+				report.Created != tt.wantReport.Created || report.Failed != tt.wantReport.Failed {
+				t.Errorf("report = %+v, want %+v", report, *tt.wantReport)
+			}
+
+			for _, row := range report.Rows {
+				if (row.TenderID != "") != (row.Error == "" && report.Committed) {
+					t.Errorf("row %d has tenderId %q and error %q in a report committed=%v",
+						row.Row, row.TenderID, row.Error, report.Committed)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package tender
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *tenderRouter) invite(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	var invitation entity.Invitation
+
+	if err := ctx.Bind(&invitation); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	created, err := r.tenderService.Invite(ctx, entity.TenderID(tenderID), &invitation, userName)
+	if err != nil {
+		r.invitationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+func (r *tenderRouter) listInvitations(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	invitations, err := r.tenderService.ListInvitations(ctx, entity.TenderID(tenderID), userName)
+	if err != nil {
+		r.invitationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+func (r *tenderRouter) revokeInvitation(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+	invitationID := ctx.Param("invitationId")
+
+	userName := ctx.Query("username")
+
+	if err := r.tenderService.RevokeInvitation(ctx, entity.TenderID(tenderID), entity.InvitationID(invitationID), userName); err != nil {
+		r.invitationError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (r *tenderRouter) myInvitations(ctx *gin.Context) {
+	userName := ctx.Query("username")
+
+	invitations, err := r.tenderService.MyInvitations(ctx, userName)
+	if err != nil {
+		r.invitationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+func (r *tenderRouter) respondInvitation(ctx *gin.Context) {
+	invitationID := ctx.Param("invitationId")
+
+	userName := ctx.Query("username")
+	response := ctx.Query("response")
+
+	invitation, err := r.tenderService.RespondInvitation(
+		ctx,
+		entity.InvitationID(invitationID),
+		entity.InvitationStatus(response),
+		userName,
+	)
+	if err != nil {
+		r.invitationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitation)
+}
+
+func (r *tenderRouter) invitationError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrTenderNotFound), errors.Is(err, service.ErrInvitationNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrAlreadyInvited):
+		ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("invitation request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
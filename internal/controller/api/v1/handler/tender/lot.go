@@ -0,0 +1,80 @@
+package tender
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+func (r *tenderRouter) createLot(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	var lot entity.Lot
+
+	if err := ctx.Bind(&lot); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	if err := r.tenderService.CreateLot(ctx, entity.TenderID(tenderID), &lot, userName); err != nil {
+		r.lotError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, lot)
+}
+
+func (r *tenderRouter) listLots(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	lots, err := r.tenderService.ListLots(ctx, entity.TenderID(tenderID), userName)
+	if err != nil {
+		r.lotError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lots)
+}
+
+func (r *tenderRouter) cancelLot(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+	lotID := ctx.Param("lotId")
+
+	userName := ctx.Query("username")
+
+	lot, err := r.tenderService.CancelLot(ctx, entity.TenderID(tenderID), entity.LotID(lotID), userName)
+	if err != nil {
+		r.lotError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lot)
+}
+
+func (r *tenderRouter) lotError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrTenderNotFound), errors.Is(err, service.ErrLotNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrLotSettled):
+		ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("lot request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
@@ -0,0 +1,93 @@
+package tender
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+type askRequest struct {
+	Text string `json:"text"`
+}
+
+func (r *tenderRouter) askQuestion(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	var request askRequest
+
+	if err := ctx.Bind(&request); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	question, err := r.questionService.Ask(ctx, entity.TenderID(tenderID), request.Text, userName)
+	if err != nil {
+		r.questionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, question)
+}
+
+func (r *tenderRouter) listQuestions(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+
+	userName := ctx.Query("username")
+
+	questions, err := r.questionService.List(ctx, entity.TenderID(tenderID), userName)
+	if err != nil {
+		r.questionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, questions)
+}
+
+func (r *tenderRouter) answerQuestion(ctx *gin.Context) {
+	tenderID := ctx.Param("tenderId")
+	questionID := ctx.Param("questionId")
+
+	userName := ctx.Query("username")
+
+	var answer entity.QuestionAnswer
+
+	if err := ctx.Bind(&answer); err != nil {
+		r.log(ctx).Error("bind failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()})
+		return
+	}
+
+	question, err := r.questionService.Answer(ctx, entity.TenderID(tenderID), entity.QuestionID(questionID), &answer, userName)
+	if err != nil {
+		r.questionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, question)
+}
+
+func (r *tenderRouter) questionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotExists):
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, entity.ResponseError{Reason: service.ErrUserNotExists.Error()})
+	case errors.Is(err, service.ErrNotEnoughRights):
+		ctx.AbortWithStatusJSON(http.StatusForbidden, entity.ResponseError{Reason: service.ErrNotEnoughRights.Error()})
+	case errors.Is(err, service.ErrTenderNotFound), errors.Is(err, service.ErrQuestionNotFound):
+		ctx.AbortWithStatusJSON(http.StatusNotFound, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrBiddingClosed):
+		ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+	case errors.Is(err, service.ErrWrongInputFormat):
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+	default:
+		r.log(ctx).Error("question request failed", zap.Error(err))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+	}
+}
@@ -31,9 +31,19 @@ func (r *tenderRouter) listMy(ctx *gin.Context) {
 		return
 	}
 
-	tenders, err := r.tenderService.ListMy(ctx, userName, limitOffset)
+	options, ok := r.listOptions(ctx)
+	if !ok {
+		return
+	}
+
+	tenders, err := r.tenderService.ListMy(ctx, userName, options, limitOffset)
 	if err != nil {
-		r.logger.Error("failed to list users tenders", zap.String("username", userName), zap.Error(err))
+		r.log(ctx).Error("failed to list users tenders", zap.String("username", userName), zap.Error(err))
+
+		if errors.Is(err, service.ErrWrongInputFormat) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
+		}
 
 		if errors.Is(err, service.ErrUserNotExists) {
 			ctx.AbortWithStatusJSON(
@@ -80,12 +90,28 @@ func (r *tenderRouter) list(ctx *gin.Context) {
 		tenderServiceType = append(tenderServiceType, entity.TenderServiceType(service))
 	}
 
-	tenders, err := r.tenderService.List(ctx, tenderServiceType, limitOffset)
+	options, ok := r.listOptions(ctx)
+	if !ok {
+		return
+	}
+
+	// The username is optional, without it only open tenders are listed.
+	userName := ctx.Query("username")
+
+	tenders, err := r.tenderService.List(ctx, tenderServiceType, userName, options, limitOffset)
 	if err != nil {
+		if errors.Is(err, service.ErrUserNotExists) {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				entity.ResponseError{Reason: service.ErrUserNotExists.Error()},
+			)
+			return
+		}
+
 		if errors.Is(err, service.ErrWrongInputFormat) {
 			ctx.AbortWithStatusJSON(
 				http.StatusBadRequest,
-				entity.ResponseError{Reason: service.ErrWrongInputFormat.Error()},
+				entity.ResponseError{Reason: err.Error()},
 			)
 			return
 		}
@@ -106,3 +132,21 @@ func (r *tenderRouter) list(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, tenders)
 }
+
+// listOptions reads the budget filter, the sort order and whether archived
+// tenders are included, which the listings share.
+func (r *tenderRouter) listOptions(ctx *gin.Context) (*entity.TenderListOptions, bool) {
+	options, err := entity.ParseTenderListOptions(
+		ctx.Query("currency"),
+		ctx.Query("budget_min"),
+		ctx.Query("budget_max"),
+		ctx.Query("sort"),
+		ctx.Query("includeArchived"),
+	)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+		return nil, false
+	}
+
+	return options, true
+}
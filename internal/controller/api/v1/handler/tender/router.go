@@ -1,33 +1,79 @@
 package tender
 
 import (
+	"context"
+	"time"
+
 	"avito2024/internal/app/core/service"
+	"avito2024/internal/controller/api/v1/handler/attachment"
+	"avito2024/internal/controller/api/v1/middleware"
+	"avito2024/internal/logger"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type tenderRouter struct {
-	tenderService *service.TenderService
-	logger        *zap.Logger
+	tenderService     *service.TenderService
+	evaluationService *service.EvaluationService
+	auctionService    *service.AuctionService
+	questionService   *service.QuestionService
+	exportTimeout     time.Duration
+	logger            *zap.Logger
 }
 
 type serviceProvider interface {
 	TenderService() *service.TenderService
+	AttachmentService() *service.AttachmentService
+	IdempotencyService() *service.IdempotencyService
+	EvaluationService() *service.EvaluationService
+	AuctionService() *service.AuctionService
+	QuestionService() *service.QuestionService
+	ExportTimeout() time.Duration
 	Logger() *zap.Logger
 }
 
 func AttachToGroup(sp serviceProvider, group *gin.RouterGroup) {
 	tr := &tenderRouter{
-		tenderService: sp.TenderService(),
-		logger:        sp.Logger().Named("tender"),
+		tenderService:     sp.TenderService(),
+		evaluationService: sp.EvaluationService(),
+		auctionService:    sp.AuctionService(),
+		questionService:   sp.QuestionService(),
+		exportTimeout:     sp.ExportTimeout(),
+		logger:            sp.Logger().Named("tender"),
 	}
 
-	group.POST("/new", tr.create)
+	group.POST("/new", middleware.Idempotency(sp.IdempotencyService(), tr.logger), tr.create)
 	group.GET("/", tr.list)
 	group.GET("/my", tr.listMy)
+	group.POST("/import", middleware.Idempotency(sp.IdempotencyService(), tr.logger), tr.importTenders)
+	group.GET("/my/export", tr.export)
 	group.GET("/:tenderId/status", tr.status)
 	group.PUT("/:tenderId/status", tr.updateStatus)
 	group.PATCH("/:tenderId/edit", tr.edit)
+	group.DELETE("/:tenderId", tr.delete)
+	group.PUT("/:tenderId/restore", tr.restore)
+	group.GET("/:tenderId/criteria", tr.criteria)
+	group.PUT("/:tenderId/criteria", tr.setCriteria)
+	group.GET("/:tenderId/bids/comparison", tr.compareBids)
+	group.POST("/:tenderId/lots", tr.createLot)
+	group.GET("/:tenderId/lots", tr.listLots)
+	group.PUT("/:tenderId/lots/:lotId/cancel", tr.cancelLot)
+	group.POST("/:tenderId/auction", tr.createAuction)
+	group.GET("/:tenderId/auction", tr.auction)
+	group.POST("/:tenderId/auction/offers", tr.placeAuctionOffer)
+	group.POST("/:tenderId/invitations", tr.invite)
+	group.GET("/:tenderId/invitations", tr.listInvitations)
+	group.DELETE("/:tenderId/invitations/:invitationId", tr.revokeInvitation)
+	group.GET("/invitations/my", tr.myInvitations)
+	group.PUT("/invitations/:invitationId/respond", tr.respondInvitation)
+	group.POST("/:tenderId/questions", tr.askQuestion)
+	group.GET("/:tenderId/questions", tr.listQuestions)
+	group.PUT("/:tenderId/questions/:questionId/answer", tr.answerQuestion)
+	attachment.AttachToGroup(sp, group.Group("/:tenderId/attachments"), attachment.TenderOwner("tenderId"))
 	// group.PUT("/:tenderId/rollback/:version", tr.rollback)
 }
+
+func (r *tenderRouter) log(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,182 @@
+package tender
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/port"
+	"avito2024/internal/app/core/service"
+)
+
+const (
+	// alice is responsible for testOrganizationID, bob for no organization.
+	aliceID            = entity.UserID("6f1c3a52-0a3e-4c1f-9d4b-1b2e3c4d5e6f")
+	bobID              = entity.UserID("0e9d8c7b-6a5f-4e3d-8c2b-1a0f9e8d7c6b")
+	testOrganizationID = entity.OrganizationID("9b8a7c6d-5e4f-4321-9fed-cba987654321")
+	// testMissingOrganizationID is rejected by tenderRepoStub the way the schema rejects it.
+	testMissingOrganizationID = entity.OrganizationID("00000000-0000-4000-8000-000000000000")
+)
+
+type txKey struct{}
+
+// tx keeps the tenders created in a transaction until it commits.
+type tx struct {
+	created []*entity.Tender
+}
+
+// tenderRepoStub keeps committed tenders in a slice, the tenders created in a
+// transaction only reach it once the transaction commits.
+type tenderRepoStub struct {
+	port.TenderRepo
+	tenders   []*entity.Tender
+	streamErr error
+}
+
+// transactorStub commits the tenders created in a transaction to repo.
+type transactorStub struct {
+	repo *tenderRepoStub
+}
+
+func (r transactorStub) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.InTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	t := &tx{}
+	if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+		return err
+	}
+
+	r.repo.tenders = append(r.repo.tenders, t.created...)
+
+	return nil
+}
+
+func (r transactorStub) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	fn(ctx)
+}
+
+func (r transactorStub) InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*tx)
+	return ok
+}
+
+func (r *tenderRepoStub) Create(ctx context.Context, tender *entity.Tender) error {
+	if tender.OrganizationID == testMissingOrganizationID {
+		return errors.New("organization does not exist")
+	}
+
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		t.created = append(t.created, tender)
+		return nil
+	}
+
+	r.tenders = append(r.tenders, tender)
+
+	return nil
+}
+
+func (r *tenderRepoStub) StreamMy(_ context.Context, _ []entity.OrganizationID, fn func(*entity.Tender) error) error {
+	for _, tender := range r.tenders {
+		if err := fn(tender); err != nil {
+			return err
+		}
+	}
+
+	return r.streamErr
+}
+
+type serviceTypeRepoStub struct {
+	port.ServiceTypeRepo
+}
+
+func (r serviceTypeRepoStub) List(context.Context) ([]*entity.ServiceType, error) {
+	return []*entity.ServiceType{
+		{Name: "Construction"},
+		{Name: "Delivery", Archived: true},
+	}, nil
+}
+
+type userRepoStub struct{}
+
+func (r userRepoStub) FindUserId(_ context.Context, userName string) (entity.UserID, error) {
+	switch userName {
+	case "alice":
+		return aliceID, nil
+	case "bob":
+		return bobID, nil
+	default:
+		return "", nil
+	}
+}
+
+func (r userRepoStub) Exists(_ context.Context, userID entity.UserID) bool {
+	return userID == aliceID || userID == bobID
+}
+
+type organizationRepoStub struct {
+	port.OrganizationRepo
+}
+
+func (r organizationRepoStub) FindOrganizationsByResponsibleUserID(_ context.Context, userID entity.UserID) ([]entity.OrganizationID, error) {
+	if userID == aliceID {
+		return []entity.OrganizationID{testOrganizationID}, nil
+	}
+
+	return nil, nil
+}
+
+type auditRepoStub struct {
+	port.AuditRepo
+}
+
+func (r auditRepoStub) Append(context.Context, *entity.AuditEntry) error {
+	return nil
+}
+
+type metricsStub struct{}
+
+func (metricsStub) TenderCreated()                          {}
+func (metricsStub) TenderStatusChanged(entity.TenderStatus) {}
+func (metricsStub) BidCreated()                             {}
+func (metricsStub) BidDecision(entity.BidReviewDecision)    {}
+
+type eventPublisherStub struct{}
+
+func (eventPublisherStub) Publish(context.Context, *entity.Event) {}
+
+// newTestRouter serves the tender handlers over a TenderService backed by repo.
+func newTestRouter(repo *tenderRepoStub) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	logger := zap.NewNop()
+	tr := &tenderRouter{
+		tenderService: service.NewTenderService(
+			repo,
+			nil,
+			nil,
+			nil,
+			service.NewCatalogService(serviceTypeRepoStub{}, userRepoStub{}, nil, logger),
+			service.NewAuditService(auditRepoStub{}, userRepoStub{}, organizationRepoStub{}, nil, logger),
+			userRepoStub{},
+			organizationRepoStub{},
+			transactorStub{repo: repo},
+			metricsStub{},
+			eventPublisherStub{},
+			logger,
+		),
+		exportTimeout: time.Minute,
+		logger:        logger,
+	}
+
+	router := gin.New()
+	router.POST("/import", tr.importTenders)
+	router.GET("/my/export", tr.export)
+
+	return router
+}
@@ -27,7 +27,7 @@ func (r *tenderRouter) updateStatus(ctx *gin.Context) {
 
 	tender, err := r.tenderService.SetStatus(ctx, entity.TenderID(tenderID), userName, entity.TenderStatus(status))
 	if err != nil {
-		r.logger.Error("set status failed", zap.Error(err))
+		r.log(ctx).Error("set status failed", zap.Error(err))
 		if errors.Is(err, service.ErrUserNotExists) {
 			ctx.AbortWithStatusJSON(
 				http.StatusUnauthorized,
@@ -51,6 +51,14 @@ func (r *tenderRouter) updateStatus(ctx *gin.Context) {
 			)
 			return
 		}
+
+		if errors.Is(err, service.ErrWrongInputFormat) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: err.Error()})
+		return
 	}
 
 	ctx.JSON(http.StatusOK, tender)
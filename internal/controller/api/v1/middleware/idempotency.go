@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+	"avito2024/internal/logger"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+
+	reasonInvalidIdempotencyKey = "Idempotency-Key must be 1 to 255 printable ASCII characters"
+)
+
+type idempotencyStore interface {
+	Begin(ctx context.Context, scope, key, requestHash string) (*entity.IdempotentResponse, error)
+	Complete(ctx context.Context, scope, key string, response *entity.IdempotentResponse) error
+	Release(ctx context.Context, scope, key string) error
+	MaxBodySize() int64
+}
+
+// responseRecorder keeps a copy of the response body for the idempotency store.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotency serves requests carrying an Idempotency-Key header at most once
+// per route and caller: a retry gets the stored response, a different request with the
+// same key gets 422 and a retry racing the first request gets 409. Server
+// errors are not stored, so the client can retry them. A body larger than the
+// store allows gets 413.
+func Idempotency(store idempotencyStore, l *zap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength || !printable(key) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: reasonInvalidIdempotencyKey})
+			return
+		}
+
+		// The body is buffered before any handler could limit it.
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, store.MaxBodySize()))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, entity.ResponseError{Reason: err.Error()})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, entity.ResponseError{Reason: err.Error()})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := ctx.Request.Method + " " + ctx.FullPath() + " " + idempotencyCaller(ctx, body)
+		requestHash := hashRequest(ctx.Request.URL.RawQuery, body)
+
+		response, err := store.Begin(ctx, scope, key, requestHash)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, entity.ResponseError{Reason: err.Error()})
+			return
+		case errors.Is(err, service.ErrIdempotencyInProgress):
+			ctx.AbortWithStatusJSON(http.StatusConflict, entity.ResponseError{Reason: err.Error()})
+			return
+		case err != nil:
+			logger.FromContext(ctx.Request.Context(), l).Error("idempotency key check failed", zap.Error(err))
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, entity.ResponseError{Reason: reasonInternalError})
+			return
+		case response != nil:
+			ctx.Header(HeaderIdempotentReplayed, "true")
+			ctx.Data(response.StatusCode, response.ContentType, response.Body)
+			ctx.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+
+		// A panicking handler has not produced a response worth replaying.
+		served := false
+		defer func() {
+			if !served {
+				release(ctx, store, scope, key, l)
+			}
+		}()
+
+		ctx.Next()
+		served = true
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			release(ctx, store, scope, key, l)
+			return
+		}
+
+		// The outcome must be saved even when the client has gone away,
+		// otherwise its retry would be blocked until the key lock times out.
+		saveCtx := context.WithoutCancel(ctx.Request.Context())
+
+		err = store.Complete(saveCtx, scope, key, &entity.IdempotentResponse{
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			logger.FromContext(saveCtx, l).Error("idempotency response save failed", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func release(ctx *gin.Context, store idempotencyStore, scope, key string, l *zap.Logger) {
+	releaseCtx := context.WithoutCancel(ctx.Request.Context())
+
+	if err := store.Release(releaseCtx, scope, key); err != nil {
+		logger.FromContext(releaseCtx, l).Error("idempotency key release failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// idempotencyCaller tells apart the keys of different callers, so no one is
+// replayed the response to a request of someone else. The caller is taken from
+// where the handlers take it: the username query parameter, or the creator or
+// author in a JSON body. Requests naming no one are told apart by client IP.
+// The caller is hashed, the scope must stay short whatever the input.
+func idempotencyCaller(ctx *gin.Context, body []byte) string {
+	hash := sha256.Sum256([]byte(caller(ctx, body)))
+
+	return hex.EncodeToString(hash[:16])
+}
+
+func caller(ctx *gin.Context, body []byte) string {
+	if username := ctx.Query("username"); username != "" {
+		return "user:" + username
+	}
+
+	var fields struct {
+		CreatorUsername string `json:"creatorUsername"`
+		AuthorType      string `json:"authorType"`
+		AuthorID        string `json:"AuthorId"`
+	}
+
+	if err := json.Unmarshal(body, &fields); err == nil {
+		if fields.CreatorUsername != "" {
+			return "user:" + fields.CreatorUsername
+		}
+
+		if fields.AuthorID != "" {
+			return "author:" + fields.AuthorType + ":" + fields.AuthorID
+		}
+	}
+
+	return "ip:" + ctx.ClientIP()
+}
+
+// hashRequest covers the query as well, username is passed there.
+func hashRequest(query string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(query))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func printable(s string) bool {
+	for _, c := range s {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
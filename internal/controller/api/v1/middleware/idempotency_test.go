@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/app/core/service"
+)
+
+// idempotencyRepoStub keeps records in memory the way the postgres repo does.
+type idempotencyRepoStub struct {
+	mu      sync.Mutex
+	records map[string]*entity.IdempotencyRecord
+}
+
+func (r *idempotencyRepoStub) Acquire(
+	_ context.Context,
+	record *entity.IdempotencyRecord,
+	lockTimeout time.Duration,
+) (*entity.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := record.Scope + "\x00" + record.Key
+	if existing, ok := r.records[id]; ok && time.Now().Before(existing.ExpiresAt) {
+		found := *existing
+		return &found, nil
+	}
+
+	acquired := *record
+	acquired.ExpiresAt = time.Now().Add(lockTimeout)
+	r.records[id] = &acquired
+
+	return nil, nil
+}
+
+func (r *idempotencyRepoStub) Complete(
+	_ context.Context,
+	scope, key string,
+	response *entity.IdempotentResponse,
+	window time.Duration,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := r.records[scope+"\x00"+key]
+	record.Response = response
+	record.ExpiresAt = time.Now().Add(window)
+
+	return nil
+}
+
+func (r *idempotencyRepoStub) Delete(_ context.Context, scope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, scope+"\x00"+key)
+
+	return nil
+}
+
+func (r *idempotencyRepoStub) DeleteExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+type idempotentRequest struct {
+	key  string
+	user string
+	body string
+}
+
+func (r idempotentRequest) do(router http.Handler) *httptest.ResponseRecorder {
+	target := "/new"
+	if r.user != "" {
+		target += "?username=" + r.user
+	}
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(r.body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderIdempotencyKey, r.key)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	alice := idempotentRequest{key: "k1", user: "alice", body: `{"name":"a"}`}
+
+	tests := []struct {
+		name string
+		// status is what the handler answers its n-th call with.
+		status     func(call int) int
+		maxBody    int64
+		first      idempotentRequest
+		second     idempotentRequest
+		wantFirst  int
+		wantSecond int
+		wantCalls  int
+		wantReplay bool
+	}{
+		{
+			name:       "retry is replayed",
+			status:     func(int) int { return http.StatusOK },
+			first:      alice,
+			second:     alice,
+			wantFirst:  http.StatusOK,
+			wantSecond: http.StatusOK,
+			wantCalls:  1,
+			wantReplay: true,
+		},
+		{
+			name:       "another body with the key",
+			status:     func(int) int { return http.StatusOK },
+			first:      alice,
+			second:     idempotentRequest{key: "k1", user: "alice", body: `{"name":"b"}`},
+			wantFirst:  http.StatusOK,
+			wantSecond: http.StatusUnprocessableEntity,
+			wantCalls:  1,
+		},
+		{
+			name:       "another user with the key",
+			status:     func(int) int { return http.StatusOK },
+			first:      alice,
+			second:     idempotentRequest{key: "k1", user: "bob", body: `{"name":"a"}`},
+			wantFirst:  http.StatusOK,
+			wantSecond: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name:       "another author in the body with the key",
+			status:     func(int) int { return http.StatusOK },
+			first:      idempotentRequest{key: "k1", body: `{"authorType":"User","AuthorId":"1"}`},
+			second:     idempotentRequest{key: "k1", body: `{"authorType":"User","AuthorId":"2"}`},
+			wantFirst:  http.StatusOK,
+			wantSecond: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name: "server error is released",
+			status: func(call int) int {
+				if call == 1 {
+					return http.StatusInternalServerError
+				}
+
+				return http.StatusOK
+			},
+			first:      alice,
+			second:     alice,
+			wantFirst:  http.StatusInternalServerError,
+			wantSecond: http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name:       "body over the limit",
+			status:     func(int) int { return http.StatusOK },
+			maxBody:    8,
+			first:      alice,
+			second:     idempotentRequest{key: "k1", user: "alice", body: `{}`},
+			wantFirst:  http.StatusRequestEntityTooLarge,
+			wantSecond: http.StatusOK,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxBody := tt.maxBody
+			if maxBody == 0 {
+				maxBody = 1 << 20
+			}
+
+			store := service.NewIdempotencyService(
+				&idempotencyRepoStub{records: make(map[string]*entity.IdempotencyRecord)},
+				time.Hour,
+				time.Minute,
+				maxBody,
+				zap.NewNop(),
+			)
+
+			calls := 0
+			router := gin.New()
+			router.POST("/new", Idempotency(store, zap.NewNop()), func(ctx *gin.Context) {
+				calls++
+				ctx.JSON(tt.status(calls), gin.H{"call": calls})
+			})
+
+			rec := tt.first.do(router)
+			if rec.Code != tt.wantFirst {
+				t.Fatalf("first status = %d, want %d", rec.Code, tt.wantFirst)
+			}
+
+			firstBody := rec.Body.String()
+
+			rec = tt.second.do(router)
+			if rec.Code != tt.wantSecond {
+				t.Fatalf("second status = %d, want %d: %s", rec.Code, tt.wantSecond, rec.Body)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", calls, tt.wantCalls)
+			}
+
+			replayed := rec.Header().Get(HeaderIdempotentReplayed) == "true"
+			if replayed != tt.wantReplay {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplay)
+			}
+
+			if tt.wantReplay && rec.Body.String() != firstBody {
+				t.Errorf("replayed body = %s, want %s", rec.Body, firstBody)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := service.NewIdempotencyService(
+		&idempotencyRepoStub{records: make(map[string]*entity.IdempotencyRecord)},
+		time.Hour,
+		time.Minute,
+		1<<20,
+		zap.NewNop(),
+	)
+
+	request := idempotentRequest{key: "k1", user: "alice", body: `{"name":"a"}`}
+
+	var retry *httptest.ResponseRecorder
+
+	router := gin.New()
+	router.POST("/new", Idempotency(store, zap.NewNop()), func(ctx *gin.Context) {
+		// The retry arrives while the first request is still being served.
+		if retry == nil {
+			retry = request.do(router)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+
+	if rec := request.do(router); rec.Code != http.StatusOK {
+		t.Fatalf("first status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if retry.Code != http.StatusConflict {
+		t.Errorf("retry status = %d, want %d", retry.Code, http.StatusConflict)
+	}
+}
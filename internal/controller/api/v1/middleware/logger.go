@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"avito2024/internal/logger"
+	"avito2024/internal/tracing"
+)
+
+// AccessLog puts a logger carrying the request ID into the request context and
+// writes one entry per request once it is served.
+func AccessLog(l *zap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		started := time.Now()
+
+		requestLogger := l.With(
+			zap.String("requestId", logger.RequestID(ctx.Request.Context())),
+			zap.String("traceId", tracing.TraceID(ctx.Request.Context())),
+		)
+		ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context(), requestLogger))
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+
+		level := zapcore.InfoLevel
+		switch {
+		case status >= 500:
+			level = zapcore.ErrorLevel
+		case status >= 400:
+			level = zapcore.WarnLevel
+		}
+
+		fields := []zap.Field{
+			zap.String("method", ctx.Request.Method),
+			zap.String("path", ctx.Request.URL.Path),
+			zap.String("route", ctx.FullPath()),
+			zap.String("query", ctx.Request.URL.RawQuery),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(started)),
+			zap.String("clientIp", ctx.ClientIP()),
+			zap.Int("bytes", ctx.Writer.Size()),
+		}
+
+		if len(ctx.Errors) > 0 {
+			fields = append(fields, zap.Strings("errors", ctx.Errors.Errors()))
+		}
+
+		requestLogger.Log(level, "request served", fields...)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const unmatchedRoute = "unmatched"
+
+type requestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// Metrics records count and latency of every request labeled by its route template,
+// so /tenders/:tenderId/status is a single series regardless of the ID.
+func Metrics(observer requestObserver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		started := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		observer.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(started))
+	}
+}
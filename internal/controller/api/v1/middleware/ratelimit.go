@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/logger"
+	"avito2024/internal/ratelimit"
+)
+
+const (
+	HeaderRetryAfter         = "Retry-After"
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+
+	reasonRateLimited = "too many requests"
+)
+
+// RateLimit throttles the requests of a route group named group. Every request
+// takes a token from the bucket of the client IP and, when the username query
+// parameter is set, from the bucket of the user, or from neither when one of
+// them is empty. So one user can not exhaust the limit of a shared IP and one
+// IP can not rotate usernames. Routes that take the user from the body, like
+// POST /bids/new, are limited by IP only: the body is not read here, as
+// buffering it before the limit applies would let anyone make the server read
+// large bodies. Store errors let the request through, an outage of a shared
+// store must not take the API down.
+func RateLimit(store ratelimit.Store, group string, policy ratelimit.Policy, l *zap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		kind, limit := "write", policy.Write
+		if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+			kind, limit = "read", policy.Read
+		}
+
+		if limit.Unlimited() {
+			ctx.Next()
+			return
+		}
+
+		prefix := group + ":" + kind + ":"
+
+		keys := []string{prefix + "ip:" + ctx.ClientIP()}
+		if username := ctx.Query("username"); username != "" {
+			keys = append(keys, prefix+"user:"+username)
+		}
+
+		result, err := store.Take(ctx, keys, limit)
+		if err != nil {
+			logger.FromContext(ctx.Request.Context(), l).Warn("rate limit store failed", zap.Strings("keys", keys), zap.Error(err))
+			ctx.Next()
+
+			return
+		}
+
+		ctx.Header(HeaderRateLimitLimit, strconv.Itoa(limit.Burst))
+		ctx.Header(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+		ctx.Header(HeaderRateLimitReset, seconds(result.Reset))
+		ctx.Header(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%s", limit.Burst, seconds(limit.Period)))
+
+		if !result.Allowed {
+			ctx.Header(HeaderRetryAfter, seconds(result.RetryAfter))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, entity.ResponseError{Reason: reasonRateLimited})
+
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// seconds rounds d up to whole seconds, as the headers carry delta-seconds.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
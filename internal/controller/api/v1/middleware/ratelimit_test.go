@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"avito2024/internal/ratelimit"
+)
+
+// rateLimitStoreStub records the keys of every take and answers with result.
+type rateLimitStoreStub struct {
+	keys   [][]string
+	result ratelimit.Result
+	err    error
+}
+
+func (r *rateLimitStoreStub) Take(_ context.Context, keys []string, _ ratelimit.Limit) (ratelimit.Result, error) {
+	r.keys = append(r.keys, keys)
+	return r.result, r.err
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := ratelimit.Policy{
+		Read:  ratelimit.Limit{Burst: 20, Period: time.Minute},
+		Write: ratelimit.Limit{Burst: 5, Period: time.Minute},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		store      *rateLimitStoreStub
+		wantKeys   []string
+		wantStatus int
+	}{
+		{
+			name:       "read by a user",
+			method:     http.MethodGet,
+			target:     "/bids/my?username=alice",
+			store:      &rateLimitStoreStub{result: ratelimit.Result{Allowed: true}},
+			wantKeys:   []string{"bids:read:ip:192.0.2.1", "bids:read:user:alice"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "write with the user in the body falls back to the IP",
+			method:     http.MethodPost,
+			target:     "/bids/new",
+			body:       `{"authorType":"User","authorId":"9b1d4c2e-3f5a-4b6c-8d7e-0f1a2b3c4d5e"}`,
+			store:      &rateLimitStoreStub{result: ratelimit.Result{Allowed: true}},
+			wantKeys:   []string{"bids:write:ip:192.0.2.1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "denied",
+			method: http.MethodPut,
+			target: "/bids/1/submit_decision?username=alice",
+			store: &rateLimitStoreStub{result: ratelimit.Result{
+				RetryAfter: 1500 * time.Millisecond,
+				Reset:      time.Minute,
+			}},
+			wantKeys:   []string{"bids:write:ip:192.0.2.1", "bids:write:user:alice"},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "store failure lets the request through",
+			method:     http.MethodPost,
+			target:     "/bids/new",
+			store:      &rateLimitStoreStub{err: errors.New("store is down")},
+			wantKeys:   []string{"bids:write:ip:192.0.2.1"},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RateLimit(tt.store, "bids", policy, zap.NewNop()))
+			router.Any("/*path", func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.RemoteAddr = "192.0.2.1:1234"
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			if len(tt.store.keys) != 1 || !slices.Equal(tt.store.keys[0], tt.wantKeys) {
+				t.Errorf("keys = %v, want one take of %v", tt.store.keys, tt.wantKeys)
+			}
+
+			if tt.wantStatus == http.StatusTooManyRequests && rec.Header().Get(HeaderRetryAfter) != "2" {
+				t.Errorf("%s = %q, want 2", HeaderRetryAfter, rec.Header().Get(HeaderRetryAfter))
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"avito2024/internal/app/core/entity"
+	"avito2024/internal/logger"
+)
+
+const reasonInternalError = "internal server error"
+
+// Recovery turns a panic in a handler into a 500 JSON response instead of a dropped connection.
+func Recovery(l *zap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			logger.FromContext(ctx.Request.Context(), l).Error("panic recovered",
+				zap.Any("panic", recovered),
+				zap.Stack("stack"),
+			)
+
+			if ctx.Writer.Written() {
+				ctx.Abort()
+				return
+			}
+
+			ctx.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				entity.ResponseError{Reason: reasonInternalError},
+			)
+		}()
+
+		ctx.Next()
+	}
+}
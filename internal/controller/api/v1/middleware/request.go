@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"avito2024/internal/logger"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// RequestID propagates the X-Request-ID of the caller or assigns a new one,
+// echoes it in the response and stores it in the request context.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Header(HeaderRequestID, requestID)
+		ctx.Request = ctx.Request.WithContext(logger.WithRequestID(ctx.Request.Context(), requestID))
+
+		ctx.Next()
+	}
+}
+
+// ClientIP stores the address of the client in the request context, resolved
+// through the trusted proxies.
+func ClientIP() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(logger.WithClientIP(ctx.Request.Context(), ctx.ClientIP()))
+
+		ctx.Next()
+	}
+}
+
+// validRequestID rejects IDs that would bloat or break log lines.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
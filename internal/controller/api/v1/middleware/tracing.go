@@ -0,0 +1,11 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TraceFilter keeps probes and scrapes out of the traces.
+func TraceFilter(req *http.Request) bool {
+	return req.URL.Path != "/metrics" && !strings.HasPrefix(req.URL.Path, "/api/health/")
+}
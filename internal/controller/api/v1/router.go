@@ -2,19 +2,65 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"avito2024/internal/app/core/service"
+	"avito2024/internal/controller/api/v1/handler/audit"
 	"avito2024/internal/controller/api/v1/handler/bid"
+	"avito2024/internal/controller/api/v1/handler/catalog"
+	"avito2024/internal/controller/api/v1/handler/health"
+	"avito2024/internal/controller/api/v1/handler/notification"
 	"avito2024/internal/controller/api/v1/handler/tender"
+	"avito2024/internal/controller/api/v1/middleware"
+	"avito2024/internal/ratelimit"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
 )
 
+// HTTPMetrics records served requests and exposes them for scraping.
+type HTTPMetrics interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+	Handler() http.Handler
+}
+
+// RateLimits throttles the tender and bid groups, see middleware.RateLimit.
+type RateLimits struct {
+	Store   ratelimit.Store
+	Tenders ratelimit.Policy
+	Bids    ratelimit.Policy
+}
+
+// Services are the business services the API is served by.
+type Services struct {
+	Tender       *service.TenderService
+	Bid          *service.BidService
+	Health       *service.HealthService
+	Idempotency  *service.IdempotencyService
+	Attachment   *service.AttachmentService
+	Evaluation   *service.EvaluationService
+	Auction      *service.AuctionService
+	Catalog      *service.CatalogService
+	Question     *service.QuestionService
+	Notification *service.NotificationService
+	Audit        *service.AuditService
+}
+
 type parentRouter struct {
-	tenderService *service.TenderService
-	bidService    *service.BidService
-	logger        *zap.Logger
+	tenderService       *service.TenderService
+	bidService          *service.BidService
+	healthService       *service.HealthService
+	idempotency         *service.IdempotencyService
+	attachmentService   *service.AttachmentService
+	evaluationService   *service.EvaluationService
+	auctionService      *service.AuctionService
+	catalogService      *service.CatalogService
+	questionService     *service.QuestionService
+	notificationService *service.NotificationService
+	auditService        *service.AuditService
+	exportTimeout       time.Duration
+	logger              *zap.Logger
 }
 
 func (r *parentRouter) TenderService() *service.TenderService {
@@ -25,29 +71,117 @@ func (r *parentRouter) BidService() *service.BidService {
 	return r.bidService
 }
 
+func (r *parentRouter) HealthService() *service.HealthService {
+	return r.healthService
+}
+
+func (r *parentRouter) IdempotencyService() *service.IdempotencyService {
+	return r.idempotency
+}
+
+func (r *parentRouter) AttachmentService() *service.AttachmentService {
+	return r.attachmentService
+}
+
+func (r *parentRouter) EvaluationService() *service.EvaluationService {
+	return r.evaluationService
+}
+
+func (r *parentRouter) AuctionService() *service.AuctionService {
+	return r.auctionService
+}
+
+func (r *parentRouter) CatalogService() *service.CatalogService {
+	return r.catalogService
+}
+
+func (r *parentRouter) QuestionService() *service.QuestionService {
+	return r.questionService
+}
+
+func (r *parentRouter) NotificationService() *service.NotificationService {
+	return r.notificationService
+}
+
+func (r *parentRouter) AuditService() *service.AuditService {
+	return r.auditService
+}
+
+func (r *parentRouter) ExportTimeout() time.Duration {
+	return r.exportTimeout
+}
+
 func (r *parentRouter) Logger() *zap.Logger {
 	return r.logger
 }
 
 func NewAPI(
-	tenderService *service.TenderService,
-	bidService *service.BidService,
+	services *Services,
+	metrics HTTPMetrics,
+	rateLimits *RateLimits,
+	trustedProxies []string,
+	exportTimeout time.Duration,
+	serviceName string,
 	logger *zap.Logger,
-) *gin.Engine {
+) (*gin.Engine, error) {
 	router := gin.New()
+	// Let handlers pass *gin.Context to services and still reach the request context values.
+	router.ContextWithFallback = true
+
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+
+	router.Use(
+		otelgin.Middleware(serviceName, otelgin.WithFilter(middleware.TraceFilter)),
+		middleware.RequestID(),
+		middleware.ClientIP(),
+		middleware.AccessLog(logger.Named("access")),
+	)
+
+	if metrics != nil {
+		router.Use(middleware.Metrics(metrics))
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	router.Use(middleware.Recovery(logger.Named("recovery")))
 
 	api := router.Group("/api")
 
 	pr := &parentRouter{
-		tenderService: tenderService,
-		bidService:    bidService,
-		logger:        logger.Named("api"),
+		tenderService:       services.Tender,
+		bidService:          services.Bid,
+		healthService:       services.Health,
+		idempotency:         services.Idempotency,
+		attachmentService:   services.Attachment,
+		evaluationService:   services.Evaluation,
+		auctionService:      services.Auction,
+		catalogService:      services.Catalog,
+		questionService:     services.Question,
+		notificationService: services.Notification,
+		auditService:        services.Audit,
+		exportTimeout:       exportTimeout,
+		logger:              logger.Named("api"),
 	}
 
 	api.GET("/ping", func(ctx *gin.Context) { ctx.String(http.StatusOK, "ok") })
 
-	bid.AttachToGroup(pr, api.Group("/bids"))
-	tender.AttachToGroup(pr, api.Group("/tenders"))
+	health.AttachToGroup(pr, api.Group("/health"))
+	bids := api.Group("/bids")
+	tenders := api.Group("/tenders")
+
+	if rateLimits != nil {
+		rateLimitLogger := logger.Named("ratelimit")
+
+		bids.Use(middleware.RateLimit(rateLimits.Store, "bids", rateLimits.Bids, rateLimitLogger))
+		tenders.Use(middleware.RateLimit(rateLimits.Store, "tenders", rateLimits.Tenders, rateLimitLogger))
+	}
+
+	bid.AttachToGroup(pr, bids)
+	tender.AttachToGroup(pr, tenders)
+	catalog.AttachToGroup(pr, api.Group("/service-types"))
+	notification.AttachToGroup(pr, api.Group("/notifications"))
+	audit.AttachToGroup(pr, api.Group("/audit"))
 
-	return router
+	return router, nil
 }
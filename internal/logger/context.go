@@ -0,0 +1,51 @@
+// Package logger carries a request-scoped zap logger through context.Context.
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	loggerKey    struct{}
+	requestIDKey struct{}
+	clientIPKey  struct{}
+)
+
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns l writing through the request-scoped logger stored in ctx,
+// so entries keep the name of l and gain the request fields. Fields attached to l
+// itself with With are dropped. Outside of a request l is returned as is.
+func FromContext(ctx context.Context, l *zap.Logger) *zap.Logger {
+	rl, ok := ctx.Value(loggerKey{}).(*zap.Logger)
+	if !ok {
+		return l
+	}
+
+	return l.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return rl.Core()
+	}))
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that have refilled completely are dropped.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// refill adds the tokens accrued since the last take.
+func (r *bucket) refill(now time.Time) {
+	elapsed := now.Sub(r.last)
+	r.last = now
+
+	r.tokens += float64(elapsed) / float64(r.limit.perToken())
+	if burst := float64(r.limit.Burst); r.tokens > burst {
+		r.tokens = burst
+	}
+}
+
+// Memory keeps buckets in the process, every replica then enforces its own limits.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (r *Memory) Take(_ context.Context, keys []string, limit Limit) (Result, error) {
+	if limit.Unlimited() || len(keys) == 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweep(now)
+
+	buckets := make([]*bucket, 0, len(keys))
+	allowed := true
+
+	for _, key := range keys {
+		b, ok := r.buckets[key]
+		if !ok || b.limit != limit {
+			b = &bucket{tokens: float64(limit.Burst), last: now, limit: limit}
+			r.buckets[key] = b
+		}
+
+		b.refill(now)
+		buckets = append(buckets, b)
+
+		if b.tokens < 1 {
+			allowed = false
+		}
+	}
+
+	var result Result
+	for i, b := range buckets {
+		if allowed {
+			b.tokens--
+		}
+
+		bucketResult := b.result(allowed)
+		if i == 0 || moreRestrictive(bucketResult, result) {
+			result = bucketResult
+		}
+	}
+
+	return result, nil
+}
+
+// result describes the bucket after a request that was allowed or not.
+func (r *bucket) result(allowed bool) Result {
+	result := Result{Allowed: allowed, Remaining: int(r.tokens)}
+	if !allowed && r.tokens < 1 {
+		result.RetryAfter = time.Duration((1 - r.tokens) * float64(r.limit.perToken()))
+	}
+
+	result.Reset = time.Duration((float64(r.limit.Burst) - r.tokens) * float64(r.limit.perToken()))
+
+	return result
+}
+
+// sweep drops full buckets, a new bucket starts full so nothing is lost.
+func (r *Memory) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < sweepInterval {
+		return
+	}
+
+	r.lastSweep = now
+
+	for key, b := range r.buckets {
+		if now.Sub(b.last) >= b.limit.Period {
+			delete(r.buckets, key)
+		}
+	}
+}
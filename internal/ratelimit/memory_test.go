@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a time source the tests advance by hand.
+type clock struct{ now time.Time }
+
+func (r *clock) advance(d time.Duration) { r.now = r.now.Add(d) }
+
+func newTestMemory() (*Memory, *clock) {
+	c := &clock{now: time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)}
+
+	store := NewMemory()
+	store.now = func() time.Time { return c.now }
+	store.lastSweep = c.now
+
+	return store, c
+}
+
+func TestMemoryTake(t *testing.T) {
+	limit := Limit{Burst: 2, Period: 2 * time.Second}
+
+	type take struct {
+		after      time.Duration
+		keys       []string
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		limit Limit
+		takes []take
+	}{
+		{
+			name:  "burst then denied",
+			limit: limit,
+			takes: []take{
+				{keys: []string{"ip"}, allowed: true, remaining: 1},
+				{keys: []string{"ip"}, allowed: true, remaining: 0},
+				{keys: []string{"ip"}, allowed: false, remaining: 0, retryAfter: time.Second},
+			},
+		},
+		{
+			name:  "refills over time",
+			limit: limit,
+			takes: []take{
+				{keys: []string{"ip"}, allowed: true, remaining: 1},
+				{keys: []string{"ip"}, allowed: true, remaining: 0},
+				{after: 500 * time.Millisecond, keys: []string{"ip"}, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond},
+				{after: 500 * time.Millisecond, keys: []string{"ip"}, allowed: true, remaining: 0},
+				{after: time.Hour, keys: []string{"ip"}, allowed: true, remaining: 1},
+			},
+		},
+		{
+			name:  "keys have their own buckets",
+			limit: limit,
+			takes: []take{
+				{keys: []string{"a"}, allowed: true, remaining: 1},
+				{keys: []string{"a"}, allowed: true, remaining: 0},
+				{keys: []string{"b"}, allowed: true, remaining: 1},
+			},
+		},
+		{
+			name:  "most restrictive bucket is reported",
+			limit: limit,
+			takes: []take{
+				{keys: []string{"user"}, allowed: true, remaining: 1},
+				{keys: []string{"ip", "user"}, allowed: true, remaining: 0},
+			},
+		},
+		{
+			name:  "denied request drains no bucket",
+			limit: limit,
+			takes: []take{
+				{keys: []string{"user"}, allowed: true, remaining: 1},
+				{keys: []string{"user"}, allowed: true, remaining: 0},
+				{keys: []string{"ip", "user"}, allowed: false, remaining: 0, retryAfter: time.Second},
+				{keys: []string{"ip", "user"}, allowed: false, remaining: 0, retryAfter: time.Second},
+				{keys: []string{"ip"}, allowed: true, remaining: 1},
+			},
+		},
+		{
+			name:  "unlimited",
+			limit: Limit{},
+			takes: []take{
+				{keys: []string{"ip"}, allowed: true},
+				{keys: []string{"ip"}, allowed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, c := newTestMemory()
+
+			for i, take := range tt.takes {
+				c.advance(take.after)
+
+				result, err := store.Take(context.Background(), take.keys, tt.limit)
+				if err != nil {
+					t.Fatalf("take %d: unexpected error: %v", i, err)
+				}
+
+				if result.Allowed != take.allowed || result.Remaining != take.remaining || result.RetryAfter != take.retryAfter {
+					t.Errorf("take %d: got allowed %t, remaining %d, retry after %s, want %t, %d, %s",
+						i, result.Allowed, result.Remaining, result.RetryAfter, take.allowed, take.remaining, take.retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	store, c := newTestMemory()
+	limit := Limit{Burst: 1, Period: time.Second}
+
+	if _, err := store.Take(context.Background(), []string{"ip"}, limit); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.advance(sweepInterval)
+
+	if _, err := store.Take(context.Background(), []string{"other"}, limit); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := store.buckets["ip"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+}
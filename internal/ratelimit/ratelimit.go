@@ -0,0 +1,60 @@
+// Package ratelimit implements token bucket limits behind a Store, so the
+// in-memory buckets can be replaced by a backend shared between replicas.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is a token bucket holding Burst tokens that refills completely every
+// Period. A zero Limit does not throttle.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+func (r Limit) Unlimited() bool {
+	return r.Burst <= 0 || r.Period <= 0
+}
+
+// perToken is the time it takes to refill a single token.
+func (r Limit) perToken() time.Duration {
+	return r.Period / time.Duration(r.Burst)
+}
+
+// Policy holds the limits of a route group, reads are GET and HEAD requests.
+type Policy struct {
+	Read  Limit
+	Write Limit
+}
+
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is the wait for the next token, zero when Allowed.
+	RetryAfter time.Duration
+	// Reset is the wait until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store takes one token from every bucket stored under keys, or none when any
+// of them is empty, so a denied request drains none of its buckets. The
+// Result is that of the most restrictive bucket.
+type Store interface {
+	Take(ctx context.Context, keys []string, limit Limit) (Result, error)
+}
+
+// moreRestrictive reports whether a should be reported instead of b: a denial
+// wins over an allowed request, then the longer wait or the fewer remaining tokens.
+func moreRestrictive(a, b Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+
+	return a.Remaining < b.Remaining
+}